@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/furee/backend/cmd/core/config"
+)
+
+// Register new public API client, the secret is only printed once.
+// go run cmd/apiclient/main.go -name=mobile -routes="POST /v1/login,POST /v1/verify-user"
+func main() {
+	name := flag.String("name", "", "client name")
+	routes := flag.String("routes", "", "comma separated allowed routes, e.g. \"POST /v1/login\" or \"*\"")
+	flag.Parse()
+
+	conf, err := config.GetCoreConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	uc, _, _, err := config.NewUsecaseContext(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	allowedRoutes := make([]string, 0)
+	for _, route := range strings.Split(*routes, ",") {
+		if route = strings.TrimSpace(route); route != "" {
+			allowedRoutes = append(allowedRoutes, route)
+		}
+	}
+
+	client, message, err := uc.Authorization.APIClient.CreateClient(*name, allowedRoutes)
+	if err != nil {
+		if message != "" {
+			fmt.Println(message)
+			return
+		}
+
+		panic(err)
+	}
+
+	fmt.Println("client id:", client.ID)
+	fmt.Println("secret   :", client.Secret)
+}
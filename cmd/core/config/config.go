@@ -1,13 +1,16 @@
 package config
 
 import (
+	"errors"
 	"strings"
 
+	cg "github.com/furee/backend/constants/general"
 	"github.com/furee/backend/domain/general"
 	"github.com/furee/backend/handlers/core"
 	"github.com/furee/backend/infra"
 	"github.com/furee/backend/repo"
 	"github.com/furee/backend/usecase"
+	"github.com/furee/backend/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
@@ -18,6 +21,11 @@ func GetCoreConfig() (*general.SectionService, error) {
 	viper.AutomaticEnv()
 	viper.SetConfigType("yml")
 
+	// OTP lockout is silently disabled when the setting is empty, so the default is always set.
+	viper.SetDefault("AUTHORIZATION.OTP.DURATION", cg.OTPDefaultDuration)
+	viper.SetDefault("AUTHORIZATION.OTP.MAX_ATTEMPT", cg.OTPDefaultMaxAttempt)
+	viper.SetDefault("AUTHORIZATION.OTP.LOCK_DURATION", cg.OTPDefaultLockDuration)
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		return nil, err
@@ -38,6 +46,10 @@ func GetCoreConfig() (*general.SectionService, error) {
 			Origins: general.RouteOrigin{
 				InternalTools: viper.GetString("ROUTES.HEADERS.INTERNAL_TOOLS"),
 			},
+			MaxBodySize:    viper.GetInt64("ROUTES.MAX_BODY_SIZE"),
+			MaxUploadSize:  viper.GetInt64("ROUTES.MAX_UPLOAD_SIZE"),
+			MaxPageLimit:   viper.GetInt("ROUTES.MAX_PAGE_LIMIT"),
+			MaxGetAllLimit: viper.GetInt("ROUTES.MAX_GET_ALL_LIMIT"),
 		},
 		Database: general.DatabaseAccount{
 			Read: general.DBDetailAccount{
@@ -65,41 +77,202 @@ func GetCoreConfig() (*general.SectionService, error) {
 				SSLMode:      viper.GetString("DATABASE.READ.SSL_MODE"),
 			},
 		},
+		Redis: general.RedisAccount{
+			Username:     viper.GetString("REDIS.USERNAME"),
+			Password:     viper.GetString("REDIS.PASSWORD"),
+			URL:          viper.GetString("REDIS.URL"),
+			Port:         viper.GetInt("REDIS.PORT"),
+			MinIdleConns: viper.GetInt("REDIS.MIN_IDLE_CONNS"),
+			Timeout:      viper.GetString("REDIS.TIMEOUT"),
+		},
+		RateLimit: general.RateLimitAccount{
+			Backend: viper.GetString("RATE_LIMIT.BACKEND"),
+			OTPSend: general.RateLimitRule{
+				Capacity:       viper.GetInt("RATE_LIMIT.OTP_SEND.CAPACITY"),
+				RefillInterval: viper.GetInt("RATE_LIMIT.OTP_SEND.REFILL_INTERVAL"),
+			},
+			OTPVerify: general.RateLimitRule{
+				Capacity:       viper.GetInt("RATE_LIMIT.OTP_VERIFY.CAPACITY"),
+				RefillInterval: viper.GetInt("RATE_LIMIT.OTP_VERIFY.REFILL_INTERVAL"),
+			},
+		},
+		Cache: general.CacheAccount{
+			Backend:    viper.GetString("CACHE.BACKEND"),
+			MaxEntries: viper.GetInt("CACHE.MAX_ENTRIES"),
+			DefaultTTL: viper.GetInt("CACHE.DEFAULT_TTL"),
+		},
+		NSQProducer: general.NSQProducer{
+			NSQD: viper.GetString("NSQ.PRODUCER.NSQD"),
+		},
+		Minio: general.MinioSecret{
+			BucketName: viper.GetString("MINIO.BUCKET_NAME"),
+			Endpoint:   viper.GetString("MINIO.ENDPOINT"),
+			Key:        viper.GetString("MINIO.KEY"),
+			Secret:     viper.GetString("MINIO.SECRET"),
+			Region:     viper.GetString("MINIO.REGION"),
+			TempFolder: viper.GetString("MINIO.TEMP_FOLDER"),
+			BaseURL:    viper.GetString("MINIO.BASE_URL"),
+		},
+		Privacy: general.PrivacyAccount{
+			ExportFolder:        viper.GetString("PRIVACY.EXPORT_FOLDER"),
+			ExportLinkDuration:  viper.GetInt("PRIVACY.EXPORT_LINK_DURATION"),
+			DeletionGracePeriod: viper.GetInt("PRIVACY.DELETION_GRACE_PERIOD"),
+			JobMaxAttempt:       viper.GetInt("PRIVACY.JOB_MAX_ATTEMPT"),
+			JobTimeout:          viper.GetInt("PRIVACY.JOB_TIMEOUT"),
+		},
+		Whitelist: general.WhitelistAccount{
+			AllowOnProduction: viper.GetBool("WHITELIST.ALLOW_ON_PRODUCTION"),
+			CacheDuration:     viper.GetInt("WHITELIST.CACHE_DURATION"),
+		},
 		Authorization: general.AuthAccount{
 			JWT: general.JWTCredential{
 				IsActive:              viper.GetBool("AUTHORIZATION.JWT.IS_ACTIVE"),
+				SigningMethod:         viper.GetString("AUTHORIZATION.JWT.SIGNING_METHOD"),
+				ActiveKeyID:           viper.GetString("AUTHORIZATION.JWT.ACTIVE_KEY_ID"),
 				AccessTokenSecretKey:  viper.GetString("AUTHORIZATION.JWT.ACCESS_TOKEN_SECRET_KEY"),
 				AccessTokenDuration:   viper.GetInt("AUTHORIZATION.JWT.ACCESS_TOKEN_DURATION"),
 				RefreshTokenSecretKey: viper.GetString("AUTHORIZATION.JWT.REFRESH_TOKEN_SECRET_KEY"),
 				RefreshTokenDuration:  viper.GetInt("AUTHORIZATION.JWT.REFRESH_TOKEN_DURATION"),
 			},
 			Public: general.PublicCredential{
-				SecretKey: viper.GetString("AUTHORIZATION.PUBLIC.SECRECT_KEY"),
+				SecretEncryptionKey: viper.GetString("AUTHORIZATION.PUBLIC.SECRET_ENCRYPTION_KEY"),
+				TimestampTolerance:  viper.GetInt("AUTHORIZATION.PUBLIC.TIMESTAMP_TOLERANCE"),
+				NonceBackend:        viper.GetString("AUTHORIZATION.PUBLIC.NONCE_BACKEND"),
+			},
+			OTP: general.OTPCredential{
+				Duration:     viper.GetInt("AUTHORIZATION.OTP.DURATION"),
+				MaxAttempt:   viper.GetInt("AUTHORIZATION.OTP.MAX_ATTEMPT"),
+				LockDuration: viper.GetInt("AUTHORIZATION.OTP.LOCK_DURATION"),
+				Channels:     strings.Split(viper.GetString("AUTHORIZATION.OTP.CHANNELS"), ","),
+				LogCode:      viper.GetBool("AUTHORIZATION.OTP.LOG_CODE"),
+			},
+			Phone: general.PhoneCredential{
+				ActiveKeyVersion: viper.GetString("AUTHORIZATION.PHONE.ACTIVE_KEY_VERSION"),
+				FilterPepper:     viper.GetString("AUTHORIZATION.PHONE.FILTER_PEPPER"),
+			},
+			Password: general.PasswordCredential{
+				UserTypes: strings.Split(viper.GetString("AUTHORIZATION.PASSWORD.USER_TYPES"), ","),
+				MinLength: viper.GetInt("AUTHORIZATION.PASSWORD.MIN_LENGTH"),
+			},
+			MFA: general.MFACredential{
+				Issuer:          viper.GetString("AUTHORIZATION.MFA.ISSUER"),
+				SecretKey:       viper.GetString("AUTHORIZATION.MFA.SECRET_KEY"),
+				PendingDuration: viper.GetInt("AUTHORIZATION.MFA.PENDING_DURATION"),
+			},
+			OIDC: general.OIDCCredential{
+				IsActive:       viper.GetBool("AUTHORIZATION.OIDC.IS_ACTIVE"),
+				IssuerURL:      viper.GetString("AUTHORIZATION.OIDC.ISSUER_URL"),
+				ClientID:       viper.GetString("AUTHORIZATION.OIDC.CLIENT_ID"),
+				ClientSecret:   viper.GetString("AUTHORIZATION.OIDC.CLIENT_SECRET"),
+				RedirectURL:    viper.GetString("AUTHORIZATION.OIDC.REDIRECT_URL"),
+				Scopes:         strings.Split(viper.GetString("AUTHORIZATION.OIDC.SCOPES"), ","),
+				AllowedDomains: strings.Split(viper.GetString("AUTHORIZATION.OIDC.ALLOWED_DOMAINS"), ","),
+				GroupClaim:     viper.GetString("AUTHORIZATION.OIDC.GROUP_CLAIM"),
+				StateDuration:  viper.GetInt("AUTHORIZATION.OIDC.STATE_DURATION"),
+				CookieSecure:   viper.GetBool("AUTHORIZATION.OIDC.COOKIE_SECURE"),
+			},
+		},
+		PartnerSecret: general.PartnerSecret{
+			MessageBird: general.MessageBirdCredential{
+				WhatsappOTPURL: viper.GetString("PARTNER.MESSAGEBIRD.WHATSAPP_OTP_URL"),
+				Authorization:  viper.GetString("PARTNER.MESSAGEBIRD.AUTHORIZATION"),
+				NameSpace:      viper.GetString("PARTNER.MESSAGEBIRD.NAMESPACE"),
+				TemplateName:   viper.GetString("PARTNER.MESSAGEBIRD.TEMPLATE_NAME"),
+				FromID:         viper.GetString("PARTNER.MESSAGEBIRD.FROM_ID"),
+				LanguageCode:   viper.GetString("PARTNER.MESSAGEBIRD.LANGUAGE_CODE"),
+				Type:           viper.GetString("PARTNER.MESSAGEBIRD.TYPE"),
+				SMSURL:         viper.GetString("PARTNER.MESSAGEBIRD.SMS_URL"),
+				SMSOriginator:  viper.GetString("PARTNER.MESSAGEBIRD.SMS_ORIGINATOR"),
+				SMSTemplate:    viper.GetString("PARTNER.MESSAGEBIRD.SMS_TEMPLATE"),
 			},
 		},
 	}
 
+	otp := data.Authorization.OTP
+	if otp.Duration <= 0 || otp.MaxAttempt <= 0 || otp.LockDuration <= 0 {
+		return nil, errors.New("AUTHORIZATION.OTP DURATION, MAX_ATTEMPT & LOCK_DURATION must be greater than 0")
+	}
+
+	if data.Route.MaxBodySize <= 0 {
+		data.Route.MaxBodySize = cg.RouteDefaultMaxBodySize
+	}
+	if data.Route.MaxUploadSize <= 0 {
+		data.Route.MaxUploadSize = cg.RouteDefaultMaxUploadSize
+	}
+	if data.Route.MaxPageLimit <= 0 {
+		data.Route.MaxPageLimit = cg.RouteDefaultMaxPageLimit
+	}
+	if data.Route.MaxGetAllLimit <= 0 {
+		data.Route.MaxGetAllLimit = cg.RouteDefaultMaxGetAllLimit
+	}
+
+	data.Route.TrustedProxies, err = utils.StrToNetworks(strings.Split(viper.GetString("ROUTES.TRUSTED_PROXIES"), ","))
+	if err != nil {
+		return nil, err
+	}
+
+	err = viper.UnmarshalKey("AUTHORIZATION.JWT.KEYS", &data.Authorization.JWT.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	err = viper.UnmarshalKey("AUTHORIZATION.PHONE.KEYS", &data.Authorization.Phone.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	err = viper.UnmarshalKey("CACHE.TTL", &data.Cache.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Viper lowercase the map key, so the group is matched in lowercase.
+	err = viper.UnmarshalKey("AUTHORIZATION.OIDC.GROUP_ROLES", &data.Authorization.OIDC.GroupRoles)
+	if err != nil {
+		return nil, err
+	}
+
 	return data, nil
 }
 
 func NewRepoContext(conf *general.SectionService) (core.Handler, *logrus.Logger, error) {
 	var handler core.Handler
 
+	usecase, dbList, logger, err := NewUsecaseContext(conf)
+	if err != nil {
+		return handler, logger, err
+	}
+
+	handler = core.NewHandler(usecase, conf, dbList, logger)
+
+	return handler, logger, nil
+}
+
+// NewUsecaseContext init the connection & usecase, also used by the command that doesn't serve http.
+func NewUsecaseContext(conf *general.SectionService) (usecase.Usecase, *infra.DatabaseList, *logrus.Logger, error) {
+	var uc usecase.Usecase
+
 	// Init Log
 	logger := infra.NewLogger(conf)
 
+	// Init JWT signing key.
+	err := utils.InitJWTConfig(conf.Authorization.JWT)
+	if err != nil {
+		return uc, nil, logger, err
+	}
+
 	// Init DB Read Connection.
 	dbRead := infra.NewDB(logger)
 	dbRead.ConnectDB(&conf.Database.Read)
 	if dbRead.Err != nil {
-		return handler, logger, dbRead.Err
+		return uc, nil, logger, dbRead.Err
 	}
 
 	// Init DB Write Connection.
 	dbWrite := infra.NewDB(logger)
 	dbWrite.ConnectDB(&conf.Database.Write)
 	if dbWrite.Err != nil {
-		return handler, logger, dbWrite.Err
+		return uc, nil, logger, dbWrite.Err
 	}
 
 	dbList := &infra.DatabaseList{
@@ -109,9 +282,31 @@ func NewRepoContext(conf *general.SectionService) (core.Handler, *logrus.Logger,
 		},
 	}
 
+	// Init Redis Connection, only when configured.
+	if conf.Redis.URL != "" {
+		redis, err := infra.NewRedis(conf.Redis, logger)
+		if err != nil {
+			return uc, nil, logger, err
+		}
+
+		dbList.Redis = redis
+	}
+
+	// Init NSQ Producer, only when configured.
+	if conf.NSQProducer.NSQD != "" {
+		producer, err := infra.NewNSQProducer(conf.NSQProducer)
+		if err != nil {
+			return uc, nil, logger, err
+		}
+
+		dbList.NSQProducer = producer
+	}
+
 	repo := repo.NewRepo(dbList, logger)
-	usecase := usecase.NewUsecase(repo, conf, dbList, logger)
-	handler = core.NewHandler(usecase, conf, logger)
+	uc, err = usecase.NewUsecase(repo, conf, dbList, logger)
+	if err != nil {
+		return uc, nil, logger, err
+	}
 
-	return handler, logger, nil
+	return uc, dbList, logger, nil
 }
@@ -1,10 +1,8 @@
 package main
 
 import (
-	"crypto/sha256"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/furee/backend/cmd/core/config"
 	"github.com/furee/backend/cmd/core/routes"
@@ -17,14 +15,6 @@ func main() {
 		panic(err)
 	}
 
-	id := time.Now().UTC().Add(time.Duration(525960 * time.Minute)).Unix()
-	secret := conf.Authorization.Public.SecretKey //public secret key
-
-	authCompareByte := sha256.Sum256([]byte(fmt.Sprintf("%s%d", secret, id)))
-
-	fmt.Println(id)
-	fmt.Printf("%x\n", authCompareByte)
-
 	handler, log, err := config.NewRepoContext(conf)
 	if err != nil {
 		panic(err)
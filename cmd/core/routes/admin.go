@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"net/http"
+
+	ca "github.com/furee/backend/constants/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers/core"
+	"github.com/gorilla/mux"
+)
+
+func getAdmin(router, routerJWT *mux.Router, conf *general.SectionService, handler core.Handler) {
+	routerJWT.Handle("/admin/users", handler.Token.RequirePermission(ca.PermissionUserAdmin, handler.User.User.GetListUser)).Methods(http.MethodGet)
+	routerJWT.Handle("/admin/users/{userid}", handler.Token.RequirePermission(ca.PermissionUserAdmin, handler.User.User.GetUserDetail)).Methods(http.MethodGet)
+	routerJWT.Handle("/admin/users/{userid}/status", handler.Token.RequirePermission(ca.PermissionUserAdmin, handler.User.User.UpdateUserStatus)).Methods(http.MethodPut)
+	routerJWT.Handle("/admin/users/{userid}/logout", handler.Token.RequirePermission(ca.PermissionUserAdmin, handler.User.User.ForceLogout)).Methods(http.MethodPost)
+	routerJWT.Handle("/admin/users/{userid}/mfa", handler.Token.RequirePermission(ca.PermissionUserAdmin, handler.MFA.Reset)).Methods(http.MethodDelete)
+	routerJWT.Handle("/admin/otp-whitelists", handler.Token.RequirePermission(ca.PermissionOTPAdmin, handler.User.Whitelist.GetListWhitelist)).Methods(http.MethodGet)
+	routerJWT.Handle("/admin/otp-whitelists", handler.Token.RequirePermission(ca.PermissionOTPAdmin, handler.User.Whitelist.CreateWhitelist)).Methods(http.MethodPost)
+	routerJWT.Handle("/admin/otp-whitelists/{whitelistid}", handler.Token.RequirePermission(ca.PermissionOTPAdmin, handler.User.Whitelist.DeleteWhitelist)).Methods(http.MethodDelete)
+	routerJWT.Handle("/admin/auth-events", handler.Token.RequirePermission(ca.PermissionAuthEvent, handler.AuthEvent.GetListAuthEvent)).Methods(http.MethodGet)
+	routerJWT.Handle("/admin/api-keys", handler.Token.RequirePermission(ca.PermissionAPIKeyAdmin, handler.APIKey.GetListAPIKey)).Methods(http.MethodGet)
+	routerJWT.Handle("/admin/api-keys", handler.Token.RequirePermission(ca.PermissionAPIKeyAdmin, handler.APIKey.CreateAPIKey)).Methods(http.MethodPost)
+	routerJWT.Handle("/admin/api-keys/{apikeyid}", handler.Token.RequirePermission(ca.PermissionAPIKeyAdmin, handler.APIKey.RevokeAPIKey)).Methods(http.MethodDelete)
+	routerJWT.Handle("/admin/users/{userid}/roles", handler.Token.RequirePermission(ca.PermissionRoleAdmin, handler.Role.AssignRole)).Methods(http.MethodPut)
+	routerJWT.Handle("/admin/countries", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.CreateCountry)).Methods(http.MethodPost)
+	routerJWT.Handle("/admin/countries/{countryid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.UpdateCountry)).Methods(http.MethodPut)
+	routerJWT.Handle("/admin/countries/{countryid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.DeactivateCountry)).Methods(http.MethodDelete)
+	routerJWT.Handle("/admin/provinces", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.CreateProvince)).Methods(http.MethodPost)
+	routerJWT.Handle("/admin/provinces/{provinceid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.UpdateProvince)).Methods(http.MethodPut)
+	routerJWT.Handle("/admin/provinces/{provinceid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.DeactivateProvince)).Methods(http.MethodDelete)
+	routerJWT.Handle("/admin/cities", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.CreateCity)).Methods(http.MethodPost)
+	routerJWT.Handle("/admin/cities/{cityid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.UpdateCity)).Methods(http.MethodPut)
+	routerJWT.Handle("/admin/cities/{cityid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.DeactivateCity)).Methods(http.MethodDelete)
+	routerJWT.Handle("/admin/districts", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.CreateDistrict)).Methods(http.MethodPost)
+	routerJWT.Handle("/admin/districts/{districtid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.UpdateDistrict)).Methods(http.MethodPut)
+	routerJWT.Handle("/admin/districts/{districtid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.DeactivateDistrict)).Methods(http.MethodDelete)
+	routerJWT.Handle("/admin/sub-districts", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.CreateSubDistrict)).Methods(http.MethodPost)
+	routerJWT.Handle("/admin/sub-districts/{subdistrictid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.UpdateSubDistrict)).Methods(http.MethodPut)
+	routerJWT.Handle("/admin/sub-districts/{subdistrictid}", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.DeactivateSubDistrict)).Methods(http.MethodDelete)
+	routerJWT.Handle("/admin/locations/import", handler.Token.RequirePermission(ca.PermissionMasterAdmin, handler.Master.Admin.ImportLocation)).Methods(http.MethodPost)
+}
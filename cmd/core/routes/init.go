@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers"
 	"github.com/furee/backend/handlers/core"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -12,26 +13,50 @@ import (
 func GetCoreEndpoint(conf *general.SectionService, handler core.Handler, log *logrus.Logger) *mux.Router {
 	parentRoute := mux.NewRouter()
 
+	// Request id & device of the caller, used by the auth event.
+	parentRoute.Use(handlers.RequestContext(conf.Route.TrustedProxies))
+	parentRoute.Use(handlers.LimitBody(conf.Route.MaxBodySize, conf.Route.MaxUploadSize))
+
 	jwtRoute := parentRoute.PathPrefix(conf.App.Endpoint).Subrouter()
 	nonJWTRoute := parentRoute.PathPrefix(conf.App.Endpoint).Subrouter()
 	publicRoute := parentRoute.PathPrefix(conf.App.Endpoint).Subrouter()
+	mfaRoute := parentRoute.PathPrefix(conf.App.Endpoint).Subrouter()
+
+	// Public key to verify the access token.
+	parentRoute.HandleFunc("/.well-known/jwks.json", handler.Token.JWKS).Methods(http.MethodGet)
 
 	// Renew Access Token Endpoint.
 	publicRoute.HandleFunc("/renew-token", handler.Token.RenewAccessToken).Methods(http.MethodGet)
 
+	// Login of the internal staff through the company identity provider.
+	publicRoute.HandleFunc("/login/oidc", handler.OIDC.Login).Methods(http.MethodGet)
+	publicRoute.HandleFunc("/login/oidc/callback", handler.OIDC.Callback).Methods(http.MethodPost)
+
 	// Middleware for public API
 	nonJWTRoute.Use(handler.Public.AuthValidator)
 
+	// API key of the machine to machine integration, only accepted on the permission guarded route.
+	jwtRoute.Use(handler.APIKey.Validator)
+
 	// Middleware
 	if conf.Authorization.JWT.IsActive {
 		log.Info("JWT token is active")
 		jwtRoute.Use(handler.Token.JWTValidator)
 	}
 
+	// Second step of the login, only accept the mfa_pending token.
+	mfaRoute.Use(handler.Token.MFAPendingValidator)
+	mfaRoute.Handle("/login/mfa", handler.RateLimit.OTPVerifyLimiter(http.HandlerFunc(handler.MFA.Verify))).Methods(http.MethodPost)
+
+	// Logout Endpoint.
+	jwtRoute.HandleFunc("/logout", handler.Token.Logout).Methods(http.MethodPost)
+	jwtRoute.HandleFunc("/logout-all", handler.Token.LogoutAll).Methods(http.MethodPost)
+
 	// Get Endpoint.
 	getMasterData(nonJWTRoute, jwtRoute, conf, handler)
 	getUser(nonJWTRoute, jwtRoute, conf, handler)
 	getOrder(nonJWTRoute, jwtRoute, conf, handler)
+	getAdmin(nonJWTRoute, jwtRoute, conf, handler)
 
 	return parentRoute
 }
@@ -14,4 +14,6 @@ func getMasterData(router, routerJWT *mux.Router, conf *general.SectionService,
 	routerJWT.HandleFunc("/city", handler.Master.City.GetListCity).Methods(http.MethodGet)
 	routerJWT.HandleFunc("/district", handler.Master.District.GetListDistrict).Methods(http.MethodGet)
 	routerJWT.HandleFunc("/sub-district", handler.Master.SubDistrict.GetListSubDistrict).Methods(http.MethodGet)
+	routerJWT.HandleFunc("/locations/tree", handler.Master.Location.GetTree).Methods(http.MethodGet)
+	routerJWT.HandleFunc("/locations/{subdistrictid}/path", handler.Master.Location.GetPath).Methods(http.MethodGet)
 }
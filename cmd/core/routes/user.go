@@ -9,6 +9,25 @@ import (
 )
 
 func getUser(router, routerJWT *mux.Router, conf *general.SectionService, handler core.Handler) {
-	router.HandleFunc("/verify-user", handler.User.User.VerifyOTP).Methods(http.MethodPost)
-	router.HandleFunc("/login", handler.User.User.LoginUser).Methods(http.MethodPost)
+	router.Handle("/verify-user", handler.RateLimit.OTPVerifyLimiter(http.HandlerFunc(handler.User.User.VerifyOTP))).Methods(http.MethodPost)
+	router.Handle("/login", handler.RateLimit.OTPSendLimiter(http.HandlerFunc(handler.User.User.LoginUser))).Methods(http.MethodPost)
+
+	// Password login, only for user type that enabled on config.
+	router.Handle("/login/password", handler.RateLimit.OTPVerifyLimiter(http.HandlerFunc(handler.User.User.LoginPassword))).Methods(http.MethodPost)
+	router.Handle("/password/forgot", handler.RateLimit.OTPSendLimiter(http.HandlerFunc(handler.User.User.ForgotPassword))).Methods(http.MethodPost)
+	router.Handle("/password/reset", handler.RateLimit.OTPVerifyLimiter(http.HandlerFunc(handler.User.User.ResetPassword))).Methods(http.MethodPost)
+
+	// Profile of the logged in user.
+	routerJWT.HandleFunc("/me", handler.User.User.GetProfile).Methods(http.MethodGet)
+	routerJWT.HandleFunc("/me", handler.User.User.UpdateProfile).Methods(http.MethodPut)
+	routerJWT.HandleFunc("/me", handler.User.User.RequestDeletion).Methods(http.MethodDelete)
+	routerJWT.HandleFunc("/me/deletion", handler.User.User.CancelDeletion).Methods(http.MethodDelete)
+	routerJWT.HandleFunc("/me/export", handler.User.User.RequestExport).Methods(http.MethodPost)
+	routerJWT.HandleFunc("/me/jobs/{jobid}", handler.User.User.GetJob).Methods(http.MethodGet)
+	routerJWT.Handle("/me/phone", handler.RateLimit.OTPSendLimiter(http.HandlerFunc(handler.User.User.RequestPhoneChange))).Methods(http.MethodPost)
+	routerJWT.Handle("/me/phone/verify", handler.RateLimit.OTPVerifyLimiter(http.HandlerFunc(handler.User.User.VerifyPhoneChange))).Methods(http.MethodPost)
+	routerJWT.HandleFunc("/me/sessions", handler.Session.GetListSession).Methods(http.MethodGet)
+	routerJWT.HandleFunc("/me/sessions/{sessionid}", handler.Session.RevokeSession).Methods(http.MethodDelete)
+	routerJWT.HandleFunc("/me/mfa/enroll", handler.MFA.Enroll).Methods(http.MethodPost)
+	routerJWT.Handle("/me/mfa/activate", handler.RateLimit.OTPVerifyLimiter(http.HandlerFunc(handler.MFA.Activate))).Methods(http.MethodPost)
 }
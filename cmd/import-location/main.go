@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/furee/backend/cmd/core/config"
+	cg "github.com/furee/backend/constants/general"
+	cm "github.com/furee/backend/constants/master"
+	"github.com/furee/backend/utils"
+)
+
+// Upsert the location master data from the official region code CSV, by the code.
+// The CSV header is code,name & optional parent_code, the level without file is not changed.
+// go run cmd/import-location/main.go -province=provinces.csv -city=cities.csv -dry-run
+func main() {
+	paths := make(map[string]*string)
+	for _, level := range cm.LocationLevels {
+		paths[level] = flag.String(level, "", fmt.Sprintf("path of the %s CSV", level))
+	}
+
+	dryRun := flag.Bool("dry-run", false, "only report the change without saving it")
+	flag.Parse()
+
+	files := make(map[string]io.Reader)
+	for level, path := range paths {
+		if *path == "" {
+			continue
+		}
+
+		file, err := os.Open(*path)
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+
+		files[level] = file
+	}
+
+	conf, err := config.GetCoreConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	uc, _, logger, err := config.NewUsecaseContext(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	// Only the shared cache is invalidated for the running server, the memory cache is per process.
+	if !*dryRun && conf.Cache.Backend != cg.CacheBackendRedis {
+		logger.Warn("cache backend is not redis, restart the running server to drop the cached master data")
+	}
+
+	report, message, err := uc.Master.Admin.ImportLocation(context.Background(), files, *dryRun)
+	if err != nil {
+		if message != "" {
+			fmt.Println(message)
+			os.Exit(1)
+		}
+
+		panic(err)
+	}
+
+	fmt.Println(message)
+	fmt.Println(utils.StructToString(report))
+}
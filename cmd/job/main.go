@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/furee/backend/cmd/core/config"
+)
+
+// Process the user job (data export & account deletion) that requested through the API,
+// the expired data export is deleted when there is no due job.
+// go run cmd/job/main.go -interval=10s
+func main() {
+	interval := flag.Duration("interval", 10*time.Second, "wait time when there is no due job")
+	once := flag.Bool("once", false, "process the due job then exit")
+	flag.Parse()
+
+	conf, err := config.GetCoreConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	uc, _, logger, err := config.NewUsecaseContext(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	logger.Info("user job worker started")
+
+	for {
+		select {
+		case <-stop:
+			logger.Info("user job worker stopped")
+			return
+		default:
+		}
+
+		ran, err := uc.User.User.RunNextJob()
+		if err != nil {
+			logger.WithError(err).Error("fail to run user job")
+		}
+
+		if ran {
+			continue
+		}
+
+		purged, err := uc.User.User.PurgeExpiredExports()
+		if err != nil {
+			logger.WithError(err).Error("fail to purge expired export")
+		}
+
+		if purged > 0 {
+			logger.WithField("total", purged).Info("expired export purged")
+		}
+
+		if *once {
+			return
+		}
+
+		select {
+		case <-stop:
+			logger.Info("user job worker stopped")
+			return
+		case <-time.After(*interval):
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/furee/backend/cmd/core/config"
+	"github.com/furee/backend/utils"
+)
+
+// Re-normalize the phone of existing user to E.164 & re-hash the phone filter.
+// go run cmd/migrate-phone/main.go -dry-run
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only report the change without updating the user")
+	flag.Parse()
+
+	conf, err := config.GetCoreConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	uc, _, _, err := config.NewUsecaseContext(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	report, err := uc.User.User.NormalizePhones(*dryRun)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(utils.StructToString(report))
+}
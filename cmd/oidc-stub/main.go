@@ -0,0 +1,210 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	dg "github.com/furee/backend/domain/general"
+)
+
+// Local identity provider to try the staff login without the company identity provider.
+// Every authorization request is approved as the given email & groups.
+// go run cmd/oidc-stub/main.go -addr=:9000 -email=staff@furee.id -groups=engineering
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer url, must be the same as AUTHORIZATION.OIDC.ISSUER_URL")
+	clientID := flag.String("client-id", "furee-internal-tools", "accepted client id")
+	clientSecret := flag.String("client-secret", "abcdefgqwerty", "accepted client secret")
+	email := flag.String("email", "staff@furee.id", "email of the logged in staff")
+	groups := flag.String("groups", "engineering", "comma separated group of the logged in staff")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	stub := &stubProvider{
+		issuer:       strings.TrimSuffix(*issuer, "/"),
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		email:        *email,
+		groups:       strings.Split(*groups, ","),
+		key:          key,
+		codes:        make(map[string]stubCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", stub.discovery)
+	mux.HandleFunc("/authorize", stub.authorize)
+	mux.HandleFunc("/token", stub.token)
+	mux.HandleFunc("/jwks", stub.jwks)
+
+	log.Printf("oidc stub listen to %s as %s", *addr, stub.issuer)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+const stubKeyID = "stub"
+
+type stubCode struct {
+	nonce         string
+	codeChallenge string
+	redirectURI   string
+	expiredAt     time.Time
+}
+
+type stubProvider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	email        string
+	groups       []string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]stubCode
+}
+
+func (sp *stubProvider) discovery(res http.ResponseWriter, req *http.Request) {
+	writeJSON(res, http.StatusOK, da.OIDCDiscovery{
+		Issuer:                sp.issuer,
+		AuthorizationEndpoint: sp.issuer + "/authorize",
+		TokenEndpoint:         sp.issuer + "/token",
+		JWKSURI:               sp.issuer + "/jwks",
+	})
+}
+
+func (sp *stubProvider) authorize(res http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	if query.Get("client_id") != sp.clientID || query.Get("response_type") != "code" {
+		writeJSON(res, http.StatusBadRequest, map[string]string{"error": "unauthorized_client"})
+		return
+	}
+
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		writeJSON(res, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	redirectURL, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURL.Scheme == "" {
+		writeJSON(res, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := randomString()
+
+	sp.mu.Lock()
+	sp.codes[code] = stubCode{
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		redirectURI:   query.Get("redirect_uri"),
+		expiredAt:     time.Now().Add(time.Minute),
+	}
+	sp.mu.Unlock()
+
+	param := redirectURL.Query()
+	param.Set("code", code)
+	param.Set("state", query.Get("state"))
+	redirectURL.RawQuery = param.Encode()
+
+	http.Redirect(res, req, redirectURL.String(), http.StatusFound)
+}
+
+func (sp *stubProvider) token(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeJSON(res, http.StatusMethodNotAllowed, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	if req.FormValue("client_id") != sp.clientID || req.FormValue("client_secret") != sp.clientSecret {
+		writeJSON(res, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	sp.mu.Lock()
+	code, ok := sp.codes[req.FormValue("code")]
+	delete(sp.codes, req.FormValue("code"))
+	sp.mu.Unlock()
+
+	if !ok || time.Now().After(code.expiredAt) || code.redirectURI != req.FormValue("redirect_uri") {
+		writeJSON(res, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(req.FormValue("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != code.codeChallenge {
+		writeJSON(res, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            sp.issuer,
+		"sub":            sp.email,
+		"aud":            sp.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          code.nonce,
+		"email":          sp.email,
+		"email_verified": true,
+		"groups":         sp.groups,
+	})
+	token.Header["kid"] = stubKeyID
+
+	idToken, err := token.SignedString(sp.key)
+	if err != nil {
+		writeJSON(res, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(res, http.StatusOK, da.OIDCTokenResponse{
+		AccessToken: randomString(),
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+		ExpiresIn:   300,
+	})
+}
+
+func (sp *stubProvider) jwks(res http.ResponseWriter, req *http.Request) {
+	writeJSON(res, http.StatusOK, dg.JWKS{
+		Keys: []dg.JWK{
+			{
+				KeyType:   "RSA",
+				KeyID:     stubKeyID,
+				Use:       "sig",
+				Algorithm: jwt.SigningMethodRS256.Alg(),
+				Modulus:   base64.RawURLEncoding.EncodeToString(sp.key.N.Bytes()),
+				Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(sp.key.E)).Bytes()),
+			},
+		},
+	})
+}
+
+func writeJSON(res http.ResponseWriter, code int, data interface{}) {
+	res.Header().Set(cg.APIHeaderContentType, cg.APIHeaderContentTypeJSon)
+	res.WriteHeader(code)
+	json.NewEncoder(res).Encode(data)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
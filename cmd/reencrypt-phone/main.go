@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/furee/backend/cmd/core/config"
+	"github.com/furee/backend/utils"
+)
+
+// Re-encrypt the user phone with the active key version after the key is rotated.
+// go run cmd/reencrypt-phone/main.go -batch=500 -pause=1s
+func main() {
+	batchSize := flag.Int("batch", 500, "number of user processed per batch")
+	pause := flag.Duration("pause", time.Second, "pause between batch to reduce the database load")
+	dryRun := flag.Bool("dry-run", false, "only report the change without updating the user")
+	flag.Parse()
+
+	conf, err := config.GetCoreConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	uc, _, logger, err := config.NewUsecaseContext(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	report, err := uc.User.User.ReencryptPhones(*batchSize, *dryRun, func(page int) {
+		logger.WithField("page", page).Info("reencrypt phone batch done")
+		time.Sleep(*pause)
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(utils.StructToString(report))
+}
@@ -0,0 +1,67 @@
+package authorization
+
+// List of permission that can be given to a role.
+const (
+	PermissionOrderRead   string = "orders:read"
+	PermissionOrderWrite  string = "orders:write"
+	PermissionMasterAdmin string = "master:admin"
+	PermissionRoleAdmin   string = "roles:admin"
+	PermissionUserAdmin   string = "users:admin"
+	PermissionOTPAdmin    string = "otp:admin"
+	PermissionAuthEvent   string = "auth_events:read"
+	PermissionAPIKeyAdmin string = "api_keys:admin"
+)
+
+// APIKeyScopes is the permission that can be given to an API key. The admin permission is only for the user,
+// so the actor of every admin action is known.
+var APIKeyScopes = []string{PermissionOrderRead, PermissionOrderWrite, PermissionAuthEvent}
+
+// List of action that written to the audit log.
+const (
+	AuditActionOTPBypass          string = "otp.bypass"
+	AuditActionOTPWhitelistCreate string = "otp_whitelist.create"
+	AuditActionOTPWhitelistDelete string = "otp_whitelist.delete"
+	AuditActionExportRequest      string = "user.export_request"
+	AuditActionExportDone         string = "user.export_done"
+	AuditActionDeleteRequest      string = "user.delete_request"
+	AuditActionDeleteCancel       string = "user.delete_cancel"
+	AuditActionDeleteDone         string = "user.delete_done"
+	AuditActionAPIKeyCreate       string = "api_key.create"
+	AuditActionAPIKeyRevoke       string = "api_key.revoke"
+	AuditActionMasterCreate       string = "master.create"
+	AuditActionMasterUpdate       string = "master.update"
+	AuditActionMasterDeactivate   string = "master.deactivate"
+	AuditActionMasterImport       string = "master.import"
+	AuditActionRoleAssign         string = "user.role_assign"
+	AuditActionMFAReset           string = "user.mfa_reset"
+	AuditActionForceLogout        string = "user.force_logout"
+	AuditActionStatusUpdate       string = "user.status_update"
+)
+
+// List of entity type on the audit log.
+const (
+	AuditEntityUser         string = "user"
+	AuditEntityOTPWhitelist string = "otp_whitelist"
+	AuditEntityAPIKey       string = "api_key"
+	AuditEntityCountry      string = "country"
+	AuditEntityProvince     string = "province"
+	AuditEntityCity         string = "city"
+	AuditEntityDistrict     string = "district"
+	AuditEntitySubDistrict  string = "sub_district"
+	AuditEntityLocation     string = "location"
+)
+
+// List of authentication event that written to the auth event log & published to the security team.
+const (
+	AuthEventOTPSent          string = "otp_sent"
+	AuthEventOTPFailed        string = "otp_failed"
+	AuthEventOTPVerified      string = "otp_verified"
+	AuthEventLockout          string = "lockout"
+	AuthEventTokenRenew       string = "token_renew"
+	AuthEventTokenReuse       string = "token_reuse_detected"
+	AuthEventLogout           string = "logout"
+	AuthEventPublicAuthFailed string = "public_auth_failed"
+	AuthEventOIDCLogin        string = "oidc_login"
+	AuthEventOIDCFailed       string = "oidc_failed"
+	AuthEventAPIKeyFailed     string = "api_key_failed"
+)
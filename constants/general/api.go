@@ -12,10 +12,31 @@ const (
 )
 
 const (
-	APIHeaderContentType   string = "Content-Type"
-	APIHeaderBorzoToken    string = "X-DV-Auth-Token"
-	APIHeaderJetClientKey  string = "clientkey"
-	APIHeaderAuthorization string = "Authorization"
+	APIHeaderContentType     string = "Content-Type"
+	APIHeaderBorzoToken      string = "X-DV-Auth-Token"
+	APIHeaderJetClientKey    string = "clientkey"
+	APIHeaderAuthorization   string = "Authorization"
+	APIHeaderAuthorizationID string = "Authorization-ID"
+	APIHeaderRetryAfter      string = "Retry-After"
+	APIHeaderForwardedFor    string = "X-Forwarded-For"
+	APIHeaderRealIP          string = "X-Real-IP"
+	APIHeaderTimestamp       string = "X-Timestamp"
+	APIHeaderNonce           string = "X-Nonce"
+	APIHeaderDeviceName      string = "X-Device-Name"
+	APIHeaderUserAgent       string = "User-Agent"
+	APIHeaderRequestID       string = "X-Request-ID"
+)
+
+// OTP sender channel.
+const (
+	OTPChannelWhatsapp string = "whatsapp"
+	OTPChannelSMS      string = "sms"
+	OTPChannelLog      string = "log"
+)
+
+const (
+	OTPDeliverySuccess string = "success"
+	OTPDeliveryFail    string = "fail"
 )
 
 const (
@@ -29,6 +50,7 @@ const (
 	HandlerErrorRequestDataNotValid      string = "request data not valid"
 	HandlerErrorRequestDataEmpty         string = "request data empty"
 	HandlerErrorRequestDataFormatInvalid string = "request data format invalid"
+	HandlerErrorRequestDataTooLarge      string = "request data too large"
 	HandlerErrorCookiesEmpty             string = "key data cannot be empty"
 	HandlerErrorCookiesInvalid           string = "key data invalid"
 	HandlerErrorKeyIDInvalid             string = "key id invalid"
@@ -38,4 +60,7 @@ const (
 	HandlerErrorFileSizeTooLarge         string = "file too large, max size 1 Mb"
 	HandlerErrorFileDataInvalid          string = "file data invalid"
 	HandlerErrorFileDataEmpty            string = "file data cannot be empty"
+	HandlerErrorTooManyRequest           string = "too many request, please try again later"
+	HandlerErrorPermissionDenied         string = "permission denied"
+	HandlerErrorTokenInvalid             string = "Token Not Valid"
 )
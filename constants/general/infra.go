@@ -29,15 +29,60 @@ const (
 )
 
 const (
-	EnvStaging = "staging"
-	EnvProd    = "production"
+	EnvLocal       = "local"
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProd        = "production"
 )
 
+// NonProdEnvs is the environment that is known as non production, any other value is treated as production.
+var NonProdEnvs = []string{EnvLocal, EnvDevelopment, EnvStaging}
+
 const (
 	LogRotationTime = time.Duration(24) * time.Hour
 	MaxRotationFile = 4
 )
 
 const (
-	SessionContextKey = "session"
+	RateLimitBackendMemory = "memory"
+	RateLimitBackendRedis  = "redis"
+
+	RateLimitOTPSend   = "otp_send"
+	RateLimitOTPVerify = "otp_verify"
+)
+
+// Default max size of the request body in bytes, used when it is not set on the config.
+// Multipart request has its own limit since it carries the uploaded file.
+const (
+	RouteDefaultMaxBodySize   int64 = 1 * 1024 * 1024
+	RouteDefaultMaxUploadSize int64 = 20 * 1024 * 1024
+)
+
+// Default max page size of the list, used when it is not set on the config.
+const (
+	RouteDefaultMaxPageLimit   = 100
+	RouteDefaultMaxGetAllLimit = 1000
+)
+
+// Default of the OTP setting, used when it is not set on the config.
+const (
+	OTPDefaultDuration     = 5 // in minutes
+	OTPDefaultMaxAttempt   = 5
+	OTPDefaultLockDuration = 15 // in minutes
+)
+
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendRedis  = "redis"
+)
+
+const (
+	NonceBackendMemory = "memory"
+	NonceBackendRedis  = "redis"
+)
+
+const (
+	JWTSigningMethodHMAC  = "HMAC"
+	JWTSigningMethodRS256 = "RS256"
+	JWTSigningMethodEdDSA = "EdDSA"
 )
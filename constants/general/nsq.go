@@ -4,3 +4,8 @@ const (
 	NSQMaxAttemps1000 int = 1000
 	NSQMaxAttemps500  int = 500
 )
+
+// List of NSQ topic that published by the service.
+const (
+	NSQTopicAuthEvent string = "auth_event"
+)
@@ -0,0 +1,29 @@
+package master
+
+// Level of the location hierarchy, from the top.
+const (
+	LocationLevelCountry     string = "country"
+	LocationLevelProvince    string = "province"
+	LocationLevelCity        string = "city"
+	LocationLevelDistrict    string = "district"
+	LocationLevelSubDistrict string = "sub_district"
+)
+
+const (
+	// LocationTreeDefaultDepth is the number of level below the root returned when the depth is not set.
+	LocationTreeDefaultDepth int = 1
+)
+
+// LocationLevels is ordered from the top to the bottom of the hierarchy.
+var LocationLevels = []string{
+	LocationLevelCountry,
+	LocationLevelProvince,
+	LocationLevelCity,
+	LocationLevelDistrict,
+	LocationLevelSubDistrict,
+}
+
+const (
+	// LocationImportMaxSize is the max total size of the uploaded region code CSV.
+	LocationImportMaxSize int64 = 20 * 1024 * 1024
+)
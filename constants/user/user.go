@@ -1 +1,31 @@
 package user
+
+// User status.
+const (
+	StatusInactive int = 0
+	StatusActive   int = 1
+	StatusBlocked  int = 2
+	StatusDeleted  int = 3
+)
+
+// User type, password login is enabled per user type on config.
+const (
+	TypeCustomer string = "customer"
+	TypeStaff    string = "staff"
+)
+
+// User job type & status, the job is processed by cmd/job.
+const (
+	JobTypeExport string = "export"
+	JobTypeDelete string = "delete"
+
+	JobStatusPending   string = "pending"
+	JobStatusRunning   string = "running"
+	JobStatusDone      string = "done"
+	JobStatusFailed    string = "failed"
+	JobStatusCancelled string = "cancelled"
+	JobStatusExpired   string = "expired"
+)
+
+// DeletedUserName replace the name of the deleted user & the customer name of the order.
+const DeletedUserName string = "Deleted User"
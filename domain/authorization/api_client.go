@@ -0,0 +1,37 @@
+package authorization
+
+import (
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// APIClient is the caller of the public endpoint, every client has its own secret.
+// AllowedRoutes is list of "METHOD /path/template", use * for any method or "*" for every route.
+type APIClient struct {
+	ID            string         `json:"id" db:"client_id"`
+	Name          string         `json:"name" db:"name"`
+	Secret        string         `json:"-" db:"secret"`
+	AllowedRoutes pq.StringArray `json:"allowed_routes" db:"allowed_routes"`
+	IsActive      bool           `json:"is_active" db:"is_active"`
+}
+
+// IsRouteAllowed check the method & mux path template against the allowed routes.
+func (ac APIClient) IsRouteAllowed(method, pathTemplate string) bool {
+	for _, route := range ac.AllowedRoutes {
+		if route == "*" {
+			return true
+		}
+
+		parts := strings.Fields(route)
+		if len(parts) != 2 {
+			continue
+		}
+
+		if (parts[0] == "*" || strings.EqualFold(parts[0], method)) && parts[1] == pathTemplate {
+			return true
+		}
+	}
+
+	return false
+}
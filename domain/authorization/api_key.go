@@ -0,0 +1,89 @@
+package authorization
+
+import (
+	"net"
+	"time"
+
+	ca "github.com/furee/backend/constants/authorization"
+	"github.com/lib/pq"
+	"gopkg.in/guregu/null.v4"
+)
+
+// APIKey is the long-lived credential of the partner system, sent as "Authorization: ApiKey <prefix>.<secret>".
+// Only the SHA-256 of the secret is stored, the scope is the permission that the key can use.
+type APIKey struct {
+	ID         int64          `json:"id" db:"api_key_id"`
+	Name       string         `json:"name" db:"name"`
+	Prefix     string         `json:"prefix" db:"prefix"`
+	SecretHash string         `json:"-" db:"secret_hash"`
+	Scopes     pq.StringArray `json:"scopes" db:"scopes"`
+	AllowedIPs pq.StringArray `json:"allowed_ips" db:"allowed_ips"`
+	ExpiredAt  *time.Time     `json:"expired_at" db:"expired_at"`
+	LastUsedAt *time.Time     `json:"last_used_at" db:"last_used_at"`
+	LastUsedIP null.String    `json:"last_used_ip" db:"last_used_ip"`
+	RevokedAt  *time.Time     `json:"revoked_at" db:"revoked_at"`
+	CreatedBy  int64          `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
+
+func (ak APIKey) HasScope(scope string) bool {
+	for _, s := range ak.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAPIKeyScope check the permission can be given to an API key.
+func IsAPIKeyScope(scope string) bool {
+	for _, s := range ca.APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsIPAllowed check the caller IP against the allowlist, entry is either IP or CIDR. Empty allowlist accept any IP.
+func (ak APIKey) IsIPAllowed(ip string) bool {
+	if len(ak.AllowedIPs) == 0 {
+		return true
+	}
+
+	callerIP := net.ParseIP(ip)
+	if callerIP == nil {
+		return false
+	}
+
+	for _, allowed := range ak.AllowedIPs {
+		if _, network, err := net.ParseCIDR(allowed); err == nil {
+			if network.Contains(callerIP) {
+				return true
+			}
+
+			continue
+		}
+
+		if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(callerIP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type APIKeyRequest struct {
+	Name       string     `json:"name" validate:"empty=false"`
+	Scopes     []string   `json:"scopes" validate:"empty=false"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	ExpiredAt  *time.Time `json:"expired_at"`
+}
+
+// APIKeyResponse is the created key, the plain key is only returned once.
+type APIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
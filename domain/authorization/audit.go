@@ -0,0 +1,18 @@
+package authorization
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// AuditLog is the record of sensitive action, ActorID is empty when the action is done by the system.
+type AuditLog struct {
+	ID         int64     `json:"id" db:"audit_log_id"`
+	ActorID    null.Int  `json:"actor_id" db:"actor_id"`
+	Action     string    `json:"action" db:"action"`
+	EntityType string    `json:"entity_type" db:"entity_type"`
+	EntityID   string    `json:"entity_id" db:"entity_id"`
+	Detail     string    `json:"detail" db:"detail"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
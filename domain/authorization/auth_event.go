@@ -0,0 +1,32 @@
+package authorization
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// AuthEvent is the append-only record of the authentication activity, used to investigate account takeover.
+// PhoneHash is the phone filter (blind index), the plain phone is never stored.
+type AuthEvent struct {
+	ID        int64       `json:"id" db:"auth_event_id"`
+	EventType string      `json:"event_type" db:"event_type"`
+	UserID    null.Int    `json:"user_id" db:"user_id"`
+	PhoneHash null.String `json:"phone_hash" db:"phone_hash"`
+	IPAddress string      `json:"ip_address" db:"ip_address"`
+	UserAgent string      `json:"user_agent" db:"user_agent"`
+	RequestID string      `json:"request_id" db:"request_id"`
+	Detail    null.String `json:"detail" db:"detail"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+type AuthEventFilter struct {
+	UserID      null.Int
+	EventType   null.String
+	Phone       null.String
+	PhoneHash   null.String
+	IPAddress   null.String
+	RequestID   null.String
+	CreatedFrom null.Time
+	CreatedTo   null.Time
+}
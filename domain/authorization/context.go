@@ -0,0 +1,99 @@
+package authorization
+
+import (
+	"context"
+
+	du "github.com/furee/backend/domain/user"
+)
+
+// contextKey is unexported so the value can only be set through this package.
+type contextKey int
+
+const (
+	sessionContextKey contextKey = iota
+	userContextKey
+	clientContextKey
+	deviceContextKey
+	requestIDContextKey
+	apiKeyContextKey
+)
+
+// WithSession store the token session of the request.
+func WithSession(ctx context.Context, session TokenSession) context.Context {
+	return context.WithValue(ctx, sessionContextKey, session)
+}
+
+// GetSession return the token session of the request.
+func GetSession(ctx context.Context) (TokenSession, bool) {
+	session, ok := ctx.Value(sessionContextKey).(TokenSession)
+	return session, ok
+}
+
+// WithUser store the authenticated user of the request.
+func WithUser(ctx context.Context, user *du.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// CurrentUser return the authenticated user of the request.
+func CurrentUser(ctx context.Context) (*du.User, bool) {
+	user, ok := ctx.Value(userContextKey).(*du.User)
+	if !ok || user == nil {
+		return nil, false
+	}
+
+	return user, true
+}
+
+// CurrentUserID return the authenticated user id, 0 when the request is not authenticated.
+func CurrentUserID(ctx context.Context) int64 {
+	user, ok := CurrentUser(ctx)
+	if !ok {
+		return 0
+	}
+
+	return user.ID
+}
+
+// WithClient store the API client that signed the public request.
+func WithClient(ctx context.Context, client *APIClient) context.Context {
+	return context.WithValue(ctx, clientContextKey, client)
+}
+
+// CurrentClient return the API client of the public request.
+func CurrentClient(ctx context.Context) (*APIClient, bool) {
+	client, ok := ctx.Value(clientContextKey).(*APIClient)
+	return client, ok && client != nil
+}
+
+// WithDevice store the device of the request.
+func WithDevice(ctx context.Context, device DeviceInfo) context.Context {
+	return context.WithValue(ctx, deviceContextKey, device)
+}
+
+// GetDevice return the device of the request, empty when it's not set.
+func GetDevice(ctx context.Context) DeviceInfo {
+	device, _ := ctx.Value(deviceContextKey).(DeviceInfo)
+	return device
+}
+
+// WithRequestID store the id of the request, the same id is returned on the response header.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// GetRequestID return the id of the request, empty when it's not set.
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// WithAPIKey store the API key that authenticated the request.
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey, key)
+}
+
+// CurrentAPIKey return the API key of the request, false when the request is authenticated with JWT.
+func CurrentAPIKey(ctx context.Context) (*APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey).(*APIKey)
+	return key, ok && key != nil
+}
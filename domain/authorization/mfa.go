@@ -0,0 +1,37 @@
+package authorization
+
+import "time"
+
+// UserMFA is the TOTP secret of the user, the secret is stored encrypted.
+// MFA is only challenged on login after the enrolment is activated.
+type UserMFA struct {
+	UserID        int64      `json:"user_id" db:"user_id"`
+	Secret        string     `json:"-" db:"secret"`
+	IsEnabled     bool       `json:"is_enabled" db:"is_enabled"`
+	LastUsedStep  int64      `json:"-" db:"last_used_step"`
+	FailedAttempt int        `json:"-" db:"failed_attempt"`
+	LockCount     int        `json:"-" db:"lock_count"`
+	LockedUntil   *time.Time `json:"-" db:"locked_until"`
+	EnabledAt     *time.Time `json:"enabled_at" db:"enabled_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode string `json:"qr_code"` // base64 png
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"empty=false"`
+}
+
+// MFAVerifyRequest is the second step of the login, either TOTP code or one of the recovery code.
+type MFAVerifyRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFARecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,45 @@
+package authorization
+
+import "time"
+
+// OIDCDiscovery is the metadata of the identity provider, only the used field is decoded.
+type OIDCDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCTokenResponse is the response of the token endpoint after the code is exchanged.
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// OIDCState is kept encrypted on the cookie between the login & the callback.
+type OIDCState struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiredAt    time.Time `json:"expired_at"`
+}
+
+// OIDCIdentity is the verified claim of the ID token.
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Groups        []string
+}
+
+type OIDCLoginResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	ExpiredAt        time.Time `json:"expired_at"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"empty=false"`
+	State string `json:"state" validate:"empty=false"`
+}
@@ -0,0 +1,11 @@
+package authorization
+
+type Role struct {
+	ID          int64  `json:"id" db:"role_id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+}
+
+type AssignRoleRequest struct {
+	Roles []string `json:"roles"`
+}
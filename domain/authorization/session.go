@@ -0,0 +1,34 @@
+package authorization
+
+import "time"
+
+// UserSession is the login of the user on a device, one session for each refresh token family.
+type UserSession struct {
+	ID         string     `json:"id" db:"session_id"`
+	UserID     int64      `json:"user_id" db:"user_id"`
+	FamilyID   string     `json:"-" db:"family_id"`
+	DeviceName string     `json:"device_name" db:"device_name"`
+	UserAgent  string     `json:"user_agent" db:"user_agent"`
+	IPAddress  string     `json:"ip_address" db:"ip_address"`
+	ExpiredAt  time.Time  `json:"expired_at" db:"expired_at"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DeviceInfo is the device of the login request, stored on the session.
+type DeviceInfo struct {
+	Name      string
+	UserAgent string
+	IPAddress string
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	IsCurrent  bool      `json:"is_current"`
+}
@@ -0,0 +1,43 @@
+package authorization
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+type RefreshToken struct {
+	ID         string      `json:"id" db:"refresh_token_id"`
+	FamilyID   string      `json:"family_id" db:"family_id"`
+	UserID     int64       `json:"user_id" db:"user_id"`
+	ExpiredAt  time.Time   `json:"expired_at" db:"expired_at"`
+	RevokedAt  *time.Time  `json:"revoked_at" db:"revoked_at"`
+	ReplacedBy null.String `json:"replaced_by" db:"replaced_by"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
+
+type RevokedAccessToken struct {
+	ID        string    `json:"id" db:"access_token_id"`
+	ExpiredAt time.Time `json:"expired_at" db:"expired_at"`
+}
+
+// TokenSession is the identity of the token that used on the current request.
+type TokenSession struct {
+	UserID             int64
+	AccessTokenID      string
+	AccessTokenExpired time.Time
+	FamilyID           string
+	Roles              []string
+	Permissions        []string
+	MFAPending         bool
+}
+
+func (ts TokenSession) HasPermission(permission string) bool {
+	for _, p := range ts.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
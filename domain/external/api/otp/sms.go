@@ -0,0 +1,7 @@
+package otp
+
+type MessageBirdSMSAPI struct {
+	Originator string   `json:"originator"`
+	Recipients []string `json:"recipients"`
+	Body       string   `json:"body"`
+}
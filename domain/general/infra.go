@@ -1,6 +1,9 @@
 package general
 
 import (
+	"net"
+	"time"
+
 	"github.com/furee/backend/constants/general"
 )
 
@@ -18,6 +21,9 @@ type SectionService struct {
 	PartnerSecret PartnerSecret    `json:",omitempty"`
 	Logistic      LogisticSecret   `json:",omitempty"`
 	Whitelist     WhitelistAccount `json:",omitempty"`
+	RateLimit     RateLimitAccount `json:",omitempty"`
+	Privacy       PrivacyAccount   `json:",omitempty"`
+	Cache         CacheAccount     `json:",omitempty"`
 }
 
 type AppAccount struct {
@@ -29,10 +35,27 @@ type AppAccount struct {
 	Endpoint     string `json:",omitempty"`
 }
 
+// IsNonProd check the environment exactly match one of the known non production environment,
+// any other value including the empty one is treated as production.
+func (aa AppAccount) IsNonProd() bool {
+	for _, env := range general.NonProdEnvs {
+		if aa.Environtment == env {
+			return true
+		}
+	}
+
+	return false
+}
+
 type RouteAccount struct {
-	Methods []string    `json:",omitempty"`
-	Headers []string    `json:",omitempty"`
-	Origins RouteOrigin `json:",omitempty"`
+	Methods        []string     `json:",omitempty"`
+	Headers        []string     `json:",omitempty"`
+	Origins        RouteOrigin  `json:",omitempty"`
+	TrustedProxies []*net.IPNet `json:"-"`          // load balancer network, the forwarded header is only read from it
+	MaxBodySize    int64        `json:",omitempty"` // in bytes
+	MaxUploadSize  int64        `json:",omitempty"` // in bytes, for multipart request
+	MaxPageLimit   int          `json:",omitempty"`
+	MaxGetAllLimit int          `json:",omitempty"`
 }
 
 type RouteOrigin struct {
@@ -65,22 +88,133 @@ type RedisAccount struct {
 	Timeout      string `json:",omitempty"`
 }
 
+type RateLimitAccount struct {
+	Backend   string        `json:",omitempty"` // memory or redis
+	OTPSend   RateLimitRule `json:",omitempty"`
+	OTPVerify RateLimitRule `json:",omitempty"`
+}
+
+type CacheAccount struct {
+	Backend    string         `json:",omitempty"` // memory or redis
+	MaxEntries int            `json:",omitempty"` // max entry of the memory backend
+	DefaultTTL int            `json:",omitempty"` // in seconds
+	TTL        map[string]int `json:",omitempty"` // in seconds per master data, e.g. country or sub_district
+}
+
+// GetTTL return the ttl of the master data, or the default ttl when not set.
+func (ca CacheAccount) GetTTL(name string) time.Duration {
+	if ttl, ok := ca.TTL[name]; ok && ttl > 0 {
+		return time.Duration(ttl) * time.Second
+	}
+
+	return time.Duration(ca.DefaultTTL) * time.Second
+}
+
+type RateLimitRule struct {
+	Capacity       int `json:",omitempty"` // max burst request
+	RefillInterval int `json:",omitempty"` // in seconds, time to get one token back
+}
+
+func (rr RateLimitRule) Interval() time.Duration {
+	return time.Duration(rr.RefillInterval) * time.Second
+}
+
 type AuthAccount struct {
-	JWT    JWTCredential    `json:",omitempty"`
-	Public PublicCredential `json:",omitempty"`
+	JWT      JWTCredential      `json:",omitempty"`
+	Public   PublicCredential   `json:",omitempty"`
+	OTP      OTPCredential      `json:",omitempty"`
+	Phone    PhoneCredential    `json:",omitempty"`
+	Password PasswordCredential `json:",omitempty"`
+	MFA      MFACredential      `json:",omitempty"`
+	OIDC     OIDCCredential     `json:",omitempty"`
+}
+
+type MFACredential struct {
+	Issuer          string `json:",omitempty"` // shown on the authenticator app
+	SecretKey       string `json:",omitempty"` // AES key to encrypt the TOTP secret, 16, 24 or 32 bytes
+	PendingDuration int    `json:",omitempty"` // in minutes, lifetime of the mfa_pending token
+}
+
+// OIDCCredential is the company identity provider used by the internal staff to login.
+type OIDCCredential struct {
+	IsActive       bool                `json:",omitempty"`
+	IssuerURL      string              `json:",omitempty"` // discovery is loaded from {IssuerURL}/.well-known/openid-configuration
+	ClientID       string              `json:",omitempty"`
+	ClientSecret   string              `json:",omitempty"`
+	RedirectURL    string              `json:",omitempty"` // page of the internal tools that receive the code & state
+	Scopes         []string            `json:",omitempty"`
+	AllowedDomains []string            `json:",omitempty"` // email domain that allowed to login
+	GroupClaim     string              `json:",omitempty"` // claim of the ID token that contains the group list
+	GroupRoles     map[string][]string `json:",omitempty"` // group (lowercase) to role name
+	StateDuration  int                 `json:",omitempty"` // in minutes, lifetime of the state cookie
+	CookieSecure   bool                `json:",omitempty"`
+}
+
+type PasswordCredential struct {
+	UserTypes []string `json:",omitempty"` // user type that allowed to login with password
+	MinLength int      `json:",omitempty"`
+}
+
+// PhoneCredential is the key to encrypt the user phone. Old key must be kept until
+// every phone that encrypted with it is re-encrypted with the active key.
+type PhoneCredential struct {
+	ActiveKeyVersion string     `json:",omitempty"`
+	FilterPepper     string     `json:",omitempty"` // HMAC key of the phone_filter blind index
+	Keys             []PhoneKey `json:",omitempty"`
+}
+
+type PhoneKey struct {
+	Version string `json:",omitempty" mapstructure:"VERSION"`
+	Key     string `json:",omitempty" mapstructure:"KEY"` // AES key, 16, 24 or 32 bytes
 }
 
 type JWTCredential struct {
-	IsActive              bool   `json:",omitempty"`
-	AccessTokenSecretKey  string `json:",omitempty"`
-	AccessTokenDuration   int    `json:",omitempty"`
-	RefreshTokenSecretKey string `json:",omitempty"`
-	RefreshTokenDuration  int    `json:",omitempty"`
+	IsActive              bool     `json:",omitempty"`
+	SigningMethod         string   `json:",omitempty"` // HMAC, RS256 or EdDSA
+	AccessTokenSecretKey  string   `json:",omitempty"`
+	AccessTokenDuration   int      `json:",omitempty"`
+	RefreshTokenSecretKey string   `json:",omitempty"`
+	RefreshTokenDuration  int      `json:",omitempty"`
+	ActiveKeyID           string   `json:",omitempty"`
+	Keys                  []JWTKey `json:",omitempty"`
+}
+
+// JWTKey is the asymmetric signing key. Retired key only need the public key,
+// keep it until every token that signed with it is expired.
+type JWTKey struct {
+	ID             string `json:",omitempty" mapstructure:"ID"`
+	PrivateKeyPath string `json:",omitempty" mapstructure:"PRIVATE_KEY_PATH"`
+	PublicKeyPath  string `json:",omitempty" mapstructure:"PUBLIC_KEY_PATH"`
 }
 
 type PublicCredential struct {
-	SecretKey string `json:",omitempty"`
+	SecretEncryptionKey string `json:",omitempty"` // AES key to encrypt the client secret, 16, 24 or 32 bytes
+	TimestampTolerance  int    `json:",omitempty"` // in seconds
+	NonceBackend        string `json:",omitempty"` // memory or redis
 }
+
+type OTPCredential struct {
+	Duration     int      `json:",omitempty"` // in minutes
+	MaxAttempt   int      `json:",omitempty"`
+	LockDuration int      `json:",omitempty"` // in minutes, doubled on every lockout
+	Channels     []string `json:",omitempty"` // sender order, next channel is used when the previous one fail
+	LogCode      bool     `json:",omitempty"` // log sender write the code, only for debugging
+}
+
+// LockDurationOf return the lock duration of the lockCount-th lockout, doubled on every lockout up to one day.
+func (oc OTPCredential) LockDurationOf(lockCount int) time.Duration {
+	lockDuration := time.Duration(oc.LockDuration) * time.Minute
+	for i := 0; i < lockCount && lockDuration < general.Time1Day; i++ {
+		lockDuration *= 2
+	}
+
+	if lockDuration > general.Time1Day {
+		lockDuration = general.Time1Day
+	}
+
+	return lockDuration
+}
+
 type ToggleAccount struct {
 	IsUseJWT bool `json:",omitempty"`
 }
@@ -97,11 +231,11 @@ type S3CredentialKey struct {
 }
 
 type MinioSecret struct {
-	BucketName string            `json:", omitempty"`
-	Endpoint   string            `json:", omitempty"`
-	Key        string            `json:", omitempty"`
-	Secret     string            `json:", omitempty"`
-	Region     string            `json:", omitempty"`
+	BucketName string            `json:",omitempty"`
+	Endpoint   string            `json:",omitempty"`
+	Key        string            `json:",omitempty"`
+	Secret     string            `json:",omitempty"`
+	Region     string            `json:",omitempty"`
 	TempFolder string            `json:",omitempty"`
 	BaseURL    string            `json:",omitempty"`
 	FileSource map[string]string `json:",omitempty"`
@@ -181,24 +315,28 @@ type MessageBirdCredential struct {
 	FromID         string `json:",omitempty"`
 	LanguageCode   string `json:",omitempty"`
 	Type           string `json:",omitempty"`
+	SMSURL         string `json:",omitempty"`
+	SMSOriginator  string `json:",omitempty"`
+	SMSTemplate    string `json:",omitempty"`
 }
 
 type KeyAccount struct {
 	User string `json:",omitempty"`
 }
 
-type WhitelistAccount struct {
-	Region WhitelistRegionData `json:",omitempty"`
-	User   WhitelistUserData   `json:",omitempty"`
+// PrivacyAccount is the setting of the user data export & account deletion.
+type PrivacyAccount struct {
+	ExportFolder        string `json:",omitempty"`
+	ExportLinkDuration  int    `json:",omitempty"` // in minutes, the export is deleted after it
+	DeletionGracePeriod int    `json:",omitempty"` // in days
+	JobMaxAttempt       int    `json:",omitempty"`
+	JobTimeout          int    `json:",omitempty"` // in minutes, the running job is claimed again after it
 }
 
-type WhitelistRegionData struct {
-	City map[int64]int64 `json:",omitempty"`
-}
-
-type WhitelistUserData struct {
-	Phone map[string]string `json:",omitempty"`
-	OTP   string            `json:",omitempty"`
+// WhitelistAccount is the setting of the OTP whitelist, the entry itself is stored on the database.
+type WhitelistAccount struct {
+	AllowOnProduction bool `json:",omitempty"` // OTP bypass is refused on production unless enabled
+	CacheDuration     int  `json:",omitempty"` // in seconds
 }
 
 func IsAllowImageType(imageType string) bool {
@@ -1,22 +1,99 @@
 package general
 
-import "gopkg.in/guregu/null.v4"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 type PaginationData struct {
-	Offset    int         `json:"-"`
-	Page      int         `json:"page"`
-	Limit     int         `json:"limit"`
-	Sort      string      `json:"sort"`
-	OrderBy   null.String `json:"order_by"`
-	TotalPage int         `json:"total_page"`
-	TotalData int         `json:"total_data"`
-	IsGetAll  bool        `json:"is_get_all"`
+	Offset    int        `json:"-"`
+	Page      int        `json:"page"`
+	Limit     int        `json:"limit"`
+	Sort      string     `json:"sort"`
+	Sorts     []SortData `json:"-"`
+	TotalPage int        `json:"total_page"`
+	TotalData int        `json:"total_data"`
+	IsGetAll  bool       `json:"is_get_all"`
 }
 
 func (pd *PaginationData) SetOffset() {
 	pd.Offset = (pd.Page - 1) * pd.Limit
 }
 
+// ClampLimit limit the page size requested by the caller, so the list is never a full scan of the table.
+// Get all is turned into the first page of maxGetAll, the total page tells the caller there is more data.
+func (pd *PaginationData) ClampLimit(maxLimit, maxGetAll int) {
+	if pd.IsGetAll {
+		pd.IsGetAll = false
+		pd.Page = 1
+		pd.Limit = maxGetAll
+		return
+	}
+
+	if pd.Limit > maxLimit {
+		pd.Limit = maxLimit
+	}
+}
+
+// SortData is one of the sort field of a list, the field is checked against the sortable column on the repo.
+type SortData struct {
+	Field string
+	Desc  bool
+}
+
+// Sort direction of the sort value.
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// SetSort parse the sort value, the format is field[:asc|desc] separated by comma, e.g. name:asc,id:desc.
+// The direction is asc when it is not set. Sort is replaced by the normalized value.
+func (pd *PaginationData) SetSort(value string) error {
+	var sorts []SortData
+	var normalized []string
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return SortError{Field: value}
+		}
+
+		field, direction := part, SortAsc
+		if i := strings.Index(part, ":"); i >= 0 {
+			field, direction = strings.TrimSpace(part[:i]), strings.ToLower(strings.TrimSpace(part[i+1:]))
+		}
+
+		if field == "" || (direction != SortAsc && direction != SortDesc) {
+			return SortError{Field: part}
+		}
+
+		sorts = append(sorts, SortData{Field: field, Desc: direction == SortDesc})
+		normalized = append(normalized, field+":"+direction)
+	}
+
+	pd.Sorts = sorts
+	pd.Sort = strings.Join(normalized, ",")
+
+	return nil
+}
+
+// SortError is returned when the sort value can not be parsed or the field is not sortable.
+type SortError struct {
+	Field string
+}
+
+func (se SortError) Error() string {
+	return fmt.Sprintf("sort field %s is not valid", se.Field)
+}
+
+// IsSortError check whether the error is caused by the requested sort.
+func IsSortError(err error) bool {
+	var se SortError
+	return errors.As(err, &se)
+}
+
 type TotalData struct {
 	Total int `db:"count"`
 }
@@ -26,7 +103,6 @@ func GetPagination() PaginationData {
 		Page:   1,
 		Offset: 0,
 		Limit:  10,
-		Sort:   "asc",
 	}
 }
 
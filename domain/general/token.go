@@ -11,4 +11,20 @@ type JWTAccess struct {
 	RenewToken         string      `json:"renew"`
 	RenewTokenExpired  string      `json:"renew_expired"`
 	Address            interface{} `json:"address"`
+	MFAPending         bool        `json:"mfa_pending,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
 }
@@ -0,0 +1,36 @@
+package master
+
+// LocationImportRow is a row of the official region code CSV.
+type LocationImportRow struct {
+	Line       int
+	Code       string
+	ParentCode string
+	Name       string
+}
+
+type LocationImportChange struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	OldName    string `json:"old_name,omitempty"`
+	ParentCode string `json:"parent_code,omitempty"`
+}
+
+// LocationImportLevelReport is the diff of a level, the existing data without code is linked by the name on the same parent.
+// Cascaded is the active data that deactivated because the parent is removed.
+type LocationImportLevelReport struct {
+	Level       string                 `json:"level"`
+	Total       int                    `json:"total"`
+	Added       []LocationImportChange `json:"added"`
+	Linked      []LocationImportChange `json:"linked"`
+	Renamed     []LocationImportChange `json:"renamed"`
+	Moved       []LocationImportChange `json:"moved"`
+	Reactivated []LocationImportChange `json:"reactivated"`
+	Removed     []LocationImportChange `json:"removed"`
+	Cascaded    []LocationImportChange `json:"cascaded"`
+	Unchanged   int                    `json:"unchanged"`
+}
+
+type LocationImportReport struct {
+	DryRun bool                        `json:"dry_run"`
+	Levels []LocationImportLevelReport `json:"levels"`
+}
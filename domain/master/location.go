@@ -1,38 +1,63 @@
 package master
 
 import (
+	"encoding/json"
+
 	"gopkg.in/guregu/null.v4"
 )
 
 type Country struct {
-	ID   int64  `json:"id" db:"country_id"`
-	Name string `json:"name" db:"name"`
+	ID       int64       `json:"id" db:"country_id"`
+	Name     string      `json:"name" db:"name"`
+	Code     null.String `json:"code" db:"code"`
+	IsActive bool        `json:"is_active" db:"is_active"`
 }
 
 type CountryFilter struct {
-	Name null.String
+	Name     null.String
+	IsActive null.Bool
+}
+
+type CountryRequest struct {
+	Name string `json:"name" validate:"empty=false"`
 }
 
 type Province struct {
-	ID        int64  `json:"id" db:"province_id"`
-	CountryID int64  `json:"country_id" db:"country_id"`
-	Name      string `json:"name" db:"name"`
+	ID        int64       `json:"id" db:"province_id"`
+	CountryID int64       `json:"country_id" db:"country_id"`
+	Name      string      `json:"name" db:"name"`
+	Code      null.String `json:"code" db:"code"`
+	IsActive  bool        `json:"is_active" db:"is_active"`
 }
 
 type ProvinceFilter struct {
 	CountryID null.Int
 	Name      null.String
+	IsActive  null.Bool
+}
+
+type ProvinceRequest struct {
+	CountryID int64  `json:"country_id" validate:"gte=1"`
+	Name      string `json:"name" validate:"empty=false"`
 }
 
 type City struct {
-	ID         int64  `json:"id" db:"city_id"`
-	ProvinceID int64  `json:"province_id" db:"province_id"`
-	Name       string `json:"name" db:"name"`
+	ID         int64       `json:"id" db:"city_id"`
+	ProvinceID int64       `json:"province_id" db:"province_id"`
+	Name       string      `json:"name" db:"name"`
+	Code       null.String `json:"code" db:"code"`
+	IsActive   bool        `json:"is_active" db:"is_active"`
 }
 
 type CityFilter struct {
 	ProvinceID null.Int
 	Name       null.String
+	IsActive   null.Bool
+}
+
+type CityRequest struct {
+	ProvinceID int64  `json:"province_id" validate:"gte=1"`
+	Name       string `json:"name" validate:"empty=false"`
 }
 
 type District struct {
@@ -40,6 +65,8 @@ type District struct {
 	CityID   int64       `json:"city_id" db:"city_id"`
 	Name     string      `json:"name" db:"name"`
 	Metadata null.String `json:"metadata" db:"metadata"`
+	Code     null.String `json:"code" db:"code"`
+	IsActive bool        `json:"is_active" db:"is_active"`
 }
 
 type DistrictMetadata struct {
@@ -48,26 +75,69 @@ type DistrictMetadata struct {
 }
 
 type DistrictJNEMetadata struct {
-	Origin      string `json:"origin"`
-	Destination string `json:"destination"`
+	Origin      string `json:"origin" validate:"empty=false"`
+	Destination string `json:"destination" validate:"empty=false"`
 }
 
 type DistrictJETMetadata struct {
-	Code string `json:"code"`
+	Code string `json:"code" validate:"empty=false"`
 }
 
 type DistrictFilter struct {
-	CityID null.Int
-	Name   null.String
+	CityID   null.Int
+	Name     null.String
+	IsActive null.Bool
+}
+
+// DistrictRequest metadata must follow the DistrictMetadata.
+type DistrictRequest struct {
+	CityID   int64           `json:"city_id" validate:"gte=1"`
+	Name     string          `json:"name" validate:"empty=false"`
+	Metadata json.RawMessage `json:"metadata"`
 }
 
 type SubDistrict struct {
-	ID         int64  `json:"id" db:"sub_district_id"`
-	DistrictID int64  `json:"district_id" db:"district_id"`
-	Name       string `json:"name" db:"name"`
+	ID         int64       `json:"id" db:"sub_district_id"`
+	DistrictID int64       `json:"district_id" db:"district_id"`
+	Name       string      `json:"name" db:"name"`
+	Code       null.String `json:"code" db:"code"`
+	IsActive   bool        `json:"is_active" db:"is_active"`
 }
 
 type SubDistrictFilter struct {
 	DistrictID null.Int
 	Name       null.String
+	IsActive   null.Bool
+}
+
+type SubDistrictRequest struct {
+	DistrictID int64  `json:"district_id" validate:"gte=1"`
+	Name       string `json:"name" validate:"empty=false"`
+}
+
+// LocationPath is the full ancestor of a sub district, selected in one query.
+type LocationPath struct {
+	CountryID       int64  `db:"country_id"`
+	CountryName     string `db:"country_name"`
+	ProvinceID      int64  `db:"province_id"`
+	ProvinceName    string `db:"province_name"`
+	CityID          int64  `db:"city_id"`
+	CityName        string `db:"city_name"`
+	DistrictID      int64  `db:"district_id"`
+	DistrictName    string `db:"district_name"`
+	SubDistrictID   int64  `db:"sub_district_id"`
+	SubDistrictName string `db:"sub_district_name"`
+}
+
+type LocationNode struct {
+	ID       int64          `json:"id"`
+	Name     string         `json:"name"`
+	Level    string         `json:"level"`
+	Children []LocationNode `json:"children,omitempty"`
+}
+
+type LocationTreeFilter struct {
+	Level string
+	ID    null.Int
+	Depth int
 }
@@ -2,10 +2,13 @@ package order
 
 import (
 	"time"
+
+	"gopkg.in/guregu/null.v4"
 )
 
 type Order struct {
 	OrderID      int64     `json:"orderId" gorm:"primaryKey;autoIncrement" db:"order_id"`
+	UserID       null.Int  `json:"userId" db:"user_id"`
 	CustomerName string    `json:"customerName" db:"customer_name"`
 	OrderedAt    time.Time `json:"orderedAt" db:"ordered_at"`
 	Items        []Item    `json:"items" gorm:"foreignKey:OrderID;references:OrderID;"`
@@ -17,3 +20,8 @@ type OrderRequest struct {
 	OrderedAt    string `json:"orderedAt"`
 	Items        []Item `json:"items"`
 }
+
+type OrderFilter struct {
+	UserID       null.Int
+	CustomerName null.String
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// UserJob is the background job requested by the user, processed by cmd/job.
+type UserJob struct {
+	ID         int64       `json:"id" db:"user_job_id"`
+	UserID     int64       `json:"user_id" db:"user_id"`
+	JobType    string      `json:"job_type" db:"job_type"`
+	Status     string      `json:"status" db:"status"`
+	RunAt      time.Time   `json:"run_at" db:"run_at"`
+	Result     null.String `json:"result" db:"result"`
+	Error      null.String `json:"error" db:"error"`
+	Attempt    int         `json:"attempt" db:"attempt"`
+	FinishedAt *time.Time  `json:"finished_at" db:"finished_at"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt  *time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type UserJobResponse struct {
+	ID           int64      `json:"id"`
+	JobType      string     `json:"job_type"`
+	Status       string     `json:"status"`
+	RunAt        time.Time  `json:"run_at"`
+	DownloadURL  string     `json:"download_url,omitempty"`
+	URLExpiredAt *time.Time `json:"url_expired_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// AnonymizeUser replace the PII of the deleted user, phone & phone filter is kept unique.
+type AnonymizeUser struct {
+	UserID      int64
+	Name        string
+	Phone       string
+	PhoneFilter string
+}
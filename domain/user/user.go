@@ -7,16 +7,24 @@ import (
 )
 
 type User struct {
-	ID           int64       `json:"id" db:"user_id"`
-	Name         string      `json:"name" db:"name"`
-	Status       int         `json:"status" db:"status"`
-	Phone        string      `json:"phone" db:"phone"`
-	PhoneFilter  string      `json:"phone_filter" db:"phone_filter"`
-	OTP          null.String `json:"otp" db:"otp"`
-	OTPCreatedAt *time.Time  `json:"otp_created_at" db:"otp_created_at"`
-	CreatedAt    time.Time   `json:"-" db:"created_at"`
-	UpdatedAt    *time.Time  `json:"-" db:"updated_at"`
-	UpdatedBy    *int64      `json:"-" db:"updated_by"`
+	ID             int64       `json:"id" db:"user_id"`
+	Name           string      `json:"name" db:"name"`
+	Email          null.String `json:"email" db:"email"`
+	Status         int         `json:"status" db:"status"`
+	StatusReason   null.String `json:"status_reason" db:"status_reason"`
+	Phone          string      `json:"phone" db:"phone"`
+	PhoneFilter    string      `json:"phone_filter" db:"phone_filter"`
+	UserType       string      `json:"user_type" db:"user_type"`
+	Password       null.String `json:"-" db:"password"`
+	OTP            null.String `json:"otp" db:"otp"`
+	OTPCreatedAt   *time.Time  `json:"otp_created_at" db:"otp_created_at"`
+	OTPWhitelistID null.Int    `json:"-" db:"otp_whitelist_id"` // set when the OTP is the fixed OTP of the whitelist
+	OTPAttempt     int         `json:"-" db:"otp_attempt"`
+	OTPLockCount   int         `json:"-" db:"otp_lock_count"`
+	OTPLockedUntil *time.Time  `json:"-" db:"otp_locked_until"`
+	CreatedAt      time.Time   `json:"-" db:"created_at"`
+	UpdatedAt      *time.Time  `json:"-" db:"updated_at"`
+	UpdatedBy      *int64      `json:"-" db:"updated_by"`
 }
 
 type CreateUser struct {
@@ -34,6 +42,27 @@ type VerifyUser struct {
 	OTP         string
 }
 
+type UpdateStatus struct {
+	UserID    int64
+	Status    int
+	Reason    string
+	UpdatedBy int64
+}
+
+type UpdatePhone struct {
+	UserID      int64
+	Phone       string
+	PhoneFilter string
+	UpdatedBy   int64
+}
+
+type OTPAttempt struct {
+	UserID      int64
+	Attempt     int `db:"otp_attempt"`
+	LockCount   int `db:"otp_lock_count"`
+	LockedUntil *time.Time
+}
+
 type CreateUserRequest struct {
 	Name  string `json:"name" validate:"empty=false"`
 	Phone string `json:"phone" validate:"empty=false"`
@@ -50,9 +79,97 @@ type UserLoginRequest struct {
 	Phone string `json:"phone" validate:"empty=false"`
 }
 
+type PasswordLoginRequest struct {
+	Phone    string `json:"phone" validate:"empty=false"`
+	Password string `json:"password" validate:"empty=false"`
+}
+
+type ForgotPasswordRequest struct {
+	Phone string `json:"phone" validate:"empty=false"`
+}
+
+type ResetPasswordRequest struct {
+	Phone       string `json:"phone" validate:"empty=false"`
+	OTP         string `json:"otp" validate:"empty=false"`
+	NewPassword string `json:"new_password" validate:"empty=false"`
+}
+
 type UserDetailResponse struct {
-	ID     int64  `json:"id"`
-	Name   string `json:"name"`
+	ID     int64       `json:"id"`
+	Name   string      `json:"name"`
+	Email  null.String `json:"email"`
+	Status int         `json:"status"`
+	Phone  string      `json:"phone"`
+}
+
+type UserFilter struct {
+	Name        null.String
+	Status      null.Int
+	CreatedFrom null.Time
+	CreatedTo   null.Time
+}
+
+type UpdateStatusRequest struct {
 	Status int    `json:"status"`
-	Phone  string `json:"phone"`
+	Reason string `json:"reason" validate:"empty=false"`
+}
+
+// UserAdminResponse is the user data shown to the admin, OTP & lock data is never exposed.
+type UserAdminResponse struct {
+	ID             int64       `json:"id"`
+	Name           string      `json:"name"`
+	Email          null.String `json:"email"`
+	Phone          string      `json:"phone"`
+	Status         int         `json:"status"`
+	StatusReason   null.String `json:"status_reason"`
+	OTPLockedUntil *time.Time  `json:"otp_locked_until"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      *time.Time  `json:"updated_at"`
+	UpdatedBy      *int64      `json:"updated_by"`
+}
+
+type UpdateProfileRequest struct {
+	Name  string `json:"name" validate:"empty=false"`
+	Email string `json:"email"`
+}
+
+type ChangePhoneRequest struct {
+	Phone string `json:"phone" validate:"empty=false"`
+}
+
+type VerifyPhoneChangeRequest struct {
+	OTP string `json:"otp" validate:"empty=false"`
+}
+
+// PhoneChange is the pending new phone of the user, the phone is only swapped
+// after the OTP that sent to the new phone is verified.
+type PhoneChange struct {
+	ID             int64      `json:"id" db:"phone_change_id"`
+	UserID         int64      `json:"user_id" db:"user_id"`
+	NewPhone       string     `json:"new_phone" db:"new_phone"`
+	NewPhoneFilter string     `json:"-" db:"new_phone_filter"`
+	OTP            string     `json:"-" db:"otp"`
+	OTPAttempt     int        `json:"-" db:"otp_attempt"`
+	ExpiredAt      time.Time  `json:"expired_at" db:"expired_at"`
+	VerifiedAt     *time.Time `json:"verified_at" db:"verified_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+type OTPDelivery struct {
+	ID        int64     `json:"id" db:"otp_delivery_id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Channel   string    `json:"channel" db:"channel"`
+	Status    string    `json:"status" db:"status"`
+	Response  string    `json:"response" db:"response"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PhoneMigrationReport is the result of re-normalizing the phone of existing user.
+type PhoneMigrationReport struct {
+	Total     int     `json:"total"`
+	Updated   int     `json:"updated"`
+	Unchanged int     `json:"unchanged"`
+	Invalid   []int64 `json:"invalid"`
+	Conflict  []int64 `json:"conflict"`
+	Failed    []int64 `json:"failed"`
 }
@@ -0,0 +1,33 @@
+package user
+
+import "time"
+
+// OTPWhitelist is the test phone number that login with fixed OTP, the OTP is never sent.
+type OTPWhitelist struct {
+	ID          int64     `json:"id" db:"otp_whitelist_id"`
+	Phone       string    `json:"phone" db:"phone"`
+	PhoneFilter string    `json:"-" db:"phone_filter"`
+	OTP         string    `json:"otp" db:"otp"`
+	Reason      string    `json:"reason" db:"reason"`
+	ExpiredAt   time.Time `json:"expired_at" db:"expired_at"`
+	CreatedBy   int64     `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type OTPWhitelistRequest struct {
+	Phone     string    `json:"phone" validate:"empty=false"`
+	OTP       string    `json:"otp" validate:"empty=false"`
+	Reason    string    `json:"reason" validate:"empty=false"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type OTPWhitelistResponse struct {
+	ID        int64     `json:"id"`
+	Phone     string    `json:"phone"`
+	OTP       string    `json:"otp"`
+	Reason    string    `json:"reason"`
+	ExpiredAt time.Time `json:"expired_at"`
+	IsExpired bool      `json:"is_expired"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
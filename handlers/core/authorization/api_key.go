@@ -0,0 +1,195 @@
+package authorization
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	dg "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	ua "github.com/furee/backend/usecase/authorization"
+	"github.com/furee/backend/utils"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/dealancer/validate.v2"
+)
+
+const apiKeyScheme = "ApiKey "
+
+type APIKeyHandler struct {
+	Usecase ua.APIKeyUsecaseItf
+	log     *logrus.Logger
+	Conf    *dg.SectionService
+}
+
+func NewAPIKeyHandler(uc usecase.Usecase, conf *dg.SectionService, logger *logrus.Logger) APIKeyHandler {
+	return APIKeyHandler{
+		Usecase: uc.Authorization.APIKey,
+		log:     logger,
+		Conf:    conf,
+	}
+}
+
+// Validator authenticate the "Authorization: ApiKey <key>" header, the request with other scheme is passed to the JWTValidator.
+// The API key is only accepted on the route guarded by RequirePermission, the scope of the key is checked there.
+func (ah APIKeyHandler) Validator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		respData := handlers.ResponseData{
+			Status: cg.Fail,
+		}
+
+		authorizationHeader := req.Header.Get("Authorization")
+		if !strings.HasPrefix(authorizationHeader, apiKeyScheme) {
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		route := mux.CurrentRoute(req)
+		if route == nil {
+			respData.Message = "API key is not allowed on this endpoint"
+			handlers.WriteResponse(res, respData, http.StatusForbidden)
+			return
+		}
+
+		if _, ok := route.GetHandler().(permissionHandler); !ok {
+			respData.Message = "API key is not allowed on this endpoint"
+			handlers.WriteResponse(res, respData, http.StatusForbidden)
+			return
+		}
+
+		key, message, err := ah.Usecase.Authenticate(req.Context(), strings.TrimSpace(strings.TrimPrefix(authorizationHeader, apiKeyScheme)), handlers.GetClientIP(req, ah.Conf.Route.TrustedProxies))
+		if err != nil {
+			code := http.StatusUnauthorized
+			if message == cg.HandlerErrorPermissionDenied {
+				code = http.StatusForbidden
+			}
+
+			if message == "" {
+				message = "fail to check api key"
+				code = http.StatusInternalServerError
+			}
+
+			respData.Message = message
+			handlers.WriteResponse(res, respData, code)
+			return
+		}
+
+		req = req.WithContext(da.WithAPIKey(req.Context(), key))
+
+		next.ServeHTTP(res, req)
+	})
+}
+
+func (ah APIKeyHandler) GetListAPIKey(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	keys, message, err := ah.Usecase.GetListAPIKey(req.Context())
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to get api key"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status: cg.Success,
+		Detail: keys,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+// CreateAPIKey return the plain key only once, the key cannot be shown again after this.
+func (ah APIKeyHandler) CreateAPIKey(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	var param da.APIKeyRequest
+
+	reqBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataEmpty
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = json.Unmarshal(reqBody, &param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataNotValid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = validate.Validate(param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	key, message, err := ah.Usecase.CreateAPIKey(req.Context(), param)
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to create api key"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  key,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (ah APIKeyHandler) RevokeAPIKey(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	apiKeyID, err := utils.StrToInt64(mux.Vars(req)["apikeyid"])
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	message, err := ah.Usecase.RevokeAPIKey(req.Context(), apiKeyID)
+	if err != nil {
+		code := http.StatusNotFound
+		if message == "" {
+			message = "fail to revoke api key"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
@@ -0,0 +1,144 @@
+package authorization
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	dg "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	ua "github.com/furee/backend/usecase/authorization"
+	"github.com/furee/backend/utils"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+)
+
+type AuthEventHandler struct {
+	Usecase ua.AuthEventUsecaseItf
+	log     *logrus.Logger
+	Conf    *dg.SectionService
+}
+
+func NewAuthEventHandler(uc usecase.Usecase, conf *dg.SectionService, logger *logrus.Logger) AuthEventHandler {
+	return AuthEventHandler{
+		Usecase: uc.Authorization.AuthEvent,
+		log:     logger,
+		Conf:    conf,
+	}
+}
+
+// GetListAuthEvent return the auth event for the security investigation, the newest event first.
+func (ah AuthEventHandler) GetListAuthEvent(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	var filter da.AuthEventFilter
+	var err error
+
+	paginationData := dg.GetPagination()
+
+	// Check user id value
+	if req.FormValue("user-id") != "" {
+		userID, err := utils.StrToInt64(req.FormValue("user-id"))
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		filter.UserID = null.IntFrom(userID)
+	}
+
+	if req.FormValue("event-type") != "" {
+		filter.EventType = null.StringFrom(req.FormValue("event-type"))
+	}
+
+	if req.FormValue("phone") != "" {
+		filter.Phone = null.StringFrom(req.FormValue("phone"))
+	}
+
+	if req.FormValue("ip-address") != "" {
+		filter.IPAddress = null.StringFrom(req.FormValue("ip-address"))
+	}
+
+	if req.FormValue("request-id") != "" {
+		filter.RequestID = null.StringFrom(req.FormValue("request-id"))
+	}
+
+	// Check created range value, format is RFC3339
+	if req.FormValue("created-from") != "" {
+		createdFrom, err := time.Parse(time.RFC3339, req.FormValue("created-from"))
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		filter.CreatedFrom = null.TimeFrom(createdFrom.UTC())
+	}
+
+	if req.FormValue("created-to") != "" {
+		createdTo, err := time.Parse(time.RFC3339, req.FormValue("created-to"))
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		filter.CreatedTo = null.TimeFrom(createdTo.UTC())
+	}
+
+	// Check page value. If exist, convert to int
+	if req.FormValue("page") != "" {
+		paginationData.Page, err = strconv.Atoi(req.FormValue("page"))
+		if err != nil || paginationData.Page < 1 {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Check limit value. If exists, convert to int
+	if req.FormValue("limit") != "" {
+		paginationData.Limit, err = strconv.Atoi(req.FormValue("limit"))
+		if err != nil || paginationData.Limit < 1 {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Limit the page size, get all is limited as well
+	paginationData.ClampLimit(ah.Conf.Route.MaxPageLimit, ah.Conf.Route.MaxGetAllLimit)
+
+	// Convert page to offset
+	paginationData.SetOffset()
+
+	data, paginationData, message, err := ah.Usecase.GetListAuthEvent(req.Context(), paginationData, filter)
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to get list auth event"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: "success get list auth event",
+		Detail: dg.ResponseData{
+			Data:       data,
+			Pagination: paginationData,
+		},
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
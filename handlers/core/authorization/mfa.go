@@ -0,0 +1,187 @@
+package authorization
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	dg "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	ua "github.com/furee/backend/usecase/authorization"
+	"github.com/furee/backend/utils"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/dealancer/validate.v2"
+)
+
+type MFAHandler struct {
+	Usecase ua.MFAUsecaseItf
+	log     *logrus.Logger
+	Conf    *dg.SectionService
+}
+
+func NewMFAHandler(uc usecase.Usecase, conf *dg.SectionService, logger *logrus.Logger) MFAHandler {
+	return MFAHandler{
+		Usecase: uc.Authorization.MFA,
+		log:     logger,
+		Conf:    conf,
+	}
+}
+
+func (mh MFAHandler) Enroll(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	enrollment, message, err := mh.Usecase.Enroll(req.Context())
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to enroll MFA"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  enrollment,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (mh MFAHandler) Activate(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	var param da.MFACodeRequest
+
+	reqBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataEmpty
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = json.Unmarshal(reqBody, &param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataNotValid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = validate.Validate(param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	codes, message, err := mh.Usecase.Activate(req.Context(), param)
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to activate MFA"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  codes,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+// Verify is the second step of the login, called with the mfa_pending token.
+func (mh MFAHandler) Verify(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	var param da.MFAVerifyRequest
+
+	reqBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataEmpty
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = json.Unmarshal(reqBody, &param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataNotValid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	jwt, message, err := mh.Usecase.Verify(req.Context(), param)
+	if err != nil {
+		code := http.StatusUnauthorized
+		if message == "" {
+			message = "fail to verify MFA"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  jwt,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+// Reset remove the MFA of the user, used by admin.
+func (mh MFAHandler) Reset(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	userID, err := utils.StrToInt64(mux.Vars(req)["userid"])
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	message, err := mh.Usecase.Reset(req.Context(), userID)
+	if err != nil {
+		code := http.StatusNotFound
+		if message == "" {
+			message = "fail to reset MFA"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
@@ -0,0 +1,135 @@
+package authorization
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	dg "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	ua "github.com/furee/backend/usecase/authorization"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/dealancer/validate.v2"
+)
+
+// oidcStateCookie keep the encrypted state, nonce & code verifier between the login & the callback.
+const oidcStateCookie = "oidc_state"
+
+type OIDCHandler struct {
+	Usecase ua.OIDCUsecaseItf
+	log     *logrus.Logger
+	Conf    *dg.SectionService
+}
+
+func NewOIDCHandler(uc usecase.Usecase, conf *dg.SectionService, logger *logrus.Logger) OIDCHandler {
+	return OIDCHandler{
+		Usecase: uc.Authorization.OIDC,
+		log:     logger,
+		Conf:    conf,
+	}
+}
+
+// Login start the login of the internal staff, the internal tools redirect the staff to the returned URL.
+func (oh OIDCHandler) Login(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	login, state, message, err := oh.Usecase.StartLogin(req.Context())
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to start login"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	oh.setStateCookie(res, state, int(time.Until(login.ExpiredAt).Seconds()))
+
+	respData = &handlers.ResponseData{
+		Status: cg.Success,
+		Detail: login,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+// Callback finish the login with the code & state that sent by the identity provider to the internal tools.
+func (oh OIDCHandler) Callback(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	var param da.OIDCCallbackRequest
+
+	reqBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataEmpty
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = json.Unmarshal(reqBody, &param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataNotValid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = validate.Validate(param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	var state string
+	if cookie, err := req.Cookie(oidcStateCookie); err == nil {
+		state = cookie.Value
+	}
+
+	// The state can only be used once.
+	oh.setStateCookie(res, "", -1)
+
+	jwt, message, err := oh.Usecase.Callback(req.Context(), param, state)
+	if err != nil {
+		code := http.StatusUnauthorized
+		if message == "" {
+			message = "fail to login"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  jwt,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (oh OIDCHandler) setStateCookie(res http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(res, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     oh.Conf.App.Endpoint + "/login/oidc",
+		MaxAge:   maxAge,
+		Expires:  time.Now().Add(time.Duration(maxAge) * time.Second),
+		HttpOnly: true,
+		Secure:   oh.Conf.Authorization.OIDC.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
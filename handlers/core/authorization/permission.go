@@ -0,0 +1,58 @@
+package authorization
+
+import (
+	"net/http"
+
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/handlers"
+	"github.com/sirupsen/logrus"
+)
+
+// permissionHandler is a named type so the APIKeyHandler can tell which route is guarded by a permission.
+type permissionHandler struct {
+	permission string
+	next       http.HandlerFunc
+	log        *logrus.Logger
+}
+
+// RequirePermission only allow the request when the token has the permission,
+// or when the API key has the permission on the scope. API key is never allowed on the admin permission.
+// Must be used on the route that already validated by JWTValidator.
+func (th TokenHandler) RequirePermission(permission string, next http.HandlerFunc) http.Handler {
+	return permissionHandler{
+		permission: permission,
+		next:       next,
+		log:        th.log,
+	}
+}
+
+func (ph permissionHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if key, ok := da.CurrentAPIKey(req.Context()); ok {
+		// The key that created before the admin scope is refused still can't reach the admin route.
+		if !da.IsAPIKeyScope(ph.permission) || !key.HasScope(ph.permission) {
+			ph.log.WithField("api key id", key.ID).WithField("permission", ph.permission).WithField("path", req.URL.Path).Warn("RequirePermission | scope denied")
+			ph.deny(res)
+			return
+		}
+
+		ph.next.ServeHTTP(res, req)
+		return
+	}
+
+	session, ok := da.GetSession(req.Context())
+	if !ok || !session.HasPermission(ph.permission) {
+		ph.log.WithField("user id", session.UserID).WithField("permission", ph.permission).WithField("path", req.URL.Path).Warn("RequirePermission | permission denied")
+		ph.deny(res)
+		return
+	}
+
+	ph.next.ServeHTTP(res, req)
+}
+
+func (ph permissionHandler) deny(res http.ResponseWriter) {
+	handlers.WriteResponse(res, handlers.ResponseData{
+		Status:  cg.Fail,
+		Message: cg.HandlerErrorPermissionDenied,
+	}, http.StatusForbidden)
+}
@@ -1,80 +1,163 @@
 package authorization
 
 import (
-	"context"
+	"bytes"
+	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
+	ca "github.com/furee/backend/constants/authorization"
 	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
 	dg "github.com/furee/backend/domain/general"
 	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/usecase"
+	ua "github.com/furee/backend/usecase/authorization"
 	"github.com/furee/backend/utils"
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
 type PublicHandler struct {
-	log  *logrus.Logger
-	Conf *dg.SectionService
+	Usecase   ua.APIClientUsecaseItf
+	AuthEvent ua.AuthEventUsecaseItf
+	log       *logrus.Logger
+	Conf      *dg.SectionService
+	nonce     infra.NonceStore
 }
 
-func NewPublicHandler(conf *dg.SectionService, logger *logrus.Logger) PublicHandler {
+func NewPublicHandler(uc usecase.Usecase, conf *dg.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger) PublicHandler {
 	return PublicHandler{
-		log:  logger,
-		Conf: conf,
+		Usecase:   uc.Authorization.APIClient,
+		AuthEvent: uc.Authorization.AuthEvent,
+		log:       logger,
+		Conf:      conf,
+		nonce:     infra.NewNonceStore(conf.Authorization.Public.NonceBackend, dbList.Redis),
 	}
 }
 
-type Session struct{}
-
+// AuthValidator verify the request signature of the API client.
+// Authorization = hex(HMAC-SHA256(client secret, METHOD\nREQUEST_URI\nX-Timestamp\nX-Nonce\nhex(SHA256(body))))
+// Authorization-ID is the client id, the nonce can only be used once.
 func (ph PublicHandler) AuthValidator(next http.Handler) http.Handler {
+	tolerance := time.Duration(ph.Conf.Authorization.Public.TimestampTolerance) * time.Second
+	if tolerance <= 0 {
+		tolerance = cg.Time1Min
+	}
+
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		respData := handlers.ResponseData{
-			Status: cg.Fail,
+			Status:  cg.Fail,
+			Message: cg.HandlerErrorTokenInvalid,
 		}
 
-		authorization := req.Header.Get("Authorization")
-		authorizationID := req.Header.Get("Authorization-ID")
+		signature := req.Header.Get(cg.APIHeaderAuthorization)
+		clientID := req.Header.Get(cg.APIHeaderAuthorizationID)
+		timestamp := req.Header.Get(cg.APIHeaderTimestamp)
+		nonce := req.Header.Get(cg.APIHeaderNonce)
 
-		if authorization == "" {
-			respData.Message = "Token Not Valid"
-			handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+		if signature == "" || clientID == "" || timestamp == "" || nonce == "" {
+			ph.reject(res, req, respData, http.StatusUnauthorized, clientID, "missing header")
+			return
+		}
+
+		authUnix, err := utils.StrToInt64(timestamp)
+		if err != nil {
+			ph.reject(res, req, respData, http.StatusUnauthorized, clientID, "invalid timestamp")
 			return
 		}
 
-		if authorizationID == "" {
-			respData.Message = "Token Not Valid"
-			handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+		diff := time.Since(time.Unix(authUnix, 0))
+		if diff > tolerance || diff < -tolerance {
+			ph.reject(res, req, respData, http.StatusUnauthorized, clientID, "timestamp out of tolerance")
 			return
 		}
 
-		authUnix, err := utils.StrToInt64(authorizationID)
+		client, message, err := ph.Usecase.GetActiveClient(clientID)
 		if err != nil {
-			respData.Message = "Token Not Valid"
-			handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+			if message == "" {
+				respData.Message = ""
+				handlers.WriteResponse(res, respData, http.StatusInternalServerError)
+				return
+			}
+
+			ph.reject(res, req, respData, http.StatusUnauthorized, clientID, "client not valid")
 			return
 		}
 
-		authTime := time.Unix(authUnix, 0)
-		if time.Now().UTC().Unix() > (authTime.UTC().Add(cg.Time1Min)).Unix() {
-			respData.Message = "Token Not Valid"
-			handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+		pathTemplate := req.URL.Path
+		if route := mux.CurrentRoute(req); route != nil {
+			if tpl, err := route.GetPathTemplate(); err == nil {
+				pathTemplate = tpl
+			}
+		}
+
+		if !client.IsRouteAllowed(req.Method, pathTemplate) {
+			respData.Message = cg.HandlerErrorPermissionDenied
+			ph.reject(res, req, respData, http.StatusForbidden, clientID, "route not allowed")
 			return
 		}
 
-		authCompareByte := sha256.Sum256([]byte(fmt.Sprintf("%s%s", ph.Conf.Authorization.Public.SecretKey, authorizationID)))
-		authCompare := fmt.Sprintf("%x", authCompareByte)
+		// The body is limited by the LimitBody middleware, the error is returned when it is larger.
+		reqBody, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataTooLarge
+			ph.reject(res, req, respData, http.StatusRequestEntityTooLarge, clientID, "invalid body")
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
 
-		if authCompare != authorization {
-			respData.Message = "Token Not Valid"
-			handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+		bodyHash := sha256.Sum256(reqBody)
+		payload := strings.Join([]string{
+			req.Method,
+			req.URL.RequestURI(),
+			timestamp,
+			nonce,
+			fmt.Sprintf("%x", bodyHash),
+		}, "\n")
+
+		mac := hmac.New(sha256.New, []byte(client.Secret))
+		mac.Write([]byte(payload))
+
+		signatureByte, err := hex.DecodeString(signature)
+		if err != nil || !hmac.Equal(signatureByte, mac.Sum(nil)) {
+			ph.reject(res, req, respData, http.StatusUnauthorized, clientID, "invalid signature")
+			return
+		}
+
+		// Nonce is checked after the signature so unsigned request can't burn the client nonce.
+		ok, err := ph.nonce.Use(fmt.Sprintf("%s:%s", clientID, nonce), 2*tolerance)
+		if err != nil {
+			ph.log.WithField("client id", clientID).WithError(err).Error("AuthValidator | fail to store nonce")
+			respData.Message = ""
+			handlers.WriteResponse(res, respData, http.StatusInternalServerError)
 			return
 		}
 
-		ctx := context.WithValue(req.Context(), Session{}, authorization)
-		req = req.WithContext(ctx)
+		if !ok {
+			ph.reject(res, req, respData, http.StatusUnauthorized, clientID, "nonce reused")
+			return
+		}
+
+		client.Secret = ""
+		next.ServeHTTP(res, req.WithContext(da.WithClient(req.Context(), client)))
+	})
+}
 
-		next.ServeHTTP(res, req)
+// reject write the failed response of the public request & record it to the auth event.
+func (ph PublicHandler) reject(res http.ResponseWriter, req *http.Request, respData handlers.ResponseData, code int, clientID, reason string) {
+	ph.AuthEvent.Record(req.Context(), ca.AuthEventPublicAuthFailed, 0, "", map[string]interface{}{
+		"client_id": clientID,
+		"method":    req.Method,
+		"path":      req.URL.Path,
+		"reason":    reason,
 	})
+
+	handlers.WriteResponse(res, respData, code)
 }
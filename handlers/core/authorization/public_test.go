@@ -0,0 +1,221 @@
+package authorization
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	dg "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/infra"
+	"github.com/sirupsen/logrus"
+)
+
+type stubAPIClientUsecase struct {
+	clients map[string]da.APIClient
+}
+
+func (su stubAPIClientUsecase) GetActiveClient(clientID string) (*da.APIClient, string, error) {
+	client, ok := su.clients[clientID]
+	if !ok {
+		return nil, "client not found", errors.New("client not found")
+	}
+
+	return &client, "", nil
+}
+
+func (su stubAPIClientUsecase) CreateClient(name string, allowedRoutes []string) (*da.APIClient, string, error) {
+	return nil, "", errors.New("not implemented")
+}
+
+type stubAuthEventUsecase struct {
+	events *[]string
+}
+
+func (su stubAuthEventUsecase) Record(ctx context.Context, eventType string, userID int64, phoneHash string, detail map[string]interface{}) {
+	*su.events = append(*su.events, fmt.Sprintf("%v", detail["reason"]))
+}
+
+func (su stubAuthEventUsecase) GetListAuthEvent(ctx context.Context, pagination dg.PaginationData, filter da.AuthEventFilter) ([]da.AuthEvent, dg.PaginationData, string, error) {
+	return nil, pagination, "", nil
+}
+
+const (
+	testClientID     = "client-1"
+	testClientSecret = "client-secret"
+	testPublicPath   = "/v1/public/orders"
+)
+
+type signedRequest struct {
+	method    string
+	path      string
+	body      string
+	signBody  string // body used for the signature, the sent body when it is empty
+	clientID  string
+	secret    string
+	timestamp time.Time
+	nonce     string
+}
+
+func (sr signedRequest) build() *http.Request {
+	signBody := sr.signBody
+	if signBody == "" {
+		signBody = sr.body
+	}
+
+	timestamp := fmt.Sprintf("%d", sr.timestamp.Unix())
+	bodyHash := sha256.Sum256([]byte(signBody))
+	payload := strings.Join([]string{sr.method, sr.path, timestamp, sr.nonce, fmt.Sprintf("%x", bodyHash)}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(sr.secret))
+	mac.Write([]byte(payload))
+
+	req := httptest.NewRequest(sr.method, sr.path, strings.NewReader(sr.body))
+	req.Header.Set(cg.APIHeaderAuthorization, hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set(cg.APIHeaderAuthorizationID, sr.clientID)
+	req.Header.Set(cg.APIHeaderTimestamp, timestamp)
+	req.Header.Set(cg.APIHeaderNonce, sr.nonce)
+
+	return req
+}
+
+func newTestPublicHandler() (PublicHandler, *[]string) {
+	events := &[]string{}
+
+	conf := &dg.SectionService{}
+	conf.Authorization.Public.TimestampTolerance = 60
+
+	return PublicHandler{
+		Usecase: stubAPIClientUsecase{clients: map[string]da.APIClient{
+			testClientID: {
+				ID:            testClientID,
+				Secret:        testClientSecret,
+				AllowedRoutes: []string{"POST " + testPublicPath},
+				IsActive:      true,
+			},
+		}},
+		AuthEvent: stubAuthEventUsecase{events: events},
+		log:       logrus.New(),
+		Conf:      conf,
+		nonce:     infra.NewNonceStore(cg.NonceBackendMemory, nil),
+	}, events
+}
+
+func TestAuthValidator(t *testing.T) {
+	now := time.Now()
+	valid := signedRequest{
+		method:    http.MethodPost,
+		path:      testPublicPath,
+		body:      `{"order":1}`,
+		clientID:  testClientID,
+		secret:    testClientSecret,
+		timestamp: now,
+	}
+
+	with := func(nonce string, change func(*signedRequest)) signedRequest {
+		sr := valid
+		sr.nonce = nonce
+		if change != nil {
+			change(&sr)
+		}
+
+		return sr
+	}
+
+	// The case share the nonce store, run in order.
+	tests := []struct {
+		name       string
+		req        signedRequest
+		wantCode   int
+		wantReason string
+	}{
+		{name: "valid", req: with("n-1", nil), wantCode: http.StatusOK},
+		{name: "nonce replay", req: with("n-1", nil), wantCode: http.StatusUnauthorized, wantReason: "nonce reused"},
+		{name: "wrong secret", req: with("n-2", func(sr *signedRequest) { sr.secret = "other-secret" }), wantCode: http.StatusUnauthorized, wantReason: "invalid signature"},
+		{name: "nonce not burned by invalid signature", req: with("n-2", nil), wantCode: http.StatusOK},
+		{name: "tampered body", req: with("n-3", func(sr *signedRequest) { sr.signBody = `{"order":2}` }), wantCode: http.StatusUnauthorized, wantReason: "invalid signature"},
+		{name: "expired timestamp", req: with("n-4", func(sr *signedRequest) { sr.timestamp = now.Add(-2 * time.Minute) }), wantCode: http.StatusUnauthorized, wantReason: "timestamp out of tolerance"},
+		{name: "future timestamp", req: with("n-5", func(sr *signedRequest) { sr.timestamp = now.Add(2 * time.Minute) }), wantCode: http.StatusUnauthorized, wantReason: "timestamp out of tolerance"},
+		{name: "unknown client", req: with("n-6", func(sr *signedRequest) { sr.clientID = "client-2" }), wantCode: http.StatusUnauthorized, wantReason: "client not valid"},
+		{name: "route not allowed", req: with("n-7", func(sr *signedRequest) { sr.method = http.MethodDelete }), wantCode: http.StatusForbidden, wantReason: "route not allowed"},
+		{name: "missing nonce", req: with("", nil), wantCode: http.StatusUnauthorized, wantReason: "missing header"},
+	}
+
+	ph, events := newTestPublicHandler()
+
+	var gotBody string
+	handler := ph.AuthValidator(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		gotBody = string(body)
+
+		if client, ok := da.CurrentClient(req.Context()); !ok || client.Secret != "" {
+			t.Errorf("client on the context = %+v, want the client without secret", client)
+		}
+
+		res.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tt := range tests {
+		*events = nil
+		gotBody = ""
+
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, tt.req.build())
+
+		if res.Code != tt.wantCode {
+			t.Errorf("%s: code = %d, want %d", tt.name, res.Code, tt.wantCode)
+			continue
+		}
+
+		if tt.wantCode == http.StatusOK {
+			if gotBody != tt.req.body {
+				t.Errorf("%s: body on the next handler = %q, want %q", tt.name, gotBody, tt.req.body)
+			}
+			continue
+		}
+
+		if len(*events) != 1 || (*events)[0] != tt.wantReason {
+			t.Errorf("%s: auth event = %v, want [%s]", tt.name, *events, tt.wantReason)
+		}
+	}
+}
+
+func TestAuthValidatorBodyLimit(t *testing.T) {
+	ph, events := newTestPublicHandler()
+
+	handler := handlers.LimitBody(8, 8)(ph.AuthValidator(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		t.Error("next handler is called for the body larger than the limit")
+	})))
+
+	req := signedRequest{
+		method:    http.MethodPost,
+		path:      testPublicPath,
+		body:      `{"order":1}`,
+		clientID:  testClientID,
+		secret:    testClientSecret,
+		timestamp: time.Now(),
+		nonce:     "n-1",
+	}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req.build())
+
+	if res.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("code = %d, want %d", res.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	if len(*events) != 1 || (*events)[0] != "invalid body" {
+		t.Errorf("auth event = %v, want [invalid body]", *events)
+	}
+}
@@ -0,0 +1,122 @@
+package authorization
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"time"
+
+	cg "github.com/furee/backend/constants/general"
+	dg "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/utils/phone"
+	"github.com/sirupsen/logrus"
+)
+
+type RateLimitHandler struct {
+	log      *logrus.Logger
+	Conf     *dg.SectionService
+	limiter  infra.RateLimiter
+	fallback infra.RateLimiter
+}
+
+func NewRateLimitHandler(conf *dg.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger) RateLimitHandler {
+	return RateLimitHandler{
+		log:      logger,
+		Conf:     conf,
+		limiter:  infra.NewRateLimiter(conf.RateLimit.Backend, dbList.Redis),
+		fallback: infra.NewRateLimiter(cg.RateLimitBackendMemory, nil),
+	}
+}
+
+// OTPSendLimiter limit the request that send OTP message to the user.
+func (rh RateLimitHandler) OTPSendLimiter(next http.Handler) http.Handler {
+	return rh.limit(cg.RateLimitOTPSend, rh.Conf.RateLimit.OTPSend, next)
+}
+
+// OTPVerifyLimiter limit the OTP verification request.
+func (rh RateLimitHandler) OTPVerifyLimiter(next http.Handler) http.Handler {
+	return rh.limit(cg.RateLimitOTPVerify, rh.Conf.RateLimit.OTPVerify, next)
+}
+
+// limit check the budget of the phone hash, client IP & public Authorization-ID.
+// Request is rejected when one of the bucket is empty, the token is only taken when all of them allow it.
+func (rh RateLimitHandler) limit(action string, rule dg.RateLimitRule, next http.Handler) http.Handler {
+	// Rate limit is disabled when the rule is not configured.
+	if rule.Capacity <= 0 || rule.RefillInterval <= 0 {
+		rh.log.WithField("action", action).Warn("rate limit rule is not configured")
+		return next
+	}
+
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		respData := handlers.ResponseData{
+			Status: cg.Fail,
+		}
+
+		keys := map[string]string{
+			"ip":     handlers.GetClientIP(req, rh.Conf.Route.TrustedProxies),
+			"client": req.Header.Get(cg.APIHeaderAuthorizationID),
+		}
+
+		// The body is limited by the LimitBody middleware, the error is returned when it is larger.
+		reqBody, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataTooLarge
+			handlers.WriteResponse(res, respData, http.StatusRequestEntityTooLarge)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+
+		var param struct {
+			Phone string `json:"phone"`
+		}
+
+		if json.Unmarshal(reqBody, &param) == nil && param.Phone != "" {
+			// Same number in different format share the same bucket.
+			if number, err := phone.Parse(param.Phone, phone.RegionID); err == nil {
+				param.Phone = number.E164
+			}
+
+			keys["phone"] = phone.BlindIndex([]byte(rh.Conf.Authorization.Phone.FilterPepper), param.Phone)
+		}
+
+		bucketKeys := make([]string, 0, len(keys))
+		for keyType, value := range keys {
+			if value == "" {
+				continue
+			}
+
+			bucketKeys = append(bucketKeys, fmt.Sprintf("ratelimit:%s:%s:%s", action, keyType, value))
+		}
+
+		allowed, retryAfter, err := rh.limiter.Allow(bucketKeys, rule)
+		if err != nil {
+			// The limiter is kept on when the backend is unavailable, the budget is counted per instance.
+			rh.log.WithField("action", action).WithError(err).Error("RateLimit | fail to check rate limit, use memory limiter")
+
+			allowed, retryAfter, err = rh.fallback.Allow(bucketKeys, rule)
+			if err != nil {
+				rh.log.WithField("action", action).WithError(err).Error("RateLimit | fail to check rate limit on memory limiter")
+
+				respData.Message = cg.HandlerErrorTooManyRequest
+				handlers.WriteResponse(res, respData, http.StatusServiceUnavailable)
+				return
+			}
+		}
+
+		if !allowed {
+			rh.log.WithField("action", action).WithField("keys", len(bucketKeys)).Warn("RateLimit | request is limited")
+
+			res.Header().Set(cg.APIHeaderRetryAfter, fmt.Sprintf("%d", int64(math.Ceil(float64(retryAfter)/float64(time.Second)))))
+			respData.Message = cg.HandlerErrorTooManyRequest
+			handlers.WriteResponse(res, respData, http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(res, req)
+	})
+}
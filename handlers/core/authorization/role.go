@@ -0,0 +1,85 @@
+package authorization
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	dg "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	ua "github.com/furee/backend/usecase/authorization"
+	"github.com/furee/backend/utils"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type RoleHandler struct {
+	Usecase ua.RoleUsecaseItf
+	log     *logrus.Logger
+	Conf    *dg.SectionService
+}
+
+func NewRoleHandler(uc usecase.Usecase, conf *dg.SectionService, logger *logrus.Logger) RoleHandler {
+	return RoleHandler{
+		Usecase: uc.Authorization.Role,
+		log:     logger,
+		Conf:    conf,
+	}
+}
+
+func (rh RoleHandler) AssignRole(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	userID, err := utils.StrToInt64(mux.Vars(req)["userid"])
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	var param da.AssignRoleRequest
+
+	reqBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataEmpty
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = json.Unmarshal(reqBody, &param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataNotValid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	roles, message, err := rh.Usecase.AssignRoles(req.Context(), userID, param)
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "user not found" {
+			code = http.StatusNotFound
+		}
+
+		if message == "" {
+			message = "fail to assign role"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  roles,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
@@ -0,0 +1,86 @@
+package authorization
+
+import (
+	"net/http"
+
+	cg "github.com/furee/backend/constants/general"
+	dg "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	ua "github.com/furee/backend/usecase/authorization"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type SessionHandler struct {
+	Usecase ua.SessionUsecaseItf
+	log     *logrus.Logger
+	Conf    *dg.SectionService
+}
+
+func NewSessionHandler(uc usecase.Usecase, conf *dg.SectionService, logger *logrus.Logger) SessionHandler {
+	return SessionHandler{
+		Usecase: uc.Authorization.Session,
+		log:     logger,
+		Conf:    conf,
+	}
+}
+
+func (sh SessionHandler) GetListSession(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	sessions, message, err := sh.Usecase.GetListSession(req.Context())
+	if err != nil {
+		code := http.StatusUnauthorized
+		if message == "" {
+			message = "fail to get session"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status: cg.Success,
+		Detail: sessions,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (sh SessionHandler) RevokeSession(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	sessionID := mux.Vars(req)["sessionid"]
+	if sessionID == "" {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	message, err := sh.Usecase.RevokeSession(req.Context(), sessionID)
+	if err != nil {
+		code := http.StatusNotFound
+		if message == "" {
+			message = "fail to revoke session"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
@@ -5,29 +5,48 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
 	dg "github.com/furee/backend/domain/general"
 	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	ua "github.com/furee/backend/usecase/authorization"
+	uu "github.com/furee/backend/usecase/user"
 	"github.com/furee/backend/utils"
 	"github.com/sirupsen/logrus"
 )
 
 type TokenHandler struct {
-	log  *logrus.Logger
-	Conf *dg.SectionService
+	Usecase     ua.TokenUsecaseItf
+	UserUsecase uu.UserDataUsecaseItf
+	Session     ua.SessionUsecaseItf
+	log         *logrus.Logger
+	Conf        *dg.SectionService
 }
 
-func NewTokenHandler(conf *dg.SectionService, logger *logrus.Logger) TokenHandler {
-	utils.InitJWTConfig(conf.Authorization.JWT)
+func NewTokenHandler(uc usecase.Usecase, conf *dg.SectionService, logger *logrus.Logger) TokenHandler {
 	return TokenHandler{
-		log:  logger,
-		Conf: conf,
+		Usecase:     uc.Authorization.Token,
+		UserUsecase: uc.User.User,
+		Session:     uc.Authorization.Session,
+		log:         logger,
+		Conf:        conf,
 	}
 }
 
+// JWTValidator accept only the token of user that already complete the login,
+// token with mfa_pending claim is rejected.
 func (th TokenHandler) JWTValidator(next http.Handler) http.Handler {
+	return th.validateToken(next, false)
+}
+
+// MFAPendingValidator accept only the token with mfa_pending claim, used on the second step of the login.
+func (th TokenHandler) MFAPendingValidator(next http.Handler) http.Handler {
+	return th.validateToken(next, true)
+}
+
+func (th TokenHandler) validateToken(next http.Handler, mfaPending bool) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		respData := handlers.ResponseData{
 			Status: cg.Fail,
@@ -39,6 +58,12 @@ func (th TokenHandler) JWTValidator(next http.Handler) http.Handler {
 			return
 		}
 
+		// Already authenticated by the APIKeyHandler.
+		if _, ok := da.CurrentAPIKey(req.Context()); ok && !mfaPending {
+			next.ServeHTTP(res, req)
+			return
+		}
+
 		authorizationHeader := req.Header.Get("Authorization")
 		if !strings.Contains(authorizationHeader, "Bearer") {
 			th.log.Error(fmt.Errorf("Invalid Token Format"))
@@ -55,7 +80,77 @@ func (th TokenHandler) JWTValidator(next http.Handler) http.Handler {
 			return
 		}
 
-		ctx := context.WithValue(req.Context(), "session", claims["session"])
+		session := da.TokenSession{
+			AccessTokenID:      utils.GetClaimString(claims, "jti"),
+			AccessTokenExpired: utils.GetClaimExpired(claims),
+			FamilyID:           utils.GetClaimString(claims, "fam"),
+			Roles:              utils.GetClaimStrings(claims, "roles"),
+			Permissions:        utils.GetClaimStrings(claims, "perms"),
+			MFAPending:         utils.GetClaimBool(claims, "mfa_pending"),
+		}
+
+		if session.MFAPending && !mfaPending {
+			respData.Message = "MFA verification required"
+			handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+			return
+		}
+
+		if !session.MFAPending && mfaPending {
+			respData.Message = cg.HandlerErrorTokenInvalid
+			handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+			return
+		}
+
+		session.UserID, err = utils.GetUserIDFromToken(utils.GetClaimString(claims, "session"), th.Conf.App.SecretKey)
+		if err != nil {
+			th.log.WithError(err).Error("JWTValidator | fail to get user id from token")
+			respData.Message = cg.HandlerErrorTokenInvalid
+			handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+			return
+		}
+
+		isRevoked, err := th.Usecase.IsAccessTokenRevoked(session.AccessTokenID)
+		if err != nil {
+			th.log.WithError(err).Error("JWTValidator | fail to check revoked token")
+			respData.Message = "fail to check token"
+			handlers.WriteResponse(res, respData, http.StatusInternalServerError)
+			return
+		}
+
+		if isRevoked {
+			respData.Message = "Token revoked"
+			handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+			return
+		}
+
+		message, err := th.Session.ValidateSession(session)
+		if err != nil {
+			code := http.StatusUnauthorized
+			if message == "" {
+				message = "fail to check session"
+				code = http.StatusInternalServerError
+			}
+
+			respData.Message = message
+			handlers.WriteResponse(res, respData, code)
+			return
+		}
+
+		user, message, err := th.UserUsecase.GetActiveUser(req.Context(), session.UserID)
+		if err != nil {
+			code := http.StatusUnauthorized
+			if message == "" {
+				message = "fail to get user data"
+				code = http.StatusInternalServerError
+			}
+
+			respData.Message = message
+			handlers.WriteResponse(res, respData, code)
+			return
+		}
+
+		ctx := da.WithSession(req.Context(), session)
+		ctx = da.WithUser(ctx, user)
 		req = req.WithContext(ctx)
 
 		next.ServeHTTP(res, req)
@@ -75,27 +170,69 @@ func (th TokenHandler) RenewAccessToken(res http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	accessToken := strings.Replace(authorizationHeader, "Bearer ", "", -1)
+	refreshToken := strings.Replace(authorizationHeader, "Bearer ", "", -1)
 
-	token, err := utils.RenewAccessToken(accessToken)
+	token, message, err := th.Usecase.RenewToken(req.Context(), refreshToken)
 	if err != nil {
-		th.log.WithField("renew access token", accessToken).WithError(err).Error("Error Renew Token")
-		respData.Message = "Fail to Renew Token"
-		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		th.log.WithError(err).Error("Error Renew Token")
+
+		code := http.StatusUnauthorized
+		if message == "" {
+			message = "Fail to Renew Token"
+			code = http.StatusBadRequest
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
 		return
 	}
 
-	tokenExpired := time.Now().UTC().Add(time.Duration(th.Conf.Authorization.JWT.AccessTokenDuration) * time.Minute)
-
 	respData = &handlers.ResponseData{
 		Status:  cg.Success,
-		Message: "success generate new access token",
-		Detail: dg.RenewToken{
-			Token:        token,
-			TokenExpired: tokenExpired.Format(time.RFC3339),
-		},
+		Message: message,
+		Detail:  token,
 	}
 
 	handlers.WriteResponse(res, respData, http.StatusOK)
 	return
 }
+
+func (th TokenHandler) Logout(res http.ResponseWriter, req *http.Request) {
+	th.logout(res, req, th.Usecase.Logout)
+}
+
+func (th TokenHandler) LogoutAll(res http.ResponseWriter, req *http.Request) {
+	th.logout(res, req, th.Usecase.LogoutAll)
+}
+
+func (th TokenHandler) logout(res http.ResponseWriter, req *http.Request, revoke func(ctx context.Context) error) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	if _, ok := da.GetSession(req.Context()); !ok {
+		respData.Message = cg.HandlerErrorTokenInvalid
+		handlers.WriteResponse(res, respData, http.StatusUnauthorized)
+		return
+	}
+
+	err := revoke(req.Context())
+	if err != nil {
+		respData.Message = "fail to logout"
+		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: "success logout",
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+// JWKS publish the public key that used to sign the access token.
+func (th TokenHandler) JWKS(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Cache-Control", "public, max-age=300")
+	handlers.WriteResponse(res, utils.GetJWKS(), http.StatusOK)
+}
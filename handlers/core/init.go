@@ -6,24 +6,39 @@ import (
 	"github.com/furee/backend/handlers/core/master"
 	"github.com/furee/backend/handlers/core/order"
 	"github.com/furee/backend/handlers/core/user"
+	"github.com/furee/backend/infra"
 	"github.com/furee/backend/usecase"
 	"github.com/sirupsen/logrus"
 )
 
 type Handler struct {
-	Token  authorization.TokenHandler
-	Public authorization.PublicHandler
-	Master master.MasterHandler
-	User   user.UserHandler
-	Order  order.OrderHandler
+	Token     authorization.TokenHandler
+	Public    authorization.PublicHandler
+	Role      authorization.RoleHandler
+	RateLimit authorization.RateLimitHandler
+	MFA       authorization.MFAHandler
+	Session   authorization.SessionHandler
+	AuthEvent authorization.AuthEventHandler
+	OIDC      authorization.OIDCHandler
+	APIKey    authorization.APIKeyHandler
+	Master    master.MasterHandler
+	User      user.UserHandler
+	Order     order.OrderHandler
 }
 
-func NewHandler(uc usecase.Usecase, conf *general.SectionService, logger *logrus.Logger) Handler {
+func NewHandler(uc usecase.Usecase, conf *general.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger) Handler {
 	return Handler{
-		Token:  authorization.NewTokenHandler(conf, logger),
-		Public: authorization.NewPublicHandler(conf, logger),
-		Master: master.NewHandler(uc, conf, logger),
-		User:   user.NewHandler(uc, conf, logger),
-		Order:  order.NewHandler(uc, conf, logger),
+		Token:     authorization.NewTokenHandler(uc, conf, logger),
+		Public:    authorization.NewPublicHandler(uc, conf, dbList, logger),
+		Role:      authorization.NewRoleHandler(uc, conf, logger),
+		RateLimit: authorization.NewRateLimitHandler(conf, dbList, logger),
+		MFA:       authorization.NewMFAHandler(uc, conf, logger),
+		Session:   authorization.NewSessionHandler(uc, conf, logger),
+		AuthEvent: authorization.NewAuthEventHandler(uc, conf, logger),
+		OIDC:      authorization.NewOIDCHandler(uc, conf, logger),
+		APIKey:    authorization.NewAPIKeyHandler(uc, conf, logger),
+		Master:    master.NewHandler(uc, conf, logger),
+		User:      user.NewHandler(uc, conf, logger),
+		Order:     order.NewHandler(uc, conf, logger),
 	}
 }
@@ -0,0 +1,309 @@
+package master
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	cg "github.com/furee/backend/constants/general"
+	cm "github.com/furee/backend/constants/master"
+	"github.com/furee/backend/domain/general"
+	dm "github.com/furee/backend/domain/master"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	um "github.com/furee/backend/usecase/master"
+	"github.com/furee/backend/utils"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/dealancer/validate.v2"
+)
+
+// AdminHandler is the create, update & deactivate of the master data, only for the master admin.
+type AdminHandler struct {
+	Usecase um.AdminUsecaseItf
+	conf    *general.SectionService
+	log     *logrus.Logger
+}
+
+func newAdminHandler(uc usecase.Usecase, conf *general.SectionService, logger *logrus.Logger) AdminHandler {
+	return AdminHandler{
+		Usecase: uc.Master.Admin,
+		conf:    conf,
+		log:     logger,
+	}
+}
+
+func (ah AdminHandler) CreateCountry(res http.ResponseWriter, req *http.Request) {
+	var param dm.CountryRequest
+
+	if !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.CreateCountry(req.Context(), param)
+	ah.writeResult(res, data, message, err, "fail to create country")
+}
+
+func (ah AdminHandler) UpdateCountry(res http.ResponseWriter, req *http.Request) {
+	var param dm.CountryRequest
+
+	id, ok := ah.readID(res, req, "countryid")
+	if !ok || !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.UpdateCountry(req.Context(), id, param)
+	ah.writeResult(res, data, message, err, "fail to update country")
+}
+
+func (ah AdminHandler) DeactivateCountry(res http.ResponseWriter, req *http.Request) {
+	id, ok := ah.readID(res, req, "countryid")
+	if !ok {
+		return
+	}
+
+	message, err := ah.Usecase.DeactivateCountry(req.Context(), id)
+	ah.writeResult(res, nil, message, err, "fail to deactivate country")
+}
+
+func (ah AdminHandler) CreateProvince(res http.ResponseWriter, req *http.Request) {
+	var param dm.ProvinceRequest
+
+	if !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.CreateProvince(req.Context(), param)
+	ah.writeResult(res, data, message, err, "fail to create province")
+}
+
+func (ah AdminHandler) UpdateProvince(res http.ResponseWriter, req *http.Request) {
+	var param dm.ProvinceRequest
+
+	id, ok := ah.readID(res, req, "provinceid")
+	if !ok || !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.UpdateProvince(req.Context(), id, param)
+	ah.writeResult(res, data, message, err, "fail to update province")
+}
+
+func (ah AdminHandler) DeactivateProvince(res http.ResponseWriter, req *http.Request) {
+	id, ok := ah.readID(res, req, "provinceid")
+	if !ok {
+		return
+	}
+
+	message, err := ah.Usecase.DeactivateProvince(req.Context(), id)
+	ah.writeResult(res, nil, message, err, "fail to deactivate province")
+}
+
+func (ah AdminHandler) CreateCity(res http.ResponseWriter, req *http.Request) {
+	var param dm.CityRequest
+
+	if !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.CreateCity(req.Context(), param)
+	ah.writeResult(res, data, message, err, "fail to create city")
+}
+
+func (ah AdminHandler) UpdateCity(res http.ResponseWriter, req *http.Request) {
+	var param dm.CityRequest
+
+	id, ok := ah.readID(res, req, "cityid")
+	if !ok || !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.UpdateCity(req.Context(), id, param)
+	ah.writeResult(res, data, message, err, "fail to update city")
+}
+
+func (ah AdminHandler) DeactivateCity(res http.ResponseWriter, req *http.Request) {
+	id, ok := ah.readID(res, req, "cityid")
+	if !ok {
+		return
+	}
+
+	message, err := ah.Usecase.DeactivateCity(req.Context(), id)
+	ah.writeResult(res, nil, message, err, "fail to deactivate city")
+}
+
+func (ah AdminHandler) CreateDistrict(res http.ResponseWriter, req *http.Request) {
+	var param dm.DistrictRequest
+
+	if !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.CreateDistrict(req.Context(), param)
+	ah.writeResult(res, data, message, err, "fail to create district")
+}
+
+func (ah AdminHandler) UpdateDistrict(res http.ResponseWriter, req *http.Request) {
+	var param dm.DistrictRequest
+
+	id, ok := ah.readID(res, req, "districtid")
+	if !ok || !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.UpdateDistrict(req.Context(), id, param)
+	ah.writeResult(res, data, message, err, "fail to update district")
+}
+
+func (ah AdminHandler) DeactivateDistrict(res http.ResponseWriter, req *http.Request) {
+	id, ok := ah.readID(res, req, "districtid")
+	if !ok {
+		return
+	}
+
+	message, err := ah.Usecase.DeactivateDistrict(req.Context(), id)
+	ah.writeResult(res, nil, message, err, "fail to deactivate district")
+}
+
+func (ah AdminHandler) CreateSubDistrict(res http.ResponseWriter, req *http.Request) {
+	var param dm.SubDistrictRequest
+
+	if !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.CreateSubDistrict(req.Context(), param)
+	ah.writeResult(res, data, message, err, "fail to create sub district")
+}
+
+func (ah AdminHandler) UpdateSubDistrict(res http.ResponseWriter, req *http.Request) {
+	var param dm.SubDistrictRequest
+
+	id, ok := ah.readID(res, req, "subdistrictid")
+	if !ok || !ah.readRequest(res, req, &param) {
+		return
+	}
+
+	data, message, err := ah.Usecase.UpdateSubDistrict(req.Context(), id, param)
+	ah.writeResult(res, data, message, err, "fail to update sub district")
+}
+
+func (ah AdminHandler) DeactivateSubDistrict(res http.ResponseWriter, req *http.Request) {
+	id, ok := ah.readID(res, req, "subdistrictid")
+	if !ok {
+		return
+	}
+
+	message, err := ah.Usecase.DeactivateSubDistrict(req.Context(), id)
+	ah.writeResult(res, nil, message, err, "fail to deactivate sub district")
+}
+
+// ImportLocation accept the region code CSV of each level on the form file named by the level, e.g. sub_district.
+// Use dry-run=true to get the diff report without saving the change.
+func (ah AdminHandler) ImportLocation(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	req.Body = http.MaxBytesReader(res, req.Body, cm.LocationImportMaxSize)
+
+	err := req.ParseMultipartForm(cm.LocationImportMaxSize)
+	if err != nil {
+		respData.Message = cg.HandlerErrorFileDataInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	files := make(map[string]io.Reader)
+	for _, level := range cm.LocationLevels {
+		file, _, err := req.FormFile(level)
+		if err == http.ErrMissingFile {
+			continue
+		}
+
+		if err != nil {
+			respData.Message = cg.HandlerErrorFileDataInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		defer file.Close()
+		files[level] = file
+	}
+
+	report, message, err := ah.Usecase.ImportLocation(req.Context(), files, utils.GetBool(req.FormValue("dry-run")))
+	ah.writeResult(res, report, message, err, "fail to import location")
+}
+
+// readID write the bad request response when the id on the path is not valid.
+func (ah AdminHandler) readID(res http.ResponseWriter, req *http.Request, name string) (int64, bool) {
+	id, err := utils.StrToInt64(mux.Vars(req)[name])
+	if err != nil {
+		handlers.WriteResponse(res, handlers.ResponseData{
+			Status:  cg.Fail,
+			Message: cg.HandlerErrorRequestDataFormatInvalid,
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+
+	return id, true
+}
+
+// readRequest write the bad request response when the body is not valid.
+func (ah AdminHandler) readRequest(res http.ResponseWriter, req *http.Request, param interface{}) bool {
+	respData := handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	reqBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataEmpty
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return false
+	}
+
+	err = json.Unmarshal(reqBody, param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataNotValid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return false
+	}
+
+	err = validate.Validate(param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// writeResult map the not found message to 404, other message to 400 & empty message to 500.
+func (ah AdminHandler) writeResult(res http.ResponseWriter, data interface{}, message string, err error, failMessage string) {
+	if err != nil {
+		code := http.StatusBadRequest
+		if strings.HasSuffix(message, "not found") {
+			code = http.StatusNotFound
+		}
+
+		if message == "" {
+			message = failMessage
+			code = http.StatusInternalServerError
+		}
+
+		handlers.WriteResponse(res, handlers.ResponseData{
+			Status:  cg.Fail,
+			Message: message,
+		}, code)
+		return
+	}
+
+	handlers.WriteResponse(res, handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  data,
+	}, http.StatusOK)
+}
@@ -37,6 +37,9 @@ func (ch CityHandler) GetListCity(res http.ResponseWriter, req *http.Request) {
 	var tableFilter dm.CityFilter
 	var err error
 
+	// The deactivated data is not shown.
+	tableFilter.IsActive = null.BoolFrom(true)
+
 	paginationData := general.GetPagination()
 
 	// Check province name value
@@ -56,9 +59,12 @@ func (ch CityHandler) GetListCity(res http.ResponseWriter, req *http.Request) {
 		tableFilter.ProvinceID = null.IntFrom(provinceID)
 	}
 
-	// Check sort value
-	if req.FormValue("sort") != "" {
-		paginationData.Sort = req.FormValue(("sort"))
+	// Check sort value, format is field[:asc|desc] separated by comma
+	err = handlers.ReadSort(req, &paginationData)
+	if err != nil {
+		respData.Message = err.Error()
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
 	}
 
 	// Check page value. If exist, convert to int
@@ -71,12 +77,6 @@ func (ch CityHandler) GetListCity(res http.ResponseWriter, req *http.Request) {
 		}
 	}
 
-	// Check orderby value.
-	paginationData.OrderBy = null.StringFrom("city_id")
-	if req.FormValue("order-by") != "" {
-		paginationData.OrderBy.String = req.FormValue("order-by")
-	}
-
 	// Check isGetAll value.
 	if req.FormValue("is-get-all") != "" {
 		paginationData.IsGetAll = utils.GetBool(req.FormValue("is-get-all"))
@@ -92,11 +92,20 @@ func (ch CityHandler) GetListCity(res http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	// Limit the page size, get all is limited as well
+	paginationData.ClampLimit(ch.conf.Route.MaxPageLimit, ch.conf.Route.MaxGetAllLimit)
+
 	// Convert page to offset
 	paginationData.SetOffset()
 
-	data, paginationData, _, err := ch.Usecase.GetListCity(paginationData, tableFilter)
+	data, paginationData, source, err := ch.Usecase.GetListCity(paginationData, tableFilter)
 	if err != nil {
+		if general.IsSortError(err) {
+			respData.Message = err.Error()
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
 		respData.Message = "fail to get list city"
 		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
 		return
@@ -104,6 +113,7 @@ func (ch CityHandler) GetListCity(res http.ResponseWriter, req *http.Request) {
 
 	respData = &handlers.ResponseData{
 		Status:  cg.Success,
+		Source:  source,
 		Message: "success get list city",
 		Detail: general.ResponseData{
 			Data:       data,
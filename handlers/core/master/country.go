@@ -37,6 +37,9 @@ func (ch CountryHandler) GetListCountry(res http.ResponseWriter, req *http.Reque
 	var tableFilter dm.CountryFilter
 	var err error
 
+	// The deactivated data is not shown.
+	tableFilter.IsActive = null.BoolFrom(true)
+
 	paginationData := general.GetPagination()
 
 	// Check city name value
@@ -44,9 +47,12 @@ func (ch CountryHandler) GetListCountry(res http.ResponseWriter, req *http.Reque
 		tableFilter.Name = null.StringFrom(req.FormValue("name"))
 	}
 
-	// Check sort value
-	if req.FormValue("sort") != "" {
-		paginationData.Sort = req.FormValue(("sort"))
+	// Check sort value, format is field[:asc|desc] separated by comma
+	err = handlers.ReadSort(req, &paginationData)
+	if err != nil {
+		respData.Message = err.Error()
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
 	}
 
 	// Check page value. If exist, convert to int
@@ -59,12 +65,6 @@ func (ch CountryHandler) GetListCountry(res http.ResponseWriter, req *http.Reque
 		}
 	}
 
-	// Check orderby value.
-	paginationData.OrderBy = null.StringFrom("country_id")
-	if req.FormValue("order-by") != "" {
-		paginationData.OrderBy.String = req.FormValue("order-by")
-	}
-
 	// Check isGetAll value.
 	if req.FormValue("is-get-all") != "" {
 		paginationData.IsGetAll = utils.GetBool(req.FormValue("is-get-all"))
@@ -80,11 +80,20 @@ func (ch CountryHandler) GetListCountry(res http.ResponseWriter, req *http.Reque
 		}
 	}
 
+	// Limit the page size, get all is limited as well
+	paginationData.ClampLimit(ch.conf.Route.MaxPageLimit, ch.conf.Route.MaxGetAllLimit)
+
 	// Convert page to offset
 	paginationData.SetOffset()
 
-	data, paginationData, _, err := ch.Usecase.GetListCountry(paginationData, tableFilter)
+	data, paginationData, source, err := ch.Usecase.GetListCountry(paginationData, tableFilter)
 	if err != nil {
+		if general.IsSortError(err) {
+			respData.Message = err.Error()
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
 		respData.Message = "fail to get list country"
 		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
 		return
@@ -92,6 +101,7 @@ func (ch CountryHandler) GetListCountry(res http.ResponseWriter, req *http.Reque
 
 	respData = &handlers.ResponseData{
 		Status:  cg.Success,
+		Source:  source,
 		Message: "success get list country",
 		Detail: general.ResponseData{
 			Data:       data,
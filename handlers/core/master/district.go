@@ -37,6 +37,9 @@ func (dh DistrictHandler) GetListDistrict(res http.ResponseWriter, req *http.Req
 	var tableFilter dm.DistrictFilter
 	var err error
 
+	// The deactivated data is not shown.
+	tableFilter.IsActive = null.BoolFrom(true)
+
 	paginationData := general.GetPagination()
 
 	// Check province name value
@@ -56,9 +59,12 @@ func (dh DistrictHandler) GetListDistrict(res http.ResponseWriter, req *http.Req
 		tableFilter.CityID = null.IntFrom(cityID)
 	}
 
-	// Check sort value
-	if req.FormValue("sort") != "" {
-		paginationData.Sort = req.FormValue(("sort"))
+	// Check sort value, format is field[:asc|desc] separated by comma
+	err = handlers.ReadSort(req, &paginationData)
+	if err != nil {
+		respData.Message = err.Error()
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
 	}
 
 	// Check page value. If exist, convert to int
@@ -71,12 +77,6 @@ func (dh DistrictHandler) GetListDistrict(res http.ResponseWriter, req *http.Req
 		}
 	}
 
-	// Check orderby value.
-	paginationData.OrderBy = null.StringFrom("district_id")
-	if req.FormValue("order-by") != "" {
-		paginationData.OrderBy.String = req.FormValue("order-by")
-	}
-
 	// Check isGetAll value.
 	if req.FormValue("is-get-all") != "" {
 		paginationData.IsGetAll = utils.GetBool(req.FormValue("is-get-all"))
@@ -92,11 +92,20 @@ func (dh DistrictHandler) GetListDistrict(res http.ResponseWriter, req *http.Req
 		}
 	}
 
+	// Limit the page size, get all is limited as well
+	paginationData.ClampLimit(dh.conf.Route.MaxPageLimit, dh.conf.Route.MaxGetAllLimit)
+
 	// Convert page to offset
 	paginationData.SetOffset()
 
-	data, paginationData, _, err := dh.Usecase.GetListDistrict(paginationData, tableFilter)
+	data, paginationData, source, err := dh.Usecase.GetListDistrict(paginationData, tableFilter)
 	if err != nil {
+		if general.IsSortError(err) {
+			respData.Message = err.Error()
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
 		respData.Message = "fail to get list district"
 		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
 		return
@@ -104,6 +113,7 @@ func (dh DistrictHandler) GetListDistrict(res http.ResponseWriter, req *http.Req
 
 	respData = &handlers.ResponseData{
 		Status:  cg.Success,
+		Source:  source,
 		Message: "success get list district",
 		Detail: general.ResponseData{
 			Data:       data,
@@ -12,6 +12,8 @@ type MasterHandler struct {
 	City        CityHandler
 	District    DistrictHandler
 	SubDistrict SubDistrictHandler
+	Location    LocationHandler
+	Admin       AdminHandler
 }
 
 func NewHandler(uc usecase.Usecase, conf *general.SectionService, logger *logrus.Logger) MasterHandler {
@@ -21,5 +23,7 @@ func NewHandler(uc usecase.Usecase, conf *general.SectionService, logger *logrus
 		City:        newCityHandler(uc, conf, logger),
 		District:    newDistrictHandler(uc, conf, logger),
 		SubDistrict: newSubDistrictHandler(uc, conf, logger),
+		Location:    newLocationHandler(uc, conf, logger),
+		Admin:       newAdminHandler(uc, conf, logger),
 	}
 }
@@ -0,0 +1,128 @@
+package master
+
+import (
+	"net/http"
+	"strconv"
+
+	cg "github.com/furee/backend/constants/general"
+	cm "github.com/furee/backend/constants/master"
+	"github.com/furee/backend/domain/general"
+	dm "github.com/furee/backend/domain/master"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	um "github.com/furee/backend/usecase/master"
+	"github.com/furee/backend/utils"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+)
+
+type LocationHandler struct {
+	Usecase um.LocationUsecaseItf
+	conf    *general.SectionService
+	log     *logrus.Logger
+}
+
+func newLocationHandler(uc usecase.Usecase, conf *general.SectionService, logger *logrus.Logger) LocationHandler {
+	return LocationHandler{
+		Usecase: uc.Master.Location,
+		conf:    conf,
+		log:     logger,
+	}
+}
+
+// GetPath return the country, province, city, district & sub district of the sub district in one call.
+func (lh LocationHandler) GetPath(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	subDistrictID, err := utils.StrToInt64(mux.Vars(req)["subdistrictid"])
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	data, message, err := lh.Usecase.GetPath(subDistrictID)
+	if err != nil {
+		code := http.StatusNotFound
+		if message == "" {
+			message = "fail to get location path"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: "success get location path",
+		Detail:  data,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+// GetTree return the nested location, rooted at the level & id when set, otherwise at all the country.
+func (lh LocationHandler) GetTree(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	var err error
+
+	filter := dm.LocationTreeFilter{
+		Level: req.FormValue("level"),
+		Depth: cm.LocationTreeDefaultDepth,
+	}
+
+	// Check id of the root value
+	if req.FormValue("id") != "" {
+		id, err := utils.StrToInt64(req.FormValue("id"))
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		filter.ID = null.IntFrom(id)
+	}
+
+	// Check depth value. If exists, convert to int
+	if req.FormValue("depth") != "" {
+		filter.Depth, err = strconv.Atoi(req.FormValue("depth"))
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+	}
+
+	data, message, err := lh.Usecase.GetTree(filter)
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "location not found" {
+			code = http.StatusNotFound
+		}
+
+		if message == "" {
+			message = "fail to get location tree"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: "success get location tree",
+		Detail:  data,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
@@ -37,6 +37,9 @@ func (ph ProvinceHandler) GetListProvince(res http.ResponseWriter, req *http.Req
 	var tableFilter dm.ProvinceFilter
 	var err error
 
+	// The deactivated data is not shown.
+	tableFilter.IsActive = null.BoolFrom(true)
+
 	paginationData := general.GetPagination()
 
 	// Check province name value
@@ -56,9 +59,12 @@ func (ph ProvinceHandler) GetListProvince(res http.ResponseWriter, req *http.Req
 		tableFilter.CountryID = null.IntFrom(countryID)
 	}
 
-	// Check sort value
-	if req.FormValue("sort") != "" {
-		paginationData.Sort = req.FormValue(("sort"))
+	// Check sort value, format is field[:asc|desc] separated by comma
+	err = handlers.ReadSort(req, &paginationData)
+	if err != nil {
+		respData.Message = err.Error()
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
 	}
 
 	// Check page value. If exist, convert to int
@@ -71,12 +77,6 @@ func (ph ProvinceHandler) GetListProvince(res http.ResponseWriter, req *http.Req
 		}
 	}
 
-	// Check orderby value.
-	paginationData.OrderBy = null.StringFrom("province_id")
-	if req.FormValue("order-by") != "" {
-		paginationData.OrderBy.String = req.FormValue("order-by")
-	}
-
 	// Check isGetAll value.
 	if req.FormValue("is-get-all") != "" {
 		paginationData.IsGetAll = utils.GetBool(req.FormValue("is-get-all"))
@@ -92,11 +92,20 @@ func (ph ProvinceHandler) GetListProvince(res http.ResponseWriter, req *http.Req
 		}
 	}
 
+	// Limit the page size, get all is limited as well
+	paginationData.ClampLimit(ph.conf.Route.MaxPageLimit, ph.conf.Route.MaxGetAllLimit)
+
 	// Convert page to offset
 	paginationData.SetOffset()
 
-	data, paginationData, _, err := ph.Usecase.GetListProvince(paginationData, tableFilter)
+	data, paginationData, source, err := ph.Usecase.GetListProvince(paginationData, tableFilter)
 	if err != nil {
+		if general.IsSortError(err) {
+			respData.Message = err.Error()
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
 		respData.Message = "fail to get list province"
 		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
 		return
@@ -104,6 +113,7 @@ func (ph ProvinceHandler) GetListProvince(res http.ResponseWriter, req *http.Req
 
 	respData = &handlers.ResponseData{
 		Status:  cg.Success,
+		Source:  source,
 		Message: "success get list province",
 		Detail: general.ResponseData{
 			Data:       data,
@@ -37,6 +37,9 @@ func (sdh SubDistrictHandler) GetListSubDistrict(res http.ResponseWriter, req *h
 	var tableFilter dm.SubDistrictFilter
 	var err error
 
+	// The deactivated data is not shown.
+	tableFilter.IsActive = null.BoolFrom(true)
+
 	paginationData := general.GetPagination()
 
 	// Check province name value
@@ -56,9 +59,12 @@ func (sdh SubDistrictHandler) GetListSubDistrict(res http.ResponseWriter, req *h
 		tableFilter.DistrictID = null.IntFrom(districtID)
 	}
 
-	// Check sort value
-	if req.FormValue("sort") != "" {
-		paginationData.Sort = req.FormValue(("sort"))
+	// Check sort value, format is field[:asc|desc] separated by comma
+	err = handlers.ReadSort(req, &paginationData)
+	if err != nil {
+		respData.Message = err.Error()
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
 	}
 
 	// Check page value. If exist, convert to int
@@ -71,12 +77,6 @@ func (sdh SubDistrictHandler) GetListSubDistrict(res http.ResponseWriter, req *h
 		}
 	}
 
-	// Check orderby value.
-	paginationData.OrderBy = null.StringFrom("sub_district_id")
-	if req.FormValue("order-by") != "" {
-		paginationData.OrderBy.String = req.FormValue("order-by")
-	}
-
 	// Check isGetAll value.
 	if req.FormValue("is-get-all") != "" {
 		paginationData.IsGetAll = utils.GetBool(req.FormValue("is-get-all"))
@@ -92,11 +92,20 @@ func (sdh SubDistrictHandler) GetListSubDistrict(res http.ResponseWriter, req *h
 		}
 	}
 
+	// Limit the page size, get all is limited as well
+	paginationData.ClampLimit(sdh.conf.Route.MaxPageLimit, sdh.conf.Route.MaxGetAllLimit)
+
 	// Convert page to offset
 	paginationData.SetOffset()
 
-	data, paginationData, _, err := sdh.Usecase.GetListSubDistrict(paginationData, tableFilter)
+	data, paginationData, source, err := sdh.Usecase.GetListSubDistrict(paginationData, tableFilter)
 	if err != nil {
+		if general.IsSortError(err) {
+			respData.Message = err.Error()
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
 		respData.Message = "fail to get list sub district"
 		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
 		return
@@ -104,6 +113,7 @@ func (sdh SubDistrictHandler) GetListSubDistrict(res http.ResponseWriter, req *h
 
 	respData = &handlers.ResponseData{
 		Status:  cg.Success,
+		Source:  source,
 		Message: "success get list sub district",
 		Detail: general.ResponseData{
 			Data:       data,
@@ -13,9 +13,11 @@ import (
 	"github.com/furee/backend/handlers"
 	"github.com/furee/backend/usecase"
 	uu "github.com/furee/backend/usecase/order"
+	"github.com/furee/backend/utils"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/dealancer/validate.v2"
+	"gopkg.in/guregu/null.v4"
 )
 
 type OrderDataHandler struct {
@@ -37,23 +39,82 @@ func (ch OrderDataHandler) GetList(res http.ResponseWriter, req *http.Request) {
 		Status: cg.Fail,
 	}
 
-	message := ""
-	orders, err := ch.Usecase.GetList()
+	var filter du.OrderFilter
+	var err error
+
+	paginationData := general.GetPagination()
+
+	// Check customer name value
+	if req.FormValue("customer-name") != "" {
+		filter.CustomerName = null.StringFrom(req.FormValue("customer-name"))
+	}
+
+	// Check user id value
+	if req.FormValue("user-id") != "" {
+		userID, err := utils.StrToInt64(req.FormValue("user-id"))
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		filter.UserID = null.IntFrom(userID)
+	}
+
+	// Check sort value, format is field[:asc|desc] separated by comma
+	err = handlers.ReadSort(req, &paginationData)
 	if err != nil {
-		// if orders == nil {
-		// 	message = "No data found"
-		// }
-		message := err.Error()
+		respData.Message = err.Error()
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
 
-		respData.Message = message
+	// Check page value. If exist, convert to int
+	if req.FormValue("page") != "" {
+		paginationData.Page, err = strconv.Atoi(req.FormValue("page"))
+		if err != nil || paginationData.Page < 1 {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Check limit value. If exists, convert to int
+	if req.FormValue("limit") != "" {
+		paginationData.Limit, err = strconv.Atoi(req.FormValue("limit"))
+		if err != nil || paginationData.Limit < 1 {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Limit the page size, get all is limited as well
+	paginationData.ClampLimit(ch.conf.Route.MaxPageLimit, ch.conf.Route.MaxGetAllLimit)
+
+	// Convert page to offset
+	paginationData.SetOffset()
+
+	orders, paginationData, err := ch.Usecase.GetList(req.Context(), paginationData, filter)
+	if err != nil {
+		if general.IsSortError(err) {
+			respData.Message = err.Error()
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		respData.Message = "fail to get list order"
 		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
 		return
 	}
 
 	respData = &handlers.ResponseData{
 		Status:  cg.Success,
-		Message: message,
-		Detail:  orders,
+		Message: "success get list order",
+		Detail: general.ResponseData{
+			Data:       orders,
+			Pagination: paginationData,
+		},
 	}
 
 	handlers.WriteResponse(res, respData, http.StatusOK)
@@ -83,7 +144,7 @@ func (ch OrderDataHandler) GetByID(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	order, err := ch.Usecase.GetByID(orderid)
+	order, err := ch.Usecase.GetByID(req.Context(), orderid)
 
 	if err != nil {
 		// if order == nil {
@@ -145,7 +206,7 @@ func (ch OrderDataHandler) CreateOrder(res http.ResponseWriter, req *http.Reques
 	}
 
 	message := ""
-	orderId, err := ch.Usecase.CreateOrder(param)
+	orderId, err := ch.Usecase.CreateOrder(req.Context(), param)
 	if err != nil {
 		if orderId == 0 {
 			message = "fail to create order"
@@ -214,7 +275,7 @@ func (ch OrderDataHandler) UpdateOrder(res http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	updated, err := ch.Usecase.UpdateOrder(param)
+	updated, err := ch.Usecase.UpdateOrder(req.Context(), param)
 	if err != nil {
 		message = err.Error()
 
@@ -256,7 +317,7 @@ func (ch OrderDataHandler) DeleteByID(res http.ResponseWriter, req *http.Request
 		return
 	}
 
-	deleted, err := ch.Usecase.DeleteByID(orderid)
+	deleted, err := ch.Usecase.DeleteByID(req.Context(), orderid)
 
 	if err != nil {
 		message = err.Error()
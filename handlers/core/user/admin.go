@@ -0,0 +1,251 @@
+package user
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	cg "github.com/furee/backend/constants/general"
+	"github.com/furee/backend/domain/general"
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/utils"
+	"github.com/gorilla/mux"
+	"gopkg.in/dealancer/validate.v2"
+	"gopkg.in/guregu/null.v4"
+)
+
+func (ch UserDataHandler) GetListUser(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	var filter du.UserFilter
+	var err error
+
+	paginationData := general.GetPagination()
+
+	// Check name value
+	if req.FormValue("name") != "" {
+		filter.Name = null.StringFrom(req.FormValue("name"))
+	}
+
+	// Check status value
+	if req.FormValue("status") != "" {
+		status, err := utils.StrToInt64(req.FormValue("status"))
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		filter.Status = null.IntFrom(status)
+	}
+
+	// Check created range value, format is YYYY-MM-DD
+	if req.FormValue("created-from") != "" {
+		createdFrom, err := time.Parse("2006-01-02", req.FormValue("created-from"))
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		filter.CreatedFrom = null.TimeFrom(createdFrom)
+	}
+
+	if req.FormValue("created-to") != "" {
+		createdTo, err := time.Parse("2006-01-02", req.FormValue("created-to"))
+		if err != nil {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		// Include the whole day of created-to.
+		filter.CreatedTo = null.TimeFrom(createdTo.Add(cg.Time1Day - time.Nanosecond))
+	}
+
+	// Check sort value, format is field[:asc|desc] separated by comma
+	err = handlers.ReadSort(req, &paginationData)
+	if err != nil {
+		respData.Message = err.Error()
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	// Check page value. If exist, convert to int
+	if req.FormValue("page") != "" {
+		paginationData.Page, err = strconv.Atoi(req.FormValue("page"))
+		if err != nil || paginationData.Page < 1 {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Check limit value. If exists, convert to int
+	if req.FormValue("limit") != "" {
+		paginationData.Limit, err = strconv.Atoi(req.FormValue("limit"))
+		if err != nil || paginationData.Limit < 1 {
+			respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Limit the page size, get all is limited as well
+	paginationData.ClampLimit(ch.conf.Route.MaxPageLimit, ch.conf.Route.MaxGetAllLimit)
+
+	// Convert page to offset
+	paginationData.SetOffset()
+
+	data, paginationData, _, err := ch.Usecase.GetListUser(req.Context(), paginationData, filter)
+	if err != nil {
+		if general.IsSortError(err) {
+			respData.Message = err.Error()
+			handlers.WriteResponse(res, respData, http.StatusBadRequest)
+			return
+		}
+
+		respData.Message = "fail to get list user"
+		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: "success get list user",
+		Detail: general.ResponseData{
+			Data:       data,
+			Pagination: paginationData,
+		},
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (ch UserDataHandler) GetUserDetail(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	userID, err := utils.StrToInt64(mux.Vars(req)["userid"])
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	user, message, err := ch.Usecase.GetUserDetail(req.Context(), userID)
+	if err != nil {
+		code := http.StatusNotFound
+		if message == "" {
+			message = "fail to get user detail"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status: cg.Success,
+		Detail: user,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (ch UserDataHandler) UpdateUserStatus(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	userID, err := utils.StrToInt64(mux.Vars(req)["userid"])
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	var param du.UpdateStatusRequest
+
+	reqBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataEmpty
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = json.Unmarshal(reqBody, &param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataNotValid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = validate.Validate(param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	user, message, err := ch.Usecase.UpdateUserStatus(req.Context(), userID, param)
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to update user status"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: "success update user status",
+		Detail:  user,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (ch UserDataHandler) ForceLogout(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	userID, err := utils.StrToInt64(mux.Vars(req)["userid"])
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	message, err := ch.Usecase.ForceLogout(req.Context(), userID)
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to force logout user"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
@@ -7,11 +7,13 @@ import (
 )
 
 type UserHandler struct {
-	User UserDataHandler
+	User      UserDataHandler
+	Whitelist OTPWhitelistHandler
 }
 
 func NewHandler(uc usecase.Usecase, conf *general.SectionService, logger *logrus.Logger) UserHandler {
 	return UserHandler{
-		User: newUserHandler(uc, conf, logger),
+		User:      newUserHandler(uc, conf, logger),
+		Whitelist: newOTPWhitelistHandler(uc, conf, logger),
 	}
 }
@@ -0,0 +1,123 @@
+package user
+
+import (
+	"net/http"
+
+	cg "github.com/furee/backend/constants/general"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/utils"
+	"github.com/gorilla/mux"
+)
+
+func (ch UserDataHandler) RequestExport(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	job, message, err := ch.Usecase.RequestExport(req.Context())
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to request data export"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  job,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (ch UserDataHandler) RequestDeletion(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	job, message, err := ch.Usecase.RequestDeletion(req.Context())
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to request account deletion"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  job,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (ch UserDataHandler) CancelDeletion(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	message, err := ch.Usecase.CancelDeletion(req.Context())
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to cancel account deletion"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (ch UserDataHandler) GetJob(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	jobID, err := utils.StrToInt64(mux.Vars(req)["jobid"])
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	job, message, err := ch.Usecase.GetJob(req.Context(), jobID)
+	if err != nil {
+		code := http.StatusNotFound
+		if message == "" {
+			message = "fail to get job"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status: cg.Success,
+		Detail: job,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
@@ -57,14 +57,17 @@ func (ch UserDataHandler) VerifyOTP(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	jwt, message, err := ch.Usecase.VerifyOTP(param)
+	jwt, message, err := ch.Usecase.VerifyOTP(req.Context(), param)
 	if err != nil {
+		// message is only filled for error that need to be shown to the user.
+		code := http.StatusBadRequest
 		if message == "" {
 			message = "fail to verify otp user"
+			code = http.StatusInternalServerError
 		}
 
 		respData.Message = message
-		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
+		handlers.WriteResponse(res, respData, code)
 		return
 	}
 
@@ -106,14 +109,17 @@ func (ch UserDataHandler) LoginUser(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	message, err := ch.Usecase.LoginUser(param)
+	message, err := ch.Usecase.LoginUser(req.Context(), param)
 	if err != nil {
+		// message is only filled for error that need to be shown to the user.
+		code := http.StatusBadRequest
 		if message == "" {
 			message = "fail to send otp user"
+			code = http.StatusInternalServerError
 		}
 
 		respData.Message = message
-		handlers.WriteResponse(res, respData, http.StatusInternalServerError)
+		handlers.WriteResponse(res, respData, code)
 		return
 	}
 
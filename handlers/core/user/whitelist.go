@@ -0,0 +1,141 @@
+package user
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	cg "github.com/furee/backend/constants/general"
+	"github.com/furee/backend/domain/general"
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/handlers"
+	"github.com/furee/backend/usecase"
+	uu "github.com/furee/backend/usecase/user"
+	"github.com/furee/backend/utils"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/dealancer/validate.v2"
+)
+
+type OTPWhitelistHandler struct {
+	Usecase uu.OTPWhitelistUsecaseItf
+	conf    *general.SectionService
+	log     *logrus.Logger
+}
+
+func newOTPWhitelistHandler(uc usecase.Usecase, conf *general.SectionService, logger *logrus.Logger) OTPWhitelistHandler {
+	return OTPWhitelistHandler{
+		Usecase: uc.User.Whitelist,
+		conf:    conf,
+		log:     logger,
+	}
+}
+
+func (wh OTPWhitelistHandler) GetListWhitelist(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	list, message, err := wh.Usecase.GetListWhitelist(req.Context())
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to get whitelist"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status: cg.Success,
+		Detail: list,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (wh OTPWhitelistHandler) CreateWhitelist(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	var param du.OTPWhitelistRequest
+
+	reqBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataEmpty
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = json.Unmarshal(reqBody, &param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataNotValid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	err = validate.Validate(param)
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	whitelist, message, err := wh.Usecase.CreateWhitelist(req.Context(), param)
+	if err != nil {
+		code := http.StatusBadRequest
+		if message == "" {
+			message = "fail to add whitelist"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+		Detail:  whitelist,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
+
+func (wh OTPWhitelistHandler) DeleteWhitelist(res http.ResponseWriter, req *http.Request) {
+	respData := &handlers.ResponseData{
+		Status: cg.Fail,
+	}
+
+	whitelistID, err := utils.StrToInt64(mux.Vars(req)["whitelistid"])
+	if err != nil {
+		respData.Message = cg.HandlerErrorRequestDataFormatInvalid
+		handlers.WriteResponse(res, respData, http.StatusBadRequest)
+		return
+	}
+
+	message, err := wh.Usecase.DeleteWhitelist(req.Context(), whitelistID)
+	if err != nil {
+		code := http.StatusNotFound
+		if message == "" {
+			message = "fail to delete whitelist"
+			code = http.StatusInternalServerError
+		}
+
+		respData.Message = message
+		handlers.WriteResponse(res, respData, code)
+		return
+	}
+
+	respData = &handlers.ResponseData{
+		Status:  cg.Success,
+		Message: message,
+	}
+
+	handlers.WriteResponse(res, respData, http.StatusOK)
+}
@@ -2,10 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
+	"regexp"
+	"strings"
 
 	constants "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
 	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/utils"
 )
 
 type ResponseHTTP struct {
@@ -68,3 +73,148 @@ func (rd *ResponseData) GenerateErrorResponse(data *general.ResponseData, errorM
 	data.Error = errorMsg
 	rd.Detail = data
 }
+
+// GetClientIP return the caller IP. Service is deployed behind load balancer, the forwarded header
+// is only read when the request comes from the trusted proxy. The header is read from the right,
+// the first hop that is not a trusted proxy is the caller, the hop added by the caller itself is ignored.
+func GetClientIP(req *http.Request, trustedProxies []*net.IPNet) string {
+	remoteIP := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+
+	if !isTrustedProxy(remoteIP, trustedProxies) {
+		return remoteIP
+	}
+
+	if forwarded := req.Header.Values(constants.APIHeaderForwardedFor); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+
+			if i == 0 || !isTrustedProxy(hop, trustedProxies) {
+				return hop
+			}
+		}
+
+		return remoteIP
+	}
+
+	if realIP := strings.TrimSpace(req.Header.Get(constants.APIHeaderRealIP)); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	return remoteIP
+}
+
+func isTrustedProxy(value string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Max length of the device data that stored on the session.
+const (
+	deviceNameMaxLength = 100
+	userAgentMaxLength  = 255
+)
+
+// GetDeviceInfo return the device of the caller, the device name is sent by the app.
+func GetDeviceInfo(req *http.Request, trustedProxies []*net.IPNet) da.DeviceInfo {
+	return da.DeviceInfo{
+		Name:      truncate(strings.TrimSpace(req.Header.Get(constants.APIHeaderDeviceName)), deviceNameMaxLength),
+		UserAgent: truncate(req.Header.Get(constants.APIHeaderUserAgent), userAgentMaxLength),
+		IPAddress: GetClientIP(req, trustedProxies),
+	}
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+
+	return string(runes[:length])
+}
+
+// requestIDPattern limit the request id that accepted from the caller, otherwise a new one is generated.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9\-_.]{1,64}$`)
+
+// RequestContext store the request id & device of the caller on the request context,
+// so it can be recorded by the usecase. The request id is returned on the response header.
+func RequestContext(trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			requestID := req.Header.Get(constants.APIHeaderRequestID)
+			if !requestIDPattern.MatchString(requestID) {
+				requestID, _ = utils.GetUUID()
+			}
+
+			res.Header().Set(constants.APIHeaderRequestID, requestID)
+
+			ctx := da.WithRequestID(req.Context(), requestID)
+			ctx = da.WithDevice(ctx, GetDeviceInfo(req, trustedProxies))
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		})
+	}
+}
+
+// LimitBody limit the size of the request body, so the body that read before the authorization
+// cannot exhaust the memory. Reading more than the limit return an error.
+func LimitBody(maxBodySize, maxUploadSize int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			maxSize := maxBodySize
+			if strings.HasPrefix(req.Header.Get(constants.APIHeaderContentType), "multipart/") {
+				maxSize = maxUploadSize
+			}
+
+			if req.Body != nil {
+				req.Body = http.MaxBytesReader(res, req.Body, maxSize)
+			}
+
+			next.ServeHTTP(res, req)
+		})
+	}
+}
+
+// ReadSort set the sort of the list from the sort query, e.g. sort=name:asc,id:desc.
+// The legacy order-by query with sort=asc|desc is still accepted and converted to the same format.
+func ReadSort(req *http.Request, pagination *general.PaginationData) error {
+	sort := strings.TrimSpace(req.FormValue("sort"))
+	orderBy := strings.TrimSpace(req.FormValue("order-by"))
+
+	direction := strings.ToLower(sort)
+	if direction == general.SortAsc || direction == general.SortDesc {
+		if orderBy == "" {
+			orderBy = "id"
+		}
+
+		sort = orderBy + ":" + direction
+	} else if orderBy != "" {
+		if sort != "" {
+			sort = orderBy + "," + sort
+		} else {
+			sort = orderBy
+		}
+	}
+
+	if sort == "" {
+		return nil
+	}
+
+	return pagination.SetSort(sort)
+}
@@ -0,0 +1,180 @@
+package infra
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	constants "github.com/furee/backend/constants/general"
+	"github.com/go-redis/redis/v8"
+)
+
+const defaultCacheMaxEntries = 10000
+
+// Cache is key value cache with ttl.
+// Get return false when the key is not found or already expired.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// NewCache return redis cache when backend is redis, otherwise in-memory LRU cache that only valid
+// for single instance. Redis backend without the client is an error, the cache is not shared
+// between the instances when it falls back to memory.
+func NewCache(backend string, maxEntries int, client *redis.Client) (Cache, error) {
+	if backend == constants.CacheBackendRedis {
+		if client == nil {
+			return nil, errors.New("cache backend is redis but redis is not configured")
+		}
+
+		return &redisCache{
+			client: client,
+		}, nil
+	}
+
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+
+	return &memoryCache{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}, nil
+}
+
+// =================== MEMORY SECTION
+type cacheItem struct {
+	key       string
+	value     []byte
+	expiredAt time.Time
+}
+
+// memoryCache is LRU cache, the least recently used item is removed when the cache is full.
+type memoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List
+}
+
+func (mc *memoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	elem, ok := mc.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	item := elem.Value.(*cacheItem)
+	if time.Now().After(item.expiredAt) {
+		mc.remove(elem)
+		return nil, false, nil
+	}
+
+	mc.order.MoveToFront(elem)
+
+	return item.value, true, nil
+}
+
+func (mc *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	expiredAt := time.Now().Add(ttl)
+
+	if elem, ok := mc.items[key]; ok {
+		item := elem.Value.(*cacheItem)
+		item.value = value
+		item.expiredAt = expiredAt
+		mc.order.MoveToFront(elem)
+		return nil
+	}
+
+	mc.items[key] = mc.order.PushFront(&cacheItem{
+		key:       key,
+		value:     value,
+		expiredAt: expiredAt,
+	})
+
+	for mc.order.Len() > mc.maxEntries {
+		mc.remove(mc.order.Back())
+	}
+
+	return nil
+}
+
+func (mc *memoryCache) DeletePrefix(ctx context.Context, prefix string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for key, elem := range mc.items {
+		if strings.HasPrefix(key, prefix) {
+			mc.remove(elem)
+		}
+	}
+
+	return nil
+}
+
+func (mc *memoryCache) remove(elem *list.Element) {
+	mc.order.Remove(elem)
+	delete(mc.items, elem.Value.(*cacheItem).key)
+}
+
+// =================== REDIS SECTION
+type redisCache struct {
+	client *redis.Client
+}
+
+func (rc *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := rc.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (rc *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return rc.client.Set(ctx, key, value, ttl).Err()
+}
+
+// DeletePrefix use SCAN instead of KEYS so redis is not blocked.
+func (rc *redisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := rc.client.Scan(ctx, 0, prefix+"*", 100).Iterator()
+
+	keys := make([]string, 0)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+
+		if len(keys) >= 100 {
+			err := rc.client.Del(ctx, keys...).Err()
+			if err != nil {
+				return err
+			}
+
+			keys = keys[:0]
+		}
+	}
+
+	err := iter.Err()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		return rc.client.Del(ctx, keys...).Err()
+	}
+
+	return nil
+}
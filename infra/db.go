@@ -6,6 +6,7 @@ import (
 
 	constants "github.com/furee/backend/constants/general"
 	"github.com/furee/backend/domain/general"
+	"github.com/go-redis/redis/v8"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/jmoiron/sqlx"
@@ -33,7 +34,9 @@ type Database interface {
 }
 
 type DatabaseList struct {
-	Backend DatabaseType
+	Backend     DatabaseType
+	Redis       *redis.Client
+	NSQProducer *NSQProducer
 }
 
 type DatabaseType struct {
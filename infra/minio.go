@@ -3,11 +3,14 @@
 package infra
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
 	"mime/multipart"
+	"net/url"
 	"os"
+	"time"
 
 	"github.com/furee/backend/domain/general"
 	"github.com/furee/backend/utils"
@@ -92,6 +95,56 @@ func (m Minio) UploadMultiPartFile(access string, folderName string, file *multi
 	return location, nil
 }
 
+// UploadFile upload the content to the path on the bucket.
+func (m Minio) UploadFile(access, uploadPath, contentType string, data []byte) error {
+	_, err := m.client.PutObject(
+		context.Background(),
+		m.bucket,
+		uploadPath,
+		bytes.NewReader(data),
+		int64(len(data)),
+		minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: map[string]string{"x-amz-acl": access},
+		},
+	)
+
+	return err
+}
+
+// PresignedURL return the time limited link to download the private file.
+func (m Minio) PresignedURL(path string, expiry time.Duration) (string, error) {
+	link, err := m.client.PresignedGetObject(context.Background(), m.bucket, path, expiry, url.Values{})
+	if err != nil {
+		return "", err
+	}
+
+	return link.String(), nil
+}
+
+// DeleteFile remove the file on the path, no error when the file doesn't exist.
+func (m Minio) DeleteFile(path string) error {
+	return m.client.RemoveObject(context.Background(), m.bucket, path, minio.RemoveObjectOptions{})
+}
+
+// DeleteFolder remove every file under the prefix.
+func (m Minio) DeleteFolder(prefix string) error {
+	ctx := context.Background()
+
+	for object := range m.client.ListObjects(ctx, m.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return object.Err
+		}
+
+		err := m.client.RemoveObject(ctx, m.bucket, object.Key, minio.RemoveObjectOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 //Write multipart file into temporary folder
 func (m Minio) saveFiletoTempFolder(file *multipart.File, fileHeader *multipart.FileHeader) (string, error) {
 	var filePath string
@@ -0,0 +1,73 @@
+package infra
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	constants "github.com/furee/backend/constants/general"
+	"github.com/go-redis/redis/v8"
+)
+
+// NonceStore remember the used nonce until it is expired.
+// Use return false when the nonce already used.
+type NonceStore interface {
+	Use(key string, ttl time.Duration) (bool, error)
+}
+
+// NewNonceStore return redis nonce store when backend is redis & redis client is available,
+// otherwise in-memory nonce store that only valid for single instance.
+func NewNonceStore(backend string, client *redis.Client) NonceStore {
+	if backend == constants.NonceBackendRedis && client != nil {
+		return &redisNonceStore{
+			client: client,
+		}
+	}
+
+	return &memoryNonceStore{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// =================== MEMORY SECTION
+type memoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+func (mn *memoryNonceStore) Use(key string, ttl time.Duration) (bool, error) {
+	mn.mu.Lock()
+	defer mn.mu.Unlock()
+
+	now := time.Now()
+
+	// Remove expired nonce periodically so the map doesn't grow forever.
+	if now.Sub(mn.lastSweep) >= time.Minute {
+		for k, expiredAt := range mn.nonces {
+			if now.After(expiredAt) {
+				delete(mn.nonces, k)
+			}
+		}
+
+		mn.lastSweep = now
+	}
+
+	expiredAt, ok := mn.nonces[key]
+	if ok && now.Before(expiredAt) {
+		return false, nil
+	}
+
+	mn.nonces[key] = now.Add(ttl)
+
+	return true, nil
+}
+
+// =================== REDIS SECTION
+type redisNonceStore struct {
+	client *redis.Client
+}
+
+func (rn *redisNonceStore) Use(key string, ttl time.Duration) (bool, error) {
+	return rn.client.SetNX(context.Background(), "nonce:"+key, 1, ttl).Result()
+}
@@ -0,0 +1,307 @@
+package infra
+
+import (
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	constants "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/utils"
+)
+
+const (
+	// oidcCacheDuration is how long the discovery & JWKS of the provider is reused.
+	oidcCacheDuration = time.Hour
+	// oidcKeyRefreshInterval limit the JWKS reload when the ID token use unknown kid.
+	oidcKeyRefreshInterval = time.Minute
+)
+
+// OIDCProvider is the client of the OpenID Connect identity provider.
+// Discovery & JWKS are loaded lazily and cached, so the service can start while the provider is down.
+type OIDCProvider struct {
+	conf   general.OIDCCredential
+	client *http.Client
+
+	mu          *sync.RWMutex
+	discovery   *da.OIDCDiscovery
+	keys        map[string]*rsa.PublicKey
+	loadedAt    time.Time
+	keyLoadedAt time.Time
+}
+
+func NewOIDCProvider(conf general.OIDCCredential) *OIDCProvider {
+	return &OIDCProvider{
+		conf:   conf,
+		client: &http.Client{Timeout: constants.APITimeDuration10s},
+		mu:     &sync.RWMutex{},
+		keys:   make(map[string]*rsa.PublicKey),
+	}
+}
+
+// AuthCodeURL return the authorization endpoint that the staff is redirected to, PKCE is always used.
+func (op *OIDCProvider) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	discovery, err := op.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	param := url.Values{}
+	param.Set("response_type", "code")
+	param.Set("client_id", op.conf.ClientID)
+	param.Set("redirect_uri", op.conf.RedirectURL)
+	param.Set("scope", strings.Join(op.conf.Scopes, " "))
+	param.Set("state", state)
+	param.Set("nonce", nonce)
+	param.Set("code_challenge", codeChallenge)
+	param.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + param.Encode(), nil
+}
+
+// Exchange trade the authorization code with the token, the code verifier prove the same client started the login.
+func (op *OIDCProvider) Exchange(code, codeVerifier string) (*da.OIDCTokenResponse, error) {
+	discovery, err := op.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	param := url.Values{}
+	param.Set("grant_type", "authorization_code")
+	param.Set("code", code)
+	param.Set("redirect_uri", op.conf.RedirectURL)
+	param.Set("client_id", op.conf.ClientID)
+	param.Set("client_secret", op.conf.ClientSecret)
+	param.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(param.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(constants.APIHeaderContentType, constants.APIHeaderContentTypeFormURLEncoded)
+
+	var res da.OIDCTokenResponse
+	err = op.do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.IDToken == "" {
+		return nil, errors.New("id token not found on token response")
+	}
+
+	return &res, nil
+}
+
+// VerifyIDToken check the signature of the ID token against the provider JWKS,
+// then the issuer, audience, expiry & nonce of the login.
+func (op *OIDCProvider) VerifyIDToken(rawToken, nonce string) (*da.OIDCIdentity, error) {
+	discovery, err := op.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse(rawToken, op.verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("id token not valid")
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("id token doesn't have expiry")
+	}
+
+	if utils.GetClaimString(claims, "iss") != discovery.Issuer {
+		return nil, errors.New("id token issuer not valid")
+	}
+
+	if !op.isAudience(claims) {
+		return nil, errors.New("id token audience not valid")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.GetClaimString(claims, "nonce")), []byte(nonce)) != 1 {
+		return nil, errors.New("id token nonce not valid")
+	}
+
+	identity := &da.OIDCIdentity{
+		Subject:       utils.GetClaimString(claims, "sub"),
+		Email:         strings.ToLower(utils.GetClaimString(claims, "email")),
+		EmailVerified: utils.GetClaimBool(claims, "email_verified") || utils.GetClaimString(claims, "email_verified") == "true",
+		Groups:        utils.GetClaimStrings(claims, op.conf.GroupClaim),
+	}
+
+	// Some provider send single group as string.
+	if identity.Groups == nil && utils.GetClaimString(claims, op.conf.GroupClaim) != "" {
+		identity.Groups = []string{utils.GetClaimString(claims, op.conf.GroupClaim)}
+	}
+
+	return identity, nil
+}
+
+func (op *OIDCProvider) isAudience(claims jwt.MapClaims) bool {
+	if aud := utils.GetClaimString(claims, "aud"); aud != "" {
+		return aud == op.conf.ClientID
+	}
+
+	for _, aud := range utils.GetClaimStrings(claims, "aud") {
+		if aud == op.conf.ClientID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verificationKey only accept RS256, the JWKS is reloaded once when the kid is unknown because the provider rotated the key.
+func (op *OIDCProvider) verificationKey(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+		return nil, fmt.Errorf("signing method %s not supported", token.Method.Alg())
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	op.mu.RLock()
+	key, ok := op.keys[kid]
+	isExpired := time.Since(op.keyLoadedAt) > oidcCacheDuration
+	canRefresh := time.Since(op.keyLoadedAt) > oidcKeyRefreshInterval
+	op.mu.RUnlock()
+
+	if ok && !isExpired {
+		return key, nil
+	}
+
+	if !canRefresh {
+		return nil, fmt.Errorf("kid %s unknown", kid)
+	}
+
+	err := op.loadKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	op.mu.RLock()
+	key, ok = op.keys[kid]
+	op.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("kid %s unknown", kid)
+	}
+
+	return key, nil
+}
+
+func (op *OIDCProvider) getDiscovery() (*da.OIDCDiscovery, error) {
+	op.mu.RLock()
+	discovery := op.discovery
+	loadedAt := op.loadedAt
+	op.mu.RUnlock()
+
+	if discovery != nil && time.Since(loadedAt) < oidcCacheDuration {
+		return discovery, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(op.conf.IssuerURL, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res da.OIDCDiscovery
+	err = op.do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.AuthorizationEndpoint == "" || res.TokenEndpoint == "" || res.JWKSURI == "" {
+		return nil, errors.New("discovery of the provider is not complete")
+	}
+
+	// The issuer of the discovery must be the configured issuer, otherwise the ID token of other provider is accepted.
+	if strings.TrimSuffix(res.Issuer, "/") != strings.TrimSuffix(op.conf.IssuerURL, "/") {
+		return nil, fmt.Errorf("discovery issuer %s is not the configured issuer", res.Issuer)
+	}
+
+	op.mu.Lock()
+	op.discovery = &res
+	op.loadedAt = time.Now()
+	op.mu.Unlock()
+
+	return &res, nil
+}
+
+func (op *OIDCProvider) loadKeys() error {
+	discovery, err := op.getDiscovery()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, discovery.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+
+	var res general.JWKS
+	err = op.do(req, &res)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range res.Keys {
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		key, err := utils.ParseRSAJWK(jwk)
+		if err != nil {
+			return err
+		}
+
+		keys[jwk.KeyID] = key
+	}
+
+	op.mu.Lock()
+	op.keys = keys
+	op.keyLoadedAt = time.Now()
+	op.mu.Unlock()
+
+	return nil
+}
+
+func (op *OIDCProvider) do(req *http.Request, dest interface{}) error {
+	resp, err := op.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("identity provider return status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return json.Unmarshal(respBody, dest)
+}
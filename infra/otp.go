@@ -0,0 +1,182 @@
+package infra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	constants "github.com/furee/backend/constants/general"
+	"github.com/furee/backend/domain/external/api/otp"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/utils/phone"
+	"github.com/sirupsen/logrus"
+)
+
+// OTPSender deliver the OTP code to the user phone.
+// Send return the raw provider response so every delivery attempt can be recorded.
+type OTPSender interface {
+	Channel() string
+	Send(phone, otpCode string) (string, error)
+}
+
+// NewOTPSenders create the OTP senders in the order of the configured channels.
+// Log sender is only used on the known non production environment.
+func NewOTPSenders(conf *general.SectionService, logger *logrus.Logger) []OTPSender {
+	senders := make([]OTPSender, 0)
+
+	for _, channel := range conf.Authorization.OTP.Channels {
+		switch channel {
+		case constants.OTPChannelWhatsapp:
+			senders = append(senders, newMessageBirdWhatsapp(conf.PartnerSecret.MessageBird))
+		case constants.OTPChannelSMS:
+			senders = append(senders, newMessageBirdSMS(conf.PartnerSecret.MessageBird))
+		case constants.OTPChannelLog:
+			if !conf.App.IsNonProd() {
+				logger.Warn("log otp sender is not allowed on production, skip")
+				continue
+			}
+
+			senders = append(senders, newLogOTPSender(logger, conf.Authorization.OTP.LogCode))
+		case "":
+			continue
+		default:
+			logger.Warnf("unknown otp channel %s, skip", channel)
+		}
+	}
+
+	if len(senders) == 0 {
+		logger.Error("no otp sender configured")
+	}
+
+	return senders
+}
+
+// =================== MESSAGEBIRD WHATSAPP SECTION
+type messageBirdWhatsapp struct {
+	cred   general.MessageBirdCredential
+	client *http.Client
+}
+
+func newMessageBirdWhatsapp(cred general.MessageBirdCredential) messageBirdWhatsapp {
+	return messageBirdWhatsapp{
+		cred:   cred,
+		client: &http.Client{Timeout: constants.APITimeDuration10s},
+	}
+}
+
+func (mw messageBirdWhatsapp) Channel() string {
+	return constants.OTPChannelWhatsapp
+}
+
+func (mw messageBirdWhatsapp) Send(phone, otpCode string) (string, error) {
+	payload := otp.MessageBirdWhatsappAPI{
+		DestinationNumber: phone,
+		Origin:            mw.cred.FromID,
+		Type:              mw.cred.Type,
+		Content: otp.MessageBirdWhatsappOTPContent{
+			HSM: otp.MessageBirdWhatsappOTPHSM{
+				Namespace:    mw.cred.NameSpace,
+				TemplateName: mw.cred.TemplateName,
+				Language: otp.MessageBirdWhatsappOTPLanguage{
+					Code: mw.cred.LanguageCode,
+				},
+				Params: []otp.MessageBirdWhatsappOTPParams{
+					{Default: otpCode},
+				},
+			},
+		},
+	}
+
+	return postMessageBird(mw.client, mw.cred.WhatsappOTPURL, mw.cred.Authorization, payload)
+}
+
+// =================== MESSAGEBIRD SMS SECTION
+type messageBirdSMS struct {
+	cred   general.MessageBirdCredential
+	client *http.Client
+}
+
+func newMessageBirdSMS(cred general.MessageBirdCredential) messageBirdSMS {
+	return messageBirdSMS{
+		cred:   cred,
+		client: &http.Client{Timeout: constants.APITimeDuration10s},
+	}
+}
+
+func (ms messageBirdSMS) Channel() string {
+	return constants.OTPChannelSMS
+}
+
+func (ms messageBirdSMS) Send(phone, otpCode string) (string, error) {
+	payload := otp.MessageBirdSMSAPI{
+		Originator: ms.cred.SMSOriginator,
+		Recipients: []string{phone},
+		Body:       fmt.Sprintf(ms.cred.SMSTemplate, otpCode),
+	}
+
+	return postMessageBird(ms.client, ms.cred.SMSURL, ms.cred.Authorization, payload)
+}
+
+func postMessageBird(client *http.Client, url, authorization string, payload interface{}) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set(constants.APIHeaderContentType, constants.APIHeaderContentTypeJSon)
+	req.Header.Set(constants.APIHeaderAuthorization, authorization)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return string(respBody), fmt.Errorf("messagebird return status %d", resp.StatusCode)
+	}
+
+	return string(respBody), nil
+}
+
+// =================== LOG SECTION
+// logOTPSender only write the OTP to the log, used for local & test environment.
+// The phone is masked & the code is only written when logCode is enabled.
+type logOTPSender struct {
+	log     *logrus.Logger
+	logCode bool
+}
+
+func newLogOTPSender(logger *logrus.Logger, logCode bool) logOTPSender {
+	return logOTPSender{
+		log:     logger,
+		logCode: logCode,
+	}
+}
+
+func (ls logOTPSender) Channel() string {
+	return constants.OTPChannelLog
+}
+
+func (ls logOTPSender) Send(number, otpCode string) (string, error) {
+	log := ls.log.WithField("phone", phone.Mask(number))
+	if !ls.logCode {
+		log.Info("otp code is sent to the log channel, set LOG_CODE to show the code")
+		return "logged", nil
+	}
+
+	log.Infof("otp code: %s", otpCode)
+	return "logged", nil
+}
@@ -0,0 +1,195 @@
+package infra
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	constants "github.com/furee/backend/constants/general"
+	"github.com/furee/backend/domain/general"
+	"github.com/go-redis/redis/v8"
+)
+
+// RateLimiter is token bucket rate limiter.
+// Allow take one token from the bucket of every key, the token is only taken when all of the bucket
+// have one. When one of the bucket is empty it return the duration until the token is available on all of them.
+type RateLimiter interface {
+	Allow(keys []string, rule general.RateLimitRule) (bool, time.Duration, error)
+}
+
+// NewRateLimiter return redis rate limiter when backend is redis & redis client is available,
+// otherwise in-memory rate limiter that only valid for single instance.
+func NewRateLimiter(backend string, client *redis.Client) RateLimiter {
+	if backend == constants.RateLimitBackendRedis && client != nil {
+		return &redisRateLimiter{
+			client: client,
+		}
+	}
+
+	return &memoryRateLimiter{
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// =================== MEMORY SECTION
+type tokenBucket struct {
+	tokens     int
+	lastRefill time.Time
+	expiredAt  time.Time
+}
+
+type memoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func (ml *memoryRateLimiter) Allow(keys []string, rule general.RateLimitRule) (bool, time.Duration, error) {
+	return ml.allow(keys, rule, time.Now())
+}
+
+func (ml *memoryRateLimiter) allow(keys []string, rule general.RateLimitRule, now time.Time) (bool, time.Duration, error) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	interval := rule.Interval()
+
+	ml.sweep(now)
+
+	buckets := make([]*tokenBucket, 0, len(keys))
+	wait := time.Duration(0)
+	for _, key := range keys {
+		bucket, ok := ml.buckets[key]
+		if !ok {
+			bucket = &tokenBucket{
+				tokens:     rule.Capacity,
+				lastRefill: now,
+			}
+			ml.buckets[key] = bucket
+		}
+
+		bucket.refill(rule, now)
+
+		if bucket.tokens <= 0 {
+			if bucketWait := interval - now.Sub(bucket.lastRefill); bucketWait > wait {
+				wait = bucketWait
+			}
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	if wait > 0 {
+		return false, wait, nil
+	}
+
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+
+	return true, 0, nil
+}
+
+// refill add the token that already earned since last refill.
+func (tb *tokenBucket) refill(rule general.RateLimitRule, now time.Time) {
+	interval := rule.Interval()
+
+	refill := int(now.Sub(tb.lastRefill) / interval)
+	if refill > 0 {
+		tb.tokens += refill
+		tb.lastRefill = tb.lastRefill.Add(time.Duration(refill) * interval)
+	}
+
+	if tb.tokens >= rule.Capacity {
+		tb.tokens = rule.Capacity
+		tb.lastRefill = now
+	}
+
+	tb.expiredAt = now.Add(time.Duration(rule.Capacity) * interval)
+}
+
+// sweep remove the bucket that already full again, run at most once a minute.
+func (ml *memoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(ml.lastSweep) < constants.Time1Min {
+		return
+	}
+
+	for key, bucket := range ml.buckets {
+		if now.After(bucket.expiredAt) {
+			delete(ml.buckets, key)
+		}
+	}
+
+	ml.lastSweep = now
+}
+
+// =================== REDIS SECTION
+// rateLimitScript refill all of the bucket, then take the token atomically when every bucket has one.
+// KEYS bucket keys, ARGV[1] capacity, ARGV[2] refill interval in ms, ARGV[3] now in ms.
+var rateLimitScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local buckets = {}
+local wait = 0
+for i, key in ipairs(KEYS) do
+	local data = redis.call('HMGET', key, 'tokens', 'ts')
+	local tokens = tonumber(data[1])
+	local ts = tonumber(data[2])
+	if tokens == nil or ts == nil then
+		tokens = capacity
+		ts = now
+	end
+
+	local refill = math.floor((now - ts) / interval)
+	if refill > 0 then
+		tokens = tokens + refill
+		ts = ts + refill * interval
+	end
+
+	if tokens >= capacity then
+		tokens = capacity
+		ts = now
+	end
+
+	if tokens <= 0 then
+		wait = math.max(wait, interval - (now - ts))
+	end
+
+	buckets[i] = {tokens, ts}
+end
+
+local allowed = 0
+if wait == 0 then
+	allowed = 1
+end
+
+for i, key in ipairs(KEYS) do
+	local tokens = buckets[i][1]
+	if allowed == 1 then
+		tokens = tokens - 1
+	end
+
+	redis.call('HMSET', key, 'tokens', tokens, 'ts', buckets[i][2])
+	redis.call('PEXPIRE', key, capacity * interval)
+end
+
+return {allowed, wait}
+`)
+
+type redisRateLimiter struct {
+	client *redis.Client
+}
+
+func (rl *redisRateLimiter) Allow(keys []string, rule general.RateLimitRule) (bool, time.Duration, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	interval := rule.Interval().Milliseconds()
+
+	res, err := rateLimitScript.Run(context.Background(), rl.client, keys, rule.Capacity, interval, now).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,67 @@
+package infra
+
+import (
+	"testing"
+	"time"
+
+	constants "github.com/furee/backend/constants/general"
+	"github.com/furee/backend/domain/general"
+)
+
+type rateLimitStep struct {
+	at          time.Duration
+	keys        []string
+	wantAllowed bool
+	wantWait    time.Duration
+}
+
+func runRateLimitSteps(t *testing.T, rule general.RateLimitRule, steps []rateLimitStep) {
+	t.Helper()
+
+	ml := NewRateLimiter(constants.RateLimitBackendMemory, nil).(*memoryRateLimiter)
+	start := time.Now()
+
+	for i, step := range steps {
+		allowed, wait, err := ml.allow(step.keys, rule, start.Add(step.at))
+		if err != nil {
+			t.Fatalf("step %d: allow error = %v", i, err)
+		}
+
+		if allowed != step.wantAllowed || wait != step.wantWait {
+			t.Errorf("step %d: allow %v at %s = (%v, %s), want (%v, %s)", i, step.keys, step.at, allowed, wait, step.wantAllowed, step.wantWait)
+		}
+	}
+}
+
+func TestMemoryRateLimiterRefill(t *testing.T) {
+	rule := general.RateLimitRule{Capacity: 2, RefillInterval: 10}
+	key := []string{"a"}
+
+	runRateLimitSteps(t, rule, []rateLimitStep{
+		{at: 0, keys: key, wantAllowed: true},
+		{at: 0, keys: key, wantAllowed: true},
+		{at: 0, keys: key, wantAllowed: false, wantWait: 10 * time.Second},
+		{at: 4 * time.Second, keys: key, wantAllowed: false, wantWait: 6 * time.Second},
+		{at: 10 * time.Second, keys: key, wantAllowed: true},
+		{at: 10 * time.Second, keys: key, wantAllowed: false, wantWait: 10 * time.Second},
+		// The refill is capped at the capacity.
+		{at: 45 * time.Second, keys: key, wantAllowed: true},
+		{at: 45 * time.Second, keys: key, wantAllowed: true},
+		{at: 45 * time.Second, keys: key, wantAllowed: false, wantWait: 10 * time.Second},
+	})
+}
+
+func TestMemoryRateLimiterAllBuckets(t *testing.T) {
+	rule := general.RateLimitRule{Capacity: 1, RefillInterval: 10}
+
+	runRateLimitSteps(t, rule, []rateLimitStep{
+		{at: 0, keys: []string{"ip"}, wantAllowed: true},
+		// The phone bucket is not taken when the ip bucket deny the request.
+		{at: 0, keys: []string{"ip", "phone"}, wantAllowed: false, wantWait: 10 * time.Second},
+		{at: 0, keys: []string{"phone"}, wantAllowed: true},
+		{at: 3 * time.Second, keys: []string{"ip", "phone"}, wantAllowed: false, wantWait: 7 * time.Second},
+		{at: 10 * time.Second, keys: []string{"ip", "phone"}, wantAllowed: true},
+		{at: 10 * time.Second, keys: []string{"ip"}, wantAllowed: false, wantWait: 10 * time.Second},
+		{at: 10 * time.Second, keys: []string{"phone"}, wantAllowed: false, wantWait: 10 * time.Second},
+	})
+}
@@ -0,0 +1,38 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	constants "github.com/furee/backend/constants/general"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/utils"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+// NewRedis create redis client & make sure the connection is reachable.
+func NewRedis(conf general.RedisAccount, logger *logrus.Logger) (*redis.Client, error) {
+	timeout := time.Duration(utils.GetInt(conf.Timeout)) * time.Second
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         fmt.Sprintf("%s:%d", conf.URL, conf.Port),
+		Username:     conf.Username,
+		Password:     conf.Password,
+		MinIdleConns: conf.MinIdleConns,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+	})
+
+	err := client.Ping(context.Background()).Err()
+	if err != nil {
+		logger.Error(constants.ConnectRedisFail, " | ", err.Error())
+		return nil, err
+	}
+
+	logger.Info(constants.ConnectRedisSuccess)
+
+	return client, nil
+}
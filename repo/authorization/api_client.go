@@ -0,0 +1,97 @@
+package authorization
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/infra"
+)
+
+type APIClientRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newAPIClientRepo(dbList *infra.DatabaseList) APIClientRepo {
+	return APIClientRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	acqSelectClient = `
+	SELECT
+		client_id,
+		name,
+		secret,
+		allowed_routes,
+		is_active
+	FROM
+		api_clients`
+
+	acqInsertClient = `
+	INSERT INTO api_clients (
+		client_id,
+		name,
+		secret,
+		allowed_routes,
+		is_active,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?, ?
+	)`
+
+	acqWhere = `
+	WHERE`
+
+	acqFilterClientID = `
+		client_id = ?`
+)
+
+type APIClientRepoItf interface {
+	GetByID(clientID string) (*da.APIClient, error)
+	InsertClient(tx *sql.Tx, data da.APIClient) error
+}
+
+func (ar APIClientRepo) GetByID(clientID string) (*da.APIClient, error) {
+	var res da.APIClient
+
+	q := fmt.Sprintf("%s%s%s", acqSelectClient, acqWhere, acqFilterClientID)
+	query, args, err := ar.DBList.Backend.Read.In(q, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = ar.DBList.Backend.Read.Rebind(query)
+	err = ar.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (ar APIClientRepo) InsertClient(tx *sql.Tx, data da.APIClient) error {
+	query, args, err := ar.DBList.Backend.Write.In(acqInsertClient, data.ID, data.Name, data.Secret, data.AllowedRoutes, data.IsActive, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	query = ar.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = ar.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
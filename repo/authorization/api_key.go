@@ -0,0 +1,209 @@
+package authorization
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/infra"
+)
+
+type APIKeyRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newAPIKeyRepo(dbList *infra.DatabaseList) APIKeyRepo {
+	return APIKeyRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	akqSelectAPIKey = `
+	SELECT
+		api_key_id,
+		name,
+		prefix,
+		secret_hash,
+		scopes,
+		allowed_ips,
+		expired_at,
+		last_used_at,
+		last_used_ip,
+		revoked_at,
+		created_by,
+		created_at
+	FROM
+		api_keys`
+
+	akqInsertAPIKey = `
+	INSERT INTO api_keys (
+		name,
+		prefix,
+		secret_hash,
+		scopes,
+		allowed_ips,
+		expired_at,
+		created_by,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?, ?, ?, ?
+	) RETURNING api_key_id`
+
+	akqUpdateAPIKey = `
+	UPDATE
+		api_keys
+	SET`
+
+	akqSetLastUsed = `
+		last_used_at = ?,
+		last_used_ip = ?`
+
+	akqSetRevoked = `
+		revoked_at = NOW()`
+
+	akqWhere = `
+	WHERE`
+
+	akqFilterAPIKeyID = `
+		api_key_id = ?`
+
+	akqFilterPrefix = `
+		prefix = ?`
+
+	akqFilterNotRevoked = `
+		revoked_at IS NULL`
+
+	akqOrderByCreated = `
+	ORDER BY created_at DESC`
+)
+
+type APIKeyRepoItf interface {
+	GetByID(apiKeyID int64) (*da.APIKey, error)
+	GetByPrefix(prefix string) (*da.APIKey, error)
+	GetList() ([]da.APIKey, error)
+	InsertAPIKey(tx *sql.Tx, data da.APIKey) (int64, error)
+	UpdateLastUsed(apiKeyID int64, lastUsedAt time.Time, ip string) error
+	RevokeAPIKey(tx *sql.Tx, apiKeyID int64) error
+}
+
+func (ar APIKeyRepo) GetByID(apiKeyID int64) (*da.APIKey, error) {
+	q := fmt.Sprintf("%s%s%s", akqSelectAPIKey, akqWhere, akqFilterAPIKeyID)
+	return ar.get(q, apiKeyID)
+}
+
+func (ar APIKeyRepo) GetByPrefix(prefix string) (*da.APIKey, error) {
+	q := fmt.Sprintf("%s%s%s", akqSelectAPIKey, akqWhere, akqFilterPrefix)
+	return ar.get(q, prefix)
+}
+
+func (ar APIKeyRepo) GetList() ([]da.APIKey, error) {
+	res := make([]da.APIKey, 0)
+
+	query := ar.DBList.Backend.Read.Rebind(akqSelectAPIKey + akqOrderByCreated)
+	err := ar.DBList.Backend.Read.Select(&res, query)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (ar APIKeyRepo) InsertAPIKey(tx *sql.Tx, data da.APIKey) (int64, error) {
+	param := make([]interface{}, 0)
+
+	param = append(param, data.Name)
+	param = append(param, data.Prefix)
+	param = append(param, data.SecretHash)
+	param = append(param, data.Scopes)
+	param = append(param, data.AllowedIPs)
+	param = append(param, data.ExpiredAt)
+	param = append(param, data.CreatedBy)
+	param = append(param, data.CreatedAt)
+
+	query, args, err := ar.DBList.Backend.Write.In(akqInsertAPIKey, param...)
+	if err != nil {
+		return 0, err
+	}
+
+	query = ar.DBList.Backend.Write.Rebind(query)
+
+	var res *sql.Row
+	if tx == nil {
+		res = ar.DBList.Backend.Write.QueryRow(query, args...)
+	} else {
+		res = tx.QueryRow(query, args...)
+	}
+
+	var apiKeyID int64
+	err = res.Scan(&apiKeyID)
+	if err != nil {
+		return 0, err
+	}
+
+	return apiKeyID, nil
+}
+
+func (ar APIKeyRepo) UpdateLastUsed(apiKeyID int64, lastUsedAt time.Time, ip string) error {
+	q := fmt.Sprintf("%s%s %s%s", akqUpdateAPIKey, akqSetLastUsed, akqWhere, akqFilterAPIKeyID)
+	return ar.exec(nil, q, lastUsedAt, ip, apiKeyID)
+}
+
+// RevokeAPIKey return sql.ErrNoRows when the key is not exist or already revoked.
+func (ar APIKeyRepo) RevokeAPIKey(tx *sql.Tx, apiKeyID int64) error {
+	q := fmt.Sprintf("%s%s %s%s AND %s", akqUpdateAPIKey, akqSetRevoked, akqWhere, akqFilterAPIKeyID, akqFilterNotRevoked)
+	return ar.exec(tx, q, apiKeyID)
+}
+
+func (ar APIKeyRepo) get(q string, param ...interface{}) (*da.APIKey, error) {
+	var res da.APIKey
+
+	query, args, err := ar.DBList.Backend.Read.In(q, param...)
+	if err != nil {
+		return nil, err
+	}
+
+	query = ar.DBList.Backend.Read.Rebind(query)
+	err = ar.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (ar APIKeyRepo) exec(tx *sql.Tx, q string, param ...interface{}) error {
+	query, args, err := ar.DBList.Backend.Write.In(q, param...)
+	if err != nil {
+		return err
+	}
+
+	query = ar.DBList.Backend.Write.Rebind(query)
+
+	var res sql.Result
+	if tx == nil {
+		res, err = ar.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		res, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package authorization
+
+import (
+	"database/sql"
+	"time"
+
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/infra"
+)
+
+type AuditLogRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newAuditLogRepo(dbList *infra.DatabaseList) AuditLogRepo {
+	return AuditLogRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	alqInsertAuditLog = `
+	INSERT INTO audit_logs (
+		actor_id,
+		action,
+		entity_type,
+		entity_id,
+		detail,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?, ?
+	)`
+)
+
+type AuditLogRepoItf interface {
+	InsertAuditLog(tx *sql.Tx, data da.AuditLog) error
+}
+
+func (ar AuditLogRepo) InsertAuditLog(tx *sql.Tx, data da.AuditLog) error {
+	param := make([]interface{}, 0)
+
+	param = append(param, data.ActorID)
+	param = append(param, data.Action)
+	param = append(param, data.EntityType)
+	param = append(param, data.EntityID)
+	param = append(param, data.Detail)
+	param = append(param, time.Now().UTC())
+
+	query, args, err := ar.DBList.Backend.Write.In(alqInsertAuditLog, param...)
+	if err != nil {
+		return err
+	}
+
+	query = ar.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = ar.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package authorization
+
+import (
+	"database/sql"
+	"strings"
+
+	da "github.com/furee/backend/domain/authorization"
+	dg "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+)
+
+type AuthEventRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newAuthEventRepo(dbList *infra.DatabaseList) AuthEventRepo {
+	return AuthEventRepo{
+		DBList: dbList,
+	}
+}
+
+// auth_events is append-only, the only update is the redaction of the deleted user.
+const (
+	aeqSelectAuthEvent = `
+	SELECT
+		auth_event_id,
+		event_type,
+		user_id,
+		phone_hash,
+		ip_address,
+		user_agent,
+		request_id,
+		detail,
+		created_at
+	FROM
+		auth_events`
+
+	aeqCountAuthEvent = `
+	SELECT
+		COUNT(auth_event_id)
+	FROM
+		auth_events`
+
+	aeqInsertAuthEvent = `
+	INSERT INTO auth_events (
+		event_type,
+		user_id,
+		phone_hash,
+		ip_address,
+		user_agent,
+		request_id,
+		detail,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?, ?, ?, ?
+	) RETURNING auth_event_id`
+
+	aeqRedactAuthEvent = `
+	UPDATE
+		auth_events
+	SET
+		phone_hash = NULL,
+		ip_address = '',
+		user_agent = ''
+	WHERE
+		user_id = ? OR phone_hash = ?`
+
+	aeqWhere = `
+	WHERE`
+
+	aeqFilterUserID = `
+		user_id = ?`
+
+	aeqFilterEventType = `
+		event_type = ?`
+
+	aeqFilterPhoneHash = `
+		phone_hash = ?`
+
+	aeqFilterIPAddress = `
+		ip_address = ?`
+
+	aeqFilterRequestID = `
+		request_id = ?`
+
+	aeqFilterCreatedFrom = `
+		created_at >= ?`
+
+	aeqFilterCreatedTo = `
+		created_at <= ?`
+
+	aeqOrderBy = `
+	ORDER BY created_at DESC, auth_event_id DESC`
+
+	aeqLimitOffset = `
+	LIMIT ? OFFSET ?`
+)
+
+type AuthEventRepoItf interface {
+	GetListAuthEvent(pagination dg.PaginationData, filter da.AuthEventFilter) ([]da.AuthEvent, error)
+	GetTotalAuthEvent(pagination dg.PaginationData, filter da.AuthEventFilter) (int64, int64, error)
+	InsertAuthEvent(data da.AuthEvent) (int64, error)
+	RedactByUser(tx *sql.Tx, userID int64, phoneHash string) error
+}
+
+func (ar AuthEventRepo) GetListAuthEvent(pagination dg.PaginationData, filter da.AuthEventFilter) ([]da.AuthEvent, error) {
+	var result []da.AuthEvent
+
+	fl, param := ar.buildFilter(filter)
+
+	q := aeqSelectAuthEvent
+
+	if len(fl) > 0 {
+		q += aeqWhere + strings.Join(fl, " AND ")
+	}
+
+	q += aeqOrderBy
+
+	if !pagination.IsGetAll {
+		q += aeqLimitOffset
+		param = append(param, pagination.Limit)
+		param = append(param, pagination.Offset)
+	}
+
+	query, args, err := ar.DBList.Backend.Read.In(q, param...)
+	if err != nil {
+		return result, err
+	}
+
+	query = ar.DBList.Backend.Read.Rebind(query)
+	err = ar.DBList.Backend.Read.Select(&result, query, args...)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (ar AuthEventRepo) GetTotalAuthEvent(pagination dg.PaginationData, filter da.AuthEventFilter) (int64, int64, error) {
+	var result int64
+
+	fl, param := ar.buildFilter(filter)
+
+	q := aeqCountAuthEvent
+
+	if len(fl) > 0 {
+		q += aeqWhere + strings.Join(fl, " AND ")
+	}
+
+	query, args, err := ar.DBList.Backend.Read.In(q, param...)
+	if err != nil {
+		return result, 0, err
+	}
+
+	query = ar.DBList.Backend.Read.Rebind(query)
+	err = ar.DBList.Backend.Read.Get(&result, query, args...)
+	if err != nil {
+		return result, 0, err
+	}
+
+	totalPage := result / int64(pagination.Limit)
+	if result%int64(pagination.Limit) > 0 {
+		totalPage++
+	}
+
+	return result, totalPage, nil
+}
+
+func (ar AuthEventRepo) InsertAuthEvent(data da.AuthEvent) (int64, error) {
+	var id int64
+
+	param := make([]interface{}, 0)
+
+	param = append(param, data.EventType)
+	param = append(param, data.UserID)
+	param = append(param, data.PhoneHash)
+	param = append(param, data.IPAddress)
+	param = append(param, data.UserAgent)
+	param = append(param, data.RequestID)
+	param = append(param, data.Detail)
+	param = append(param, data.CreatedAt)
+
+	query, args, err := ar.DBList.Backend.Write.In(aeqInsertAuthEvent, param...)
+	if err != nil {
+		return id, err
+	}
+
+	query = ar.DBList.Backend.Write.Rebind(query)
+	err = ar.DBList.Backend.Write.QueryRow(query, args...).Scan(&id)
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+// RedactByUser clear the phone hash, IP & user agent of the event of the user, including the event
+// that only recorded the phone hash. The event type & time is kept for the security history.
+func (ar AuthEventRepo) RedactByUser(tx *sql.Tx, userID int64, phoneHash string) error {
+	query, args, err := ar.DBList.Backend.Write.In(aeqRedactAuthEvent, userID, phoneHash)
+	if err != nil {
+		return err
+	}
+
+	query = ar.DBList.Backend.Write.Rebind(query)
+
+	if tx == nil {
+		_, err = ar.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	return err
+}
+
+func (ar AuthEventRepo) buildFilter(filter da.AuthEventFilter) ([]string, []interface{}) {
+	param := make([]interface{}, 0)
+	var fl []string
+
+	if filter.UserID.Valid {
+		fl = append(fl, aeqFilterUserID)
+		param = append(param, filter.UserID.Int64)
+	}
+
+	if filter.EventType.Valid {
+		fl = append(fl, aeqFilterEventType)
+		param = append(param, filter.EventType.String)
+	}
+
+	if filter.PhoneHash.Valid {
+		fl = append(fl, aeqFilterPhoneHash)
+		param = append(param, filter.PhoneHash.String)
+	}
+
+	if filter.IPAddress.Valid {
+		fl = append(fl, aeqFilterIPAddress)
+		param = append(param, filter.IPAddress.String)
+	}
+
+	if filter.RequestID.Valid {
+		fl = append(fl, aeqFilterRequestID)
+		param = append(param, filter.RequestID.String)
+	}
+
+	if filter.CreatedFrom.Valid {
+		fl = append(fl, aeqFilterCreatedFrom)
+		param = append(param, filter.CreatedFrom.Time)
+	}
+
+	if filter.CreatedTo.Valid {
+		fl = append(fl, aeqFilterCreatedTo)
+		param = append(param, filter.CreatedTo.Time)
+	}
+
+	return fl, param
+}
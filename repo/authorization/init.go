@@ -0,0 +1,32 @@
+package authorization
+
+import (
+	"github.com/furee/backend/infra"
+	"github.com/sirupsen/logrus"
+)
+
+type AuthorizationRepo struct {
+	RefreshToken RefreshTokenRepoItf
+	RevokedToken RevokedTokenRepoItf
+	Role         RoleRepoItf
+	APIClient    APIClientRepoItf
+	MFA          MFARepoItf
+	Session      SessionRepoItf
+	AuditLog     AuditLogRepoItf
+	AuthEvent    AuthEventRepoItf
+	APIKey       APIKeyRepoItf
+}
+
+func NewMasterRepo(db *infra.DatabaseList, logger *logrus.Logger) AuthorizationRepo {
+	return AuthorizationRepo{
+		RefreshToken: newRefreshTokenRepo(db),
+		RevokedToken: newRevokedTokenRepo(db),
+		Role:         newRoleRepo(db),
+		APIClient:    newAPIClientRepo(db),
+		MFA:          newMFARepo(db),
+		Session:      newSessionRepo(db),
+		AuditLog:     newAuditLogRepo(db),
+		AuthEvent:    newAuthEventRepo(db),
+		APIKey:       newAPIKeyRepo(db),
+	}
+}
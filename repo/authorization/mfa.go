@@ -0,0 +1,300 @@
+package authorization
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/infra"
+)
+
+type MFARepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newMFARepo(dbList *infra.DatabaseList) MFARepo {
+	return MFARepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	mqSelectMFA = `
+	SELECT
+		user_id,
+		secret,
+		is_enabled,
+		last_used_step,
+		failed_attempt,
+		lock_count,
+		locked_until,
+		enabled_at,
+		created_at
+	FROM
+		user_mfa`
+
+	mqUpsertSecret = `
+	INSERT INTO user_mfa (
+		user_id,
+		secret,
+		is_enabled,
+		last_used_step,
+		created_at
+	) VALUES (
+		?, ?, false, 0, ?
+	) ON CONFLICT (user_id) DO UPDATE SET
+		secret = EXCLUDED.secret,
+		is_enabled = false,
+		last_used_step = 0,
+		enabled_at = NULL,
+		created_at = EXCLUDED.created_at`
+
+	mqUpdateMFA = `
+	UPDATE
+		user_mfa
+	SET`
+
+	mqSetEnabled = `
+		is_enabled = true,
+		enabled_at = ?`
+
+	mqSetLastUsedStep = `
+		last_used_step = ?`
+
+	mqSetIncrementFailedAttempt = `
+		failed_attempt = failed_attempt + 1`
+
+	mqSetLock = `
+		failed_attempt = 0,
+		lock_count = lock_count + 1,
+		locked_until = ?`
+
+	mqSetResetFailedAttempt = `
+		failed_attempt = 0,
+		lock_count = 0,
+		locked_until = NULL`
+
+	mqReturningFailedAttempt = `
+	RETURNING
+		failed_attempt`
+
+	mqDeleteMFA = `
+	DELETE FROM
+		user_mfa`
+
+	mqInsertRecoveryCode = `
+	INSERT INTO user_recovery_codes (
+		user_id,
+		code_hash,
+		created_at
+	) VALUES `
+
+	mqRecoveryCodeValue = `(?, ?, ?)`
+
+	mqUseRecoveryCode = `
+	UPDATE
+		user_recovery_codes
+	SET
+		used_at = ?`
+
+	mqDeleteRecoveryCode = `
+	DELETE FROM
+		user_recovery_codes`
+
+	mqWhere = `
+	WHERE`
+
+	mqFilterUserID = `
+		user_id = ?`
+
+	mqFilterLastUsedStepBefore = `
+		last_used_step < ?`
+
+	mqFilterLockCount = `
+		lock_count = ?`
+
+	mqFilterCodeHash = `
+		code_hash = ?`
+
+	mqFilterNotUsed = `
+		used_at IS NULL`
+)
+
+type MFARepoItf interface {
+	GetByUserID(userID int64) (*da.UserMFA, error)
+	UpsertSecret(tx *sql.Tx, userID int64, secret string) error
+	Enable(tx *sql.Tx, userID int64, step int64) error
+	UpdateLastUsedStep(tx *sql.Tx, userID int64, step int64) error
+	IncrementFailedAttempt(tx *sql.Tx, userID int64) (int, error)
+	Lock(tx *sql.Tx, userID int64, currentLockCount int, lockedUntil time.Time) (bool, error)
+	ResetFailedAttempt(tx *sql.Tx, userID int64) error
+	Delete(tx *sql.Tx, userID int64) error
+	InsertRecoveryCodes(tx *sql.Tx, userID int64, hashes []string) error
+	DeleteRecoveryCodes(tx *sql.Tx, userID int64) error
+	UseRecoveryCode(tx *sql.Tx, userID int64, hash string) error
+}
+
+func (mr MFARepo) GetByUserID(userID int64) (*da.UserMFA, error) {
+	var res da.UserMFA
+
+	q := fmt.Sprintf("%s%s%s", mqSelectMFA, mqWhere, mqFilterUserID)
+	query, args, err := mr.DBList.Backend.Read.In(q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = mr.DBList.Backend.Read.Rebind(query)
+	err = mr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if res.UserID == 0 {
+		return nil, nil
+	}
+
+	return &res, nil
+}
+
+// UpsertSecret store new secret for the enrolment, MFA stay disabled until activated.
+func (mr MFARepo) UpsertSecret(tx *sql.Tx, userID int64, secret string) error {
+	_, err := mr.exec(tx, mqUpsertSecret, userID, secret, time.Now().UTC())
+	return err
+}
+
+// Enable activate MFA & mark the step of the code used to activate it.
+func (mr MFARepo) Enable(tx *sql.Tx, userID int64, step int64) error {
+	q := fmt.Sprintf("%s%s, %s %s%s", mqUpdateMFA, mqSetEnabled, mqSetLastUsedStep, mqWhere, mqFilterUserID)
+	_, err := mr.exec(tx, q, time.Now().UTC(), step, userID)
+	return err
+}
+
+// UpdateLastUsedStep mark the TOTP step as used so the same code can't be replayed.
+// sql.ErrNoRows is returned when the step or a later one already used.
+func (mr MFARepo) UpdateLastUsedStep(tx *sql.Tx, userID int64, step int64) error {
+	q := fmt.Sprintf("%s%s %s%s AND %s", mqUpdateMFA, mqSetLastUsedStep, mqWhere, mqFilterUserID, mqFilterLastUsedStepBefore)
+	affected, err := mr.exec(tx, q, step, userID, step)
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// IncrementFailedAttempt add the attempt in one statement & return the new value,
+// so the parallel request is counted one by one.
+func (mr MFARepo) IncrementFailedAttempt(tx *sql.Tx, userID int64) (int, error) {
+	var attempt int
+
+	q := fmt.Sprintf("%s%s %s%s%s", mqUpdateMFA, mqSetIncrementFailedAttempt, mqWhere, mqFilterUserID, mqReturningFailedAttempt)
+	query, args, err := mr.DBList.Backend.Write.In(q, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	query = mr.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		err = mr.DBList.Backend.Write.Get(&attempt, query, args...)
+	} else {
+		err = tx.QueryRow(query, args...).Scan(&attempt)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return attempt, nil
+}
+
+// Lock lock the MFA verification & reset the attempt, only when the lock count is still the current one.
+// False is returned when it is already locked by the other request.
+func (mr MFARepo) Lock(tx *sql.Tx, userID int64, currentLockCount int, lockedUntil time.Time) (bool, error) {
+	q := fmt.Sprintf("%s%s %s%s AND %s", mqUpdateMFA, mqSetLock, mqWhere, mqFilterUserID, mqFilterLockCount)
+	affected, err := mr.exec(tx, q, lockedUntil, userID, currentLockCount)
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// ResetFailedAttempt clear the attempt & lock after the code is verified.
+func (mr MFARepo) ResetFailedAttempt(tx *sql.Tx, userID int64) error {
+	q := fmt.Sprintf("%s%s %s%s", mqUpdateMFA, mqSetResetFailedAttempt, mqWhere, mqFilterUserID)
+	_, err := mr.exec(tx, q, userID)
+	return err
+}
+
+func (mr MFARepo) Delete(tx *sql.Tx, userID int64) error {
+	q := fmt.Sprintf("%s%s%s", mqDeleteMFA, mqWhere, mqFilterUserID)
+	_, err := mr.exec(tx, q, userID)
+	return err
+}
+
+func (mr MFARepo) InsertRecoveryCodes(tx *sql.Tx, userID int64, hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	values := make([]string, 0)
+	param := make([]interface{}, 0)
+	for _, hash := range hashes {
+		values = append(values, mqRecoveryCodeValue)
+		param = append(param, userID, hash, now)
+	}
+
+	q := fmt.Sprintf("%s%s", mqInsertRecoveryCode, strings.Join(values, ", "))
+	_, err := mr.exec(tx, q, param...)
+	return err
+}
+
+func (mr MFARepo) DeleteRecoveryCodes(tx *sql.Tx, userID int64) error {
+	q := fmt.Sprintf("%s%s%s", mqDeleteRecoveryCode, mqWhere, mqFilterUserID)
+	_, err := mr.exec(tx, q, userID)
+	return err
+}
+
+// UseRecoveryCode mark the recovery code as used.
+// sql.ErrNoRows is returned when there is no unused code with the hash.
+func (mr MFARepo) UseRecoveryCode(tx *sql.Tx, userID int64, hash string) error {
+	q := fmt.Sprintf("%s %s%s AND %s AND %s", mqUseRecoveryCode, mqWhere, mqFilterUserID, mqFilterCodeHash, mqFilterNotUsed)
+	affected, err := mr.exec(tx, q, time.Now().UTC(), userID, hash)
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (mr MFARepo) exec(tx *sql.Tx, q string, param ...interface{}) (int64, error) {
+	query, args, err := mr.DBList.Backend.Write.In(q, param...)
+	if err != nil {
+		return 0, err
+	}
+
+	query = mr.DBList.Backend.Write.Rebind(query)
+
+	var res sql.Result
+	if tx == nil {
+		res, err = mr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		res, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,200 @@
+package authorization
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/infra"
+)
+
+type RefreshTokenRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newRefreshTokenRepo(dbList *infra.DatabaseList) RefreshTokenRepo {
+	return RefreshTokenRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	rtqSelectRefreshToken = `
+	SELECT
+		refresh_token_id,
+		family_id,
+		user_id,
+		expired_at,
+		revoked_at,
+		replaced_by,
+		created_at
+	FROM
+		refresh_tokens`
+
+	rtqInsertRefreshToken = `
+	INSERT INTO refresh_tokens (
+		refresh_token_id,
+		family_id,
+		user_id,
+		expired_at,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?
+	)`
+
+	rtqUpdateRefreshToken = `
+	UPDATE
+		refresh_tokens
+	SET
+		revoked_at = NOW()`
+
+	rtqWhere = `
+	WHERE`
+
+	rtqFilterRefreshTokenID = `
+		refresh_token_id = ?`
+
+	rtqFilterFamilyID = `
+		family_id = ?`
+
+	rtqFilterUserID = `
+		user_id = ?`
+
+	rtqFilterNotFamilyID = `
+		family_id <> ?`
+
+	rtqFilterNotRevoked = `
+		revoked_at IS NULL`
+
+	rtqSetReplacedBy = `
+		replaced_by = ?`
+)
+
+type RefreshTokenRepoItf interface {
+	GetByID(tokenID string) (*da.RefreshToken, error)
+	InsertRefreshToken(tx *sql.Tx, data da.RefreshToken) error
+	RotateRefreshToken(tx *sql.Tx, tokenID, newTokenID string) error
+	RevokeFamily(tx *sql.Tx, familyID string) error
+	RevokeByUserID(tx *sql.Tx, userID int64) error
+	RevokeOtherFamilies(tx *sql.Tx, userID int64, familyID string) error
+}
+
+func (rr RefreshTokenRepo) GetByID(tokenID string) (*da.RefreshToken, error) {
+	var res da.RefreshToken
+
+	q := fmt.Sprintf("%s%s%s", rtqSelectRefreshToken, rtqWhere, rtqFilterRefreshTokenID)
+	query, args, err := rr.DBList.Backend.Read.In(q, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = rr.DBList.Backend.Read.Rebind(query)
+	err = rr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if res.ID == "" {
+		return nil, nil
+	}
+
+	return &res, nil
+}
+
+func (rr RefreshTokenRepo) InsertRefreshToken(tx *sql.Tx, data da.RefreshToken) error {
+	param := make([]interface{}, 0)
+
+	param = append(param, data.ID)
+	param = append(param, data.FamilyID)
+	param = append(param, data.UserID)
+	param = append(param, data.ExpiredAt)
+	param = append(param, time.Now().UTC())
+
+	query, args, err := rr.DBList.Backend.Write.In(rtqInsertRefreshToken, param...)
+	if err != nil {
+		return err
+	}
+
+	query = rr.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = rr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RotateRefreshToken revoke the token & mark which token replace it.
+// sql.ErrNoRows is returned when the token already rotated or revoked.
+func (rr RefreshTokenRepo) RotateRefreshToken(tx *sql.Tx, tokenID, newTokenID string) error {
+	q := fmt.Sprintf("%s, %s %s%s AND %s", rtqUpdateRefreshToken, rtqSetReplacedBy, rtqWhere, rtqFilterRefreshTokenID, rtqFilterNotRevoked)
+	query, args, err := rr.DBList.Backend.Write.In(q, newTokenID, tokenID)
+	if err != nil {
+		return err
+	}
+
+	query = rr.DBList.Backend.Write.Rebind(query)
+
+	var res sql.Result
+	if tx == nil {
+		res, err = rr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		res, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (rr RefreshTokenRepo) RevokeFamily(tx *sql.Tx, familyID string) error {
+	q := fmt.Sprintf("%s %s%s AND %s", rtqUpdateRefreshToken, rtqWhere, rtqFilterFamilyID, rtqFilterNotRevoked)
+	return rr.exec(tx, q, familyID)
+}
+
+func (rr RefreshTokenRepo) RevokeByUserID(tx *sql.Tx, userID int64) error {
+	q := fmt.Sprintf("%s %s%s AND %s", rtqUpdateRefreshToken, rtqWhere, rtqFilterUserID, rtqFilterNotRevoked)
+	return rr.exec(tx, q, userID)
+}
+
+func (rr RefreshTokenRepo) RevokeOtherFamilies(tx *sql.Tx, userID int64, familyID string) error {
+	q := fmt.Sprintf("%s %s%s AND %s AND %s", rtqUpdateRefreshToken, rtqWhere, rtqFilterUserID, rtqFilterNotFamilyID, rtqFilterNotRevoked)
+	return rr.exec(tx, q, userID, familyID)
+}
+
+func (rr RefreshTokenRepo) exec(tx *sql.Tx, q string, param ...interface{}) error {
+	query, args, err := rr.DBList.Backend.Write.In(q, param...)
+	if err != nil {
+		return err
+	}
+
+	query = rr.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = rr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
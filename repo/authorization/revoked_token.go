@@ -0,0 +1,83 @@
+package authorization
+
+import (
+	"database/sql"
+	"time"
+
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/infra"
+)
+
+type RevokedTokenRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newRevokedTokenRepo(dbList *infra.DatabaseList) RevokedTokenRepo {
+	return RevokedTokenRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	rvqInsertRevokedToken = `
+	INSERT INTO revoked_access_tokens (
+		access_token_id,
+		expired_at,
+		created_at
+	) VALUES (
+		?, ?, ?
+	)
+	ON CONFLICT (access_token_id) DO NOTHING`
+
+	rvqSelectExist = `
+	SELECT EXISTS (
+		SELECT
+			1
+		FROM
+			revoked_access_tokens
+		WHERE
+			access_token_id = ?
+	)`
+)
+
+type RevokedTokenRepoItf interface {
+	InsertRevokedToken(tx *sql.Tx, data da.RevokedAccessToken) error
+	IsRevoked(tokenID string) (bool, error)
+}
+
+func (rr RevokedTokenRepo) InsertRevokedToken(tx *sql.Tx, data da.RevokedAccessToken) error {
+	query, args, err := rr.DBList.Backend.Write.In(rvqInsertRevokedToken, data.ID, data.ExpiredAt, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	query = rr.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = rr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (rr RevokedTokenRepo) IsRevoked(tokenID string) (bool, error) {
+	var isExist bool
+
+	query, args, err := rr.DBList.Backend.Read.In(rvqSelectExist, tokenID)
+	if err != nil {
+		return isExist, err
+	}
+
+	query = rr.DBList.Backend.Read.Rebind(query)
+	err = rr.DBList.Backend.Read.Get(&isExist, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return isExist, err
+	}
+
+	return isExist, nil
+}
@@ -0,0 +1,214 @@
+package authorization
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/infra"
+)
+
+type RoleRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newRoleRepo(dbList *infra.DatabaseList) RoleRepo {
+	return RoleRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	rqSelectRole = `
+	SELECT
+		r.role_id,
+		r.name,
+		r.description
+	FROM
+		roles r`
+
+	rqSelectUserRole = `
+	SELECT
+		r.role_id,
+		r.name,
+		r.description
+	FROM
+		roles r
+	JOIN
+		user_roles ur ON ur.role_id = r.role_id`
+
+	rqSelectUserPermission = `
+	SELECT DISTINCT
+		p.name
+	FROM
+		permissions p
+	JOIN
+		role_permissions rp ON rp.permission_id = p.permission_id
+	JOIN
+		user_roles ur ON ur.role_id = rp.role_id`
+
+	rqSelectRolePermission = `
+	SELECT DISTINCT
+		p.name
+	FROM
+		permissions p
+	JOIN
+		role_permissions rp ON rp.permission_id = p.permission_id`
+
+	rqInsertUserRole = `
+	INSERT INTO user_roles (
+		user_id,
+		role_id,
+		created_by,
+		created_at
+	) VALUES (
+		?, ?, ?, ?
+	)`
+
+	rqDeleteUserRole = `
+	DELETE FROM
+		user_roles`
+
+	rqWhere = `
+	WHERE`
+
+	rqFilterUserID = `
+		ur.user_id = ?`
+
+	rqFilterName = `
+		r.name IN (?)`
+
+	rqFilterRoleIDs = `
+		rp.role_id IN (?)`
+
+	rqFilterDeleteUserID = `
+		user_id = ?`
+)
+
+type RoleRepoItf interface {
+	GetByNames(names []string) ([]da.Role, error)
+	GetByUserID(userID int64) ([]da.Role, error)
+	GetPermissionsByUserID(userID int64) ([]string, error)
+	GetPermissionsByRoleIDs(roleIDs []int64) ([]string, error)
+	InsertUserRole(tx *sql.Tx, userID, roleID, createdBy int64) error
+	DeleteUserRoles(tx *sql.Tx, userID int64) error
+}
+
+func (rr RoleRepo) GetByNames(names []string) ([]da.Role, error) {
+	var res []da.Role
+
+	q := fmt.Sprintf("%s%s%s", rqSelectRole, rqWhere, rqFilterName)
+	query, args, err := rr.DBList.Backend.Read.In(q, names)
+	if err != nil {
+		return res, err
+	}
+
+	query = rr.DBList.Backend.Read.Rebind(query)
+	err = rr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (rr RoleRepo) GetByUserID(userID int64) ([]da.Role, error) {
+	var res []da.Role
+
+	q := fmt.Sprintf("%s%s%s", rqSelectUserRole, rqWhere, rqFilterUserID)
+	query, args, err := rr.DBList.Backend.Read.In(q, userID)
+	if err != nil {
+		return res, err
+	}
+
+	query = rr.DBList.Backend.Read.Rebind(query)
+	err = rr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (rr RoleRepo) GetPermissionsByUserID(userID int64) ([]string, error) {
+	var res []string
+
+	q := fmt.Sprintf("%s%s%s", rqSelectUserPermission, rqWhere, rqFilterUserID)
+	query, args, err := rr.DBList.Backend.Read.In(q, userID)
+	if err != nil {
+		return res, err
+	}
+
+	query = rr.DBList.Backend.Read.Rebind(query)
+	err = rr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// GetPermissionsByRoleIDs return the permission of all the roles at once.
+func (rr RoleRepo) GetPermissionsByRoleIDs(roleIDs []int64) ([]string, error) {
+	var res []string
+
+	if len(roleIDs) == 0 {
+		return res, nil
+	}
+
+	q := fmt.Sprintf("%s%s%s", rqSelectRolePermission, rqWhere, rqFilterRoleIDs)
+	query, args, err := rr.DBList.Backend.Read.In(q, roleIDs)
+	if err != nil {
+		return res, err
+	}
+
+	query = rr.DBList.Backend.Read.Rebind(query)
+	err = rr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (rr RoleRepo) InsertUserRole(tx *sql.Tx, userID, roleID, createdBy int64) error {
+	query, args, err := rr.DBList.Backend.Write.In(rqInsertUserRole, userID, roleID, createdBy, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	query = rr.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = rr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (rr RoleRepo) DeleteUserRoles(tx *sql.Tx, userID int64) error {
+	q := fmt.Sprintf("%s%s%s", rqDeleteUserRole, rqWhere, rqFilterDeleteUserID)
+	query, args, err := rr.DBList.Backend.Write.In(q, userID)
+	if err != nil {
+		return err
+	}
+
+	query = rr.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = rr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
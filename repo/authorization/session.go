@@ -0,0 +1,232 @@
+package authorization
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/infra"
+)
+
+type SessionRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newSessionRepo(dbList *infra.DatabaseList) SessionRepo {
+	return SessionRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	sqSelectSession = `
+	SELECT
+		session_id,
+		user_id,
+		family_id,
+		device_name,
+		user_agent,
+		ip_address,
+		expired_at,
+		last_seen_at,
+		revoked_at,
+		created_at
+	FROM
+		user_sessions`
+
+	sqInsertSession = `
+	INSERT INTO user_sessions (
+		session_id,
+		user_id,
+		family_id,
+		device_name,
+		user_agent,
+		ip_address,
+		expired_at,
+		last_seen_at,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?, ?, ?, ?, ?
+	)`
+
+	sqUpdateSession = `
+	UPDATE
+		user_sessions
+	SET`
+
+	sqSetRevoked = `
+		revoked_at = NOW()`
+
+	sqSetLastSeen = `
+		last_seen_at = ?`
+
+	sqSetExpired = `
+		expired_at = ?`
+
+	sqWhere = `
+	WHERE`
+
+	sqFilterSessionID = `
+		session_id = ?`
+
+	sqFilterFamilyID = `
+		family_id = ?`
+
+	sqFilterUserID = `
+		user_id = ?`
+
+	sqFilterNotFamilyID = `
+		family_id <> ?`
+
+	sqFilterNotRevoked = `
+		revoked_at IS NULL`
+
+	sqFilterNotExpired = `
+		expired_at > ?`
+
+	sqDeleteSession = `
+	DELETE FROM
+		user_sessions`
+
+	sqOrderByLastSeen = `
+	ORDER BY
+		last_seen_at DESC`
+)
+
+type SessionRepoItf interface {
+	GetByID(sessionID string) (*da.UserSession, error)
+	GetByFamilyID(familyID string) (*da.UserSession, error)
+	GetListByUserID(userID int64) ([]da.UserSession, error)
+	InsertSession(tx *sql.Tx, data da.UserSession) error
+	UpdateLastSeen(familyID string, lastSeenAt time.Time) error
+	UpdateExpired(tx *sql.Tx, familyID string, expiredAt time.Time) error
+	RevokeByID(tx *sql.Tx, sessionID string) error
+	RevokeByFamilyID(tx *sql.Tx, familyID string) error
+	RevokeByUserID(tx *sql.Tx, userID int64) error
+	RevokeOtherFamilies(tx *sql.Tx, userID int64, familyID string) error
+	DeleteByUserID(tx *sql.Tx, userID int64) error
+}
+
+func (sr SessionRepo) GetByID(sessionID string) (*da.UserSession, error) {
+	q := fmt.Sprintf("%s%s%s", sqSelectSession, sqWhere, sqFilterSessionID)
+	return sr.get(q, sessionID)
+}
+
+func (sr SessionRepo) GetByFamilyID(familyID string) (*da.UserSession, error) {
+	q := fmt.Sprintf("%s%s%s", sqSelectSession, sqWhere, sqFilterFamilyID)
+	return sr.get(q, familyID)
+}
+
+// GetListByUserID return the session of the user that not revoked & not expired yet.
+func (sr SessionRepo) GetListByUserID(userID int64) ([]da.UserSession, error) {
+	res := make([]da.UserSession, 0)
+
+	q := fmt.Sprintf("%s%s%s AND %s AND %s%s", sqSelectSession, sqWhere, sqFilterUserID, sqFilterNotRevoked, sqFilterNotExpired, sqOrderByLastSeen)
+	query, args, err := sr.DBList.Backend.Read.In(q, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	query = sr.DBList.Backend.Read.Rebind(query)
+	err = sr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (sr SessionRepo) InsertSession(tx *sql.Tx, data da.UserSession) error {
+	param := make([]interface{}, 0)
+
+	param = append(param, data.ID)
+	param = append(param, data.UserID)
+	param = append(param, data.FamilyID)
+	param = append(param, data.DeviceName)
+	param = append(param, data.UserAgent)
+	param = append(param, data.IPAddress)
+	param = append(param, data.ExpiredAt)
+	param = append(param, data.LastSeenAt)
+	param = append(param, time.Now().UTC())
+
+	return sr.exec(tx, sqInsertSession, param...)
+}
+
+func (sr SessionRepo) UpdateLastSeen(familyID string, lastSeenAt time.Time) error {
+	q := fmt.Sprintf("%s%s %s%s", sqUpdateSession, sqSetLastSeen, sqWhere, sqFilterFamilyID)
+	return sr.exec(nil, q, lastSeenAt, familyID)
+}
+
+// UpdateExpired extend the session when the refresh token is renewed.
+func (sr SessionRepo) UpdateExpired(tx *sql.Tx, familyID string, expiredAt time.Time) error {
+	q := fmt.Sprintf("%s%s, %s %s%s", sqUpdateSession, sqSetExpired, sqSetLastSeen, sqWhere, sqFilterFamilyID)
+	return sr.exec(tx, q, expiredAt, time.Now().UTC(), familyID)
+}
+
+func (sr SessionRepo) RevokeByID(tx *sql.Tx, sessionID string) error {
+	q := fmt.Sprintf("%s%s %s%s AND %s", sqUpdateSession, sqSetRevoked, sqWhere, sqFilterSessionID, sqFilterNotRevoked)
+	return sr.exec(tx, q, sessionID)
+}
+
+func (sr SessionRepo) RevokeByFamilyID(tx *sql.Tx, familyID string) error {
+	q := fmt.Sprintf("%s%s %s%s AND %s", sqUpdateSession, sqSetRevoked, sqWhere, sqFilterFamilyID, sqFilterNotRevoked)
+	return sr.exec(tx, q, familyID)
+}
+
+func (sr SessionRepo) RevokeByUserID(tx *sql.Tx, userID int64) error {
+	q := fmt.Sprintf("%s%s %s%s AND %s", sqUpdateSession, sqSetRevoked, sqWhere, sqFilterUserID, sqFilterNotRevoked)
+	return sr.exec(tx, q, userID)
+}
+
+func (sr SessionRepo) RevokeOtherFamilies(tx *sql.Tx, userID int64, familyID string) error {
+	q := fmt.Sprintf("%s%s %s%s AND %s AND %s", sqUpdateSession, sqSetRevoked, sqWhere, sqFilterUserID, sqFilterNotFamilyID, sqFilterNotRevoked)
+	return sr.exec(tx, q, userID, familyID)
+}
+
+// DeleteByUserID remove the session & the device data of the deleted user.
+func (sr SessionRepo) DeleteByUserID(tx *sql.Tx, userID int64) error {
+	q := fmt.Sprintf("%s%s%s", sqDeleteSession, sqWhere, sqFilterUserID)
+	return sr.exec(tx, q, userID)
+}
+
+func (sr SessionRepo) get(q string, param ...interface{}) (*da.UserSession, error) {
+	var res da.UserSession
+
+	query, args, err := sr.DBList.Backend.Read.In(q, param...)
+	if err != nil {
+		return nil, err
+	}
+
+	query = sr.DBList.Backend.Read.Rebind(query)
+	err = sr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if res.ID == "" {
+		return nil, nil
+	}
+
+	return &res, nil
+}
+
+func (sr SessionRepo) exec(tx *sql.Tx, q string, param ...interface{}) error {
+	query, args, err := sr.DBList.Backend.Write.In(q, param...)
+	if err != nil {
+		return err
+	}
+
+	query = sr.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = sr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
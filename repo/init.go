@@ -2,6 +2,7 @@ package repo
 
 import (
 	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo/authorization"
 	m "github.com/furee/backend/repo/master"
 	"github.com/furee/backend/repo/order"
 	"github.com/furee/backend/repo/user"
@@ -9,15 +10,17 @@ import (
 )
 
 type Repo struct {
-	Master m.MasterRepo
-	User   user.UserRepo
-	Order  order.OrderRepo
+	Master        m.MasterRepo
+	User          user.UserRepo
+	Order         order.OrderRepo
+	Authorization authorization.AuthorizationRepo
 }
 
 func NewRepo(db *infra.DatabaseList, logger *logrus.Logger) Repo {
 	return Repo{
-		Master: m.NewMasterRepo(db, logger),
-		User:   user.NewMasterRepo(db, logger),
-		Order:  order.NewMasterRepo(db, logger),
+		Master:        m.NewMasterRepo(db, logger),
+		User:          user.NewMasterRepo(db, logger),
+		Order:         order.NewMasterRepo(db, logger),
+		Authorization: authorization.NewMasterRepo(db, logger),
 	}
 }
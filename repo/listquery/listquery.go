@@ -0,0 +1,187 @@
+// Package listquery build the filter, sort & pagination part of the list query.
+// Only the column declared by the repo is written to the query, every value from the request is passed as a bind parameter.
+package listquery
+
+import (
+	"fmt"
+	"strings"
+
+	dg "github.com/furee/backend/domain/general"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	lqWhere = `
+	WHERE`
+
+	lqOrderBy = `
+	ORDER BY`
+
+	lqLimitOffset = `
+	LIMIT ?
+	OFFSET ?`
+)
+
+// Query collect the condition of the list, an invalid filter value is skipped.
+type Query struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (q *Query) add(condition string, arg interface{}) {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, arg)
+}
+
+// Int filter the column equal to the value.
+func (q *Query) Int(column string, value null.Int) {
+	if value.Valid {
+		q.add(fmt.Sprintf("%s = ?", column), value.Int64)
+	}
+}
+
+// Bool filter the column equal to the value.
+func (q *Query) Bool(column string, value null.Bool) {
+	if value.Valid {
+		q.add(fmt.Sprintf("%s = ?", column), value.Bool)
+	}
+}
+
+// String filter the column equal to the value.
+func (q *Query) String(column string, value null.String) {
+	if value.Valid {
+		q.add(fmt.Sprintf("%s = ?", column), value.String)
+	}
+}
+
+// Contains filter the column that contain the value, case insensitive.
+func (q *Query) Contains(column string, value null.String) {
+	if value.Valid {
+		q.add(fmt.Sprintf("lower(%s) LIKE ?", column), "%"+strings.ToLower(value.String)+"%")
+	}
+}
+
+// TimeFrom filter the column that is equal or after the value.
+func (q *Query) TimeFrom(column string, value null.Time) {
+	if value.Valid {
+		q.add(fmt.Sprintf("%s >= ?", column), value.Time)
+	}
+}
+
+// TimeTo filter the column that is equal or before the value.
+func (q *Query) TimeTo(column string, value null.Time) {
+	if value.Valid {
+		q.add(fmt.Sprintf("%s <= ?", column), value.Time)
+	}
+}
+
+// Where return the where clause of the condition, empty when there is no condition.
+func (q Query) Where() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+
+	return lqWhere + "\n\t\t" + strings.Join(q.conditions, " AND\n\t\t")
+}
+
+// Args return the bind parameter of the condition.
+func (q Query) Args() []interface{} {
+	args := make([]interface{}, len(q.args))
+	copy(args, q.args)
+
+	return args
+}
+
+// Select return the list query with the condition, sort & limit of the pagination.
+func (q Query) Select(selectQuery string, sortable Sortable, pagination dg.PaginationData) (string, []interface{}, error) {
+	orderBy, err := sortable.OrderBy(pagination.Sorts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	limit, limitArgs := LimitOffset(pagination)
+
+	return selectQuery + q.Where() + orderBy + limit, append(q.Args(), limitArgs...), nil
+}
+
+// Count return the count query with the condition.
+func (q Query) Count(countQuery string) (string, []interface{}) {
+	return countQuery + q.Where(), q.Args()
+}
+
+// Sortable declare the field that can be used to sort the list of an entity.
+type Sortable struct {
+	// Columns map the sort field of the request to the column.
+	Columns map[string]string
+
+	// Default is used when no sort is requested, Key ascending when it is empty.
+	Default []dg.SortData
+
+	// Key is the unique column, always added as the last sort so the page is stable.
+	Key string
+}
+
+// OrderBy return the order by clause of the sort, SortError is returned for the field that is not declared.
+func (s Sortable) OrderBy(sorts []dg.SortData) (string, error) {
+	if len(sorts) == 0 {
+		sorts = s.Default
+	}
+
+	var fields []string
+	used := make(map[string]bool)
+
+	for _, sort := range sorts {
+		column, ok := s.Columns[sort.Field]
+		if !ok {
+			return "", dg.SortError{Field: sort.Field}
+		}
+
+		if used[column] {
+			continue
+		}
+
+		used[column] = true
+		fields = append(fields, column+" "+direction(sort.Desc))
+	}
+
+	if s.Key != "" && !used[s.Key] {
+		fields = append(fields, s.Key+" "+direction(false))
+	}
+
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	return lqOrderBy + "\n\t\t" + strings.Join(fields, ",\n\t\t"), nil
+}
+
+func direction(desc bool) string {
+	if desc {
+		return "DESC"
+	}
+
+	return "ASC"
+}
+
+// LimitOffset return the limit clause of the pagination, empty when all data is requested.
+func LimitOffset(pagination dg.PaginationData) (string, []interface{}) {
+	if pagination.IsGetAll {
+		return "", nil
+	}
+
+	return lqLimitOffset, []interface{}{pagination.Limit, pagination.Offset}
+}
+
+// TotalPage return the number of page of the total data.
+func TotalPage(total int64, limit int) int64 {
+	if limit <= 0 {
+		return 1
+	}
+
+	totalPage := total / int64(limit)
+	if total%int64(limit) > 0 {
+		totalPage++
+	}
+
+	return totalPage
+}
@@ -0,0 +1,106 @@
+package listquery
+
+import (
+	"strings"
+	"testing"
+
+	dg "github.com/furee/backend/domain/general"
+	"gopkg.in/guregu/null.v4"
+)
+
+var testSortable = Sortable{
+	Columns: map[string]string{
+		"id":         "user_id",
+		"name":       "name",
+		"created_at": "created_at",
+	},
+	Default: []dg.SortData{{Field: "created_at", Desc: true}},
+	Key:     "user_id",
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "default", sort: "", want: []string{"created_at DESC", "user_id ASC"}},
+		{name: "asc", sort: "name", want: []string{"name ASC", "user_id ASC"}},
+		{name: "desc with key", sort: "name:desc,id:desc", want: []string{"name DESC", "user_id DESC"}},
+		{name: "duplicate field", sort: "name:desc,name:asc", want: []string{"name DESC", "user_id ASC"}},
+		{name: "unknown field", sort: "phone", wantErr: true},
+		{name: "column name is not a field", sort: "user_id", wantErr: true},
+		{name: "injection", sort: "name;drop table users", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pagination dg.PaginationData
+			if tt.sort != "" {
+				if err := pagination.SetSort(tt.sort); err != nil {
+					t.Fatalf("SetSort(%q) error = %v", tt.sort, err)
+				}
+			}
+
+			orderBy, err := testSortable.OrderBy(pagination.Sorts)
+			if tt.wantErr {
+				if !dg.IsSortError(err) {
+					t.Fatalf("OrderBy(%q) error = %v, want SortError", tt.sort, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("OrderBy(%q) error = %v", tt.sort, err)
+			}
+
+			want := lqOrderBy + "\n\t\t" + strings.Join(tt.want, ",\n\t\t")
+			if orderBy != want {
+				t.Errorf("OrderBy(%q) = %q, want %q", tt.sort, orderBy, want)
+			}
+		})
+	}
+}
+
+func TestSetSortInvalid(t *testing.T) {
+	for _, sort := range []string{",", "name,", ":asc", "name:up", "name:asc:desc"} {
+		var pagination dg.PaginationData
+		if err := pagination.SetSort(sort); !dg.IsSortError(err) {
+			t.Errorf("SetSort(%q) error = %v, want SortError", sort, err)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	pagination := dg.PaginationData{Limit: 10, Offset: 20}
+
+	var q Query
+	q.Int("status", null.IntFrom(1))
+	q.Contains("name", null.StringFrom("Budi"))
+	q.String("phone", null.String{})
+
+	query, args, err := q.Select("SELECT * FROM users", testSortable, pagination)
+	if err != nil {
+		t.Fatalf("Select error = %v", err)
+	}
+
+	if !strings.Contains(query, "status = ? AND\n\t\tlower(name) LIKE ?") || strings.Contains(query, "phone") {
+		t.Errorf("Select query = %q, want the status & name condition only", query)
+	}
+
+	want := []interface{}{int64(1), "%budi%", 10, 20}
+	if len(args) != len(want) {
+		t.Fatalf("Select args = %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("Select args[%d] = %v, want %v", i, args[i], want[i])
+		}
+	}
+
+	pagination.IsGetAll = true
+	if limit, limitArgs := LimitOffset(pagination); limit != "" || limitArgs != nil {
+		t.Errorf("LimitOffset of get all = (%q, %v), want empty", limit, limitArgs)
+	}
+}
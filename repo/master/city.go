@@ -1,12 +1,14 @@
 package master
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 
 	dg "github.com/furee/backend/domain/general"
 	dm "github.com/furee/backend/domain/master"
 	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo/listquery"
 )
 
 type CityRepo struct {
@@ -24,7 +26,9 @@ const (
 	SELECT
 		city_id,
 		province_id,
-		name
+		name,
+		code,
+		is_active
 	FROM
 		cities`
 
@@ -46,20 +50,57 @@ const (
 	cqFilterName = `
 		lower(name) LIKE ?`
 
-	cqLimitOffset = `
-	LIMIT ?
-	OFFSET ?`
+	cqFilterProvinceIDs = `
+		province_id IN (?)`
 
-	cqOrderBy = `
-	ORDER BY`
+	cqFilterIsActive = `
+		is_active = ?`
+
+	cqFilterSameName = `
+		lower(name) = lower(?)`
+
+	cqFilterNotCityID = `
+		city_id <> ?`
+
+	cqOrderByName = `
+	ORDER BY
+		name ASC`
+
+	cqInsertCity = `
+	INSERT INTO cities (
+		province_id,
+		name,
+		code,
+		is_active
+	) VALUES (?, ?, ?, ?)
+	RETURNING city_id`
+
+	cqUpdateCity = `
+	UPDATE
+		cities
+	SET`
+
+	cqSetCity = `
+		province_id = ?,
+		name = ?,
+		code = ?,
+		is_active = ?`
+
+	cqSetInactive = `
+		is_active = false`
 )
 
 type CityRepoItf interface {
 	GetByID(cityID int64) (dm.City, error)
 	GetByProvinceID(provinceID int64) ([]dm.City, error)
+	GetByProvinceIDs(provinceIDs []int64) ([]dm.City, error)
 	GetByName(name string) (dm.City, error)
 	GetListCity(pagination dg.PaginationData, filter dm.CityFilter) ([]dm.City, error)
 	GetTotalDataCity(pagination dg.PaginationData, filter dm.CityFilter) (int64, int64, error)
+	GetDuplicateName(data dm.City) (*dm.City, error)
+	InsertCity(tx *sql.Tx, data dm.City) (int64, error)
+	UpdateCity(tx *sql.Tx, data dm.City) error
+	DeactivateCity(tx *sql.Tx, cityID int64) error
 }
 
 func (cr CityRepo) GetByID(cityID int64) (dm.City, error) {
@@ -98,6 +139,29 @@ func (cr CityRepo) GetByProvinceID(provinceID int64) ([]dm.City, error) {
 	return res, nil
 }
 
+// GetByProvinceIDs return the active city of all the parent at once, used to build the location tree.
+func (cr CityRepo) GetByProvinceIDs(provinceIDs []int64) ([]dm.City, error) {
+	var res []dm.City
+
+	if len(provinceIDs) == 0 {
+		return res, nil
+	}
+
+	q := fmt.Sprintf("%s%s%s AND%s%s", cqSelectCity, cqWhere, cqFilterProvinceIDs, cqFilterIsActive, cqOrderByName)
+	query, args, err := cr.DBList.Backend.Read.In(q, provinceIDs, true)
+	if err != nil {
+		return res, err
+	}
+
+	query = cr.DBList.Backend.Read.Rebind(query)
+	err = cr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
 func (cr CityRepo) GetByName(name string) (dm.City, error) {
 	var res dm.City
 
@@ -116,35 +180,33 @@ func (cr CityRepo) GetByName(name string) (dm.City, error) {
 	return res, nil
 }
 
-func (cr CityRepo) GetListCity(pagination dg.PaginationData, filter dm.CityFilter) ([]dm.City, error) {
-	var result []dm.City
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, cqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
-
-	if filter.ProvinceID.Valid {
-		fl = append(fl, cqFilterProvinceID)
-		param = append(param, filter.ProvinceID.Int64)
-	}
+// citySortable is the field that can be used to sort the city list, id is the alias of city_id.
+var citySortable = listquery.Sortable{
+	Columns: map[string]string{
+		"id":          "city_id",
+		"city_id":     "city_id",
+		"province_id": "province_id",
+		"name":        "name",
+		"code":        "code",
+	},
+	Key: "city_id",
+}
 
-	q := cqSelectCity
+func (cr CityRepo) listQuery(filter dm.CityFilter) listquery.Query {
+	var lq listquery.Query
+	lq.Contains("name", filter.Name)
+	lq.Int("province_id", filter.ProvinceID)
+	lq.Bool("is_active", filter.IsActive)
 
-	if len(fl) > 0 {
-		q += cqWhere + strings.Join(fl, " AND ")
-	}
+	return lq
+}
 
-	// Add orderby value.
-	q += " " + cqOrderBy + " " + pagination.OrderBy.String + " " + strings.ToLower(pagination.Sort)
+func (cr CityRepo) GetListCity(pagination dg.PaginationData, filter dm.CityFilter) ([]dm.City, error) {
+	var result []dm.City
 
-	if !pagination.IsGetAll {
-		// Add limit & page to param.
-		q += cqLimitOffset
-		param = append(param, pagination.Limit)
-		param = append(param, pagination.Offset)
+	q, param, err := cr.listQuery(filter).Select(cqSelectCity, citySortable, pagination)
+	if err != nil {
+		return result, err
 	}
 
 	query, args, err := cr.DBList.Backend.Read.In(q, param...)
@@ -163,25 +225,8 @@ func (cr CityRepo) GetListCity(pagination dg.PaginationData, filter dm.CityFilte
 
 func (cr CityRepo) GetTotalDataCity(pagination dg.PaginationData, filter dm.CityFilter) (int64, int64, error) {
 	var result int64
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, cqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
-
-	if filter.ProvinceID.Valid {
-		fl = append(fl, cqFilterProvinceID)
-		param = append(param, filter.ProvinceID.Int64)
-	}
-
-	q := cqCountCity
-
-	if len(fl) > 0 {
-		q += dqWhere + strings.Join(fl, " AND ")
-	}
 
+	q, param := cr.listQuery(filter).Count(cqCountCity)
 	query, args, err := cr.DBList.Backend.Read.In(q, param...)
 	if err != nil {
 		return result, 0, err
@@ -194,11 +239,44 @@ func (cr CityRepo) GetTotalDataCity(pagination dg.PaginationData, filter dm.City
 		return result, 0, err
 	}
 
-	//Calculate Total Page
-	totalPage := result / int64(pagination.Limit)
-	if result%int64(pagination.Limit) > 0 {
-		totalPage++
+	return result, listquery.TotalPage(result, pagination.Limit), nil
+}
+
+// GetDuplicateName return the other city with the same name on the province, nil when not found.
+func (cr CityRepo) GetDuplicateName(data dm.City) (*dm.City, error) {
+	var res dm.City
+
+	q := fmt.Sprintf("%s%s%s AND%s AND%s", cqSelectCity, cqWhere, cqFilterProvinceID, cqFilterSameName, cqFilterNotCityID)
+	query, args, err := cr.DBList.Backend.Read.In(q, data.ProvinceID, data.Name, data.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = cr.DBList.Backend.Read.Rebind(query)
+	err = cr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
 	}
 
-	return result, totalPage, nil
+	return &res, nil
+}
+
+func (cr CityRepo) InsertCity(tx *sql.Tx, data dm.City) (int64, error) {
+	return insertReturningID(cr.DBList, tx, cqInsertCity, data.ProvinceID, data.Name, data.Code, data.IsActive)
+}
+
+// UpdateCity return sql.ErrNoRows when the city is not exist.
+func (cr CityRepo) UpdateCity(tx *sql.Tx, data dm.City) error {
+	q := fmt.Sprintf("%s%s%s%s", cqUpdateCity, cqSetCity, cqWhere, cqFilterCityID)
+	return execWrite(cr.DBList, tx, q, data.ProvinceID, data.Name, data.Code, data.IsActive, data.ID)
+}
+
+// DeactivateCity return sql.ErrNoRows when the city is not exist.
+func (cr CityRepo) DeactivateCity(tx *sql.Tx, cityID int64) error {
+	q := fmt.Sprintf("%s%s%s%s", cqUpdateCity, cqSetInactive, cqWhere, cqFilterCityID)
+	return execWrite(cr.DBList, tx, q, cityID)
 }
@@ -1,12 +1,14 @@
 package master
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 
 	dg "github.com/furee/backend/domain/general"
 	dm "github.com/furee/backend/domain/master"
 	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo/listquery"
 )
 
 type CountryRepo struct {
@@ -23,7 +25,9 @@ const (
 	prqSelectCountry = `
 	SELECT
 		country_id,
-		name
+		name,
+		code,
+		is_active
 	FROM
 		countries`
 
@@ -41,12 +45,32 @@ const (
 	prqFilterName = `
 		lower(name) LIKE ?`
 
-	prqLimitOffset = `
-	LIMIT ?
-	OFFSET ?`
+	prqFilterSameName = `
+		lower(name) = lower(?)`
 
-	prqOrderBy = `
-	ORDER BY`
+	prqFilterNotCountryID = `
+		country_id <> ?`
+
+	prqInsertCountry = `
+	INSERT INTO countries (
+		name,
+		code,
+		is_active
+	) VALUES (?, ?, ?)
+	RETURNING country_id`
+
+	prqUpdateCountry = `
+	UPDATE
+		countries
+	SET`
+
+	prqSetCountry = `
+		name = ?,
+		code = ?,
+		is_active = ?`
+
+	prqSetInactive = `
+		is_active = false`
 )
 
 type CountryRepoItf interface {
@@ -54,6 +78,10 @@ type CountryRepoItf interface {
 	GetByName(countryName string) (dm.Country, error)
 	GetListCountry(pagination dg.PaginationData, filter dm.CountryFilter) ([]dm.Country, error)
 	GetTotalDataCountry(pagination dg.PaginationData, filter dm.CountryFilter) (int64, int64, error)
+	GetDuplicateName(data dm.Country) (*dm.Country, error)
+	InsertCountry(tx *sql.Tx, data dm.Country) (int64, error)
+	UpdateCountry(tx *sql.Tx, data dm.Country) error
+	DeactivateCountry(tx *sql.Tx, countryID int64) error
 }
 
 func (cr CountryRepo) GetByID(countryID int64) (dm.Country, error) {
@@ -92,30 +120,31 @@ func (cr CountryRepo) GetByName(countryName string) (dm.Country, error) {
 	return res, nil
 }
 
-func (cr CountryRepo) GetListCountry(pagination dg.PaginationData, filter dm.CountryFilter) ([]dm.Country, error) {
-	var result []dm.Country
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, prqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
+// countrySortable is the field that can be used to sort the country list, id is the alias of country_id.
+var countrySortable = listquery.Sortable{
+	Columns: map[string]string{
+		"id":         "country_id",
+		"country_id": "country_id",
+		"name":       "name",
+		"code":       "code",
+	},
+	Key: "country_id",
+}
 
-	q := prqSelectCountry
+func (cr CountryRepo) listQuery(filter dm.CountryFilter) listquery.Query {
+	var lq listquery.Query
+	lq.Contains("name", filter.Name)
+	lq.Bool("is_active", filter.IsActive)
 
-	if len(fl) > 0 {
-		q += prqWhere + strings.Join(fl, " AND ")
-	}
+	return lq
+}
 
-	// Add orderby value.
-	q += " " + prqOrderBy + " " + pagination.OrderBy.String + " " + strings.ToLower(pagination.Sort)
+func (cr CountryRepo) GetListCountry(pagination dg.PaginationData, filter dm.CountryFilter) ([]dm.Country, error) {
+	var result []dm.Country
 
-	if !pagination.IsGetAll {
-		// Add limit & page to param.
-		q += prqLimitOffset
-		param = append(param, pagination.Limit)
-		param = append(param, pagination.Offset)
+	q, param, err := cr.listQuery(filter).Select(prqSelectCountry, countrySortable, pagination)
+	if err != nil {
+		return result, err
 	}
 
 	query, args, err := cr.DBList.Backend.Read.In(q, param...)
@@ -134,20 +163,8 @@ func (cr CountryRepo) GetListCountry(pagination dg.PaginationData, filter dm.Cou
 
 func (cr CountryRepo) GetTotalDataCountry(pagination dg.PaginationData, filter dm.CountryFilter) (int64, int64, error) {
 	var result int64
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, prqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
-
-	q := prqCountCountry
-
-	if len(fl) > 0 {
-		q += prqWhere + strings.Join(fl, " AND ")
-	}
 
+	q, param := cr.listQuery(filter).Count(prqCountCountry)
 	query, args, err := cr.DBList.Backend.Read.In(q, param...)
 	if err != nil {
 		return result, 0, err
@@ -160,11 +177,44 @@ func (cr CountryRepo) GetTotalDataCountry(pagination dg.PaginationData, filter d
 		return result, 0, err
 	}
 
-	//Calculate Total Page
-	totalPage := result / int64(pagination.Limit)
-	if result%int64(pagination.Limit) > 0 {
-		totalPage++
+	return result, listquery.TotalPage(result, pagination.Limit), nil
+}
+
+// GetDuplicateName return the other country with the same name, nil when not found.
+func (cr CountryRepo) GetDuplicateName(data dm.Country) (*dm.Country, error) {
+	var res dm.Country
+
+	q := fmt.Sprintf("%s%s%s AND%s", prqSelectCountry, prqWhere, prqFilterSameName, prqFilterNotCountryID)
+	query, args, err := cr.DBList.Backend.Read.In(q, data.Name, data.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = cr.DBList.Backend.Read.Rebind(query)
+	err = cr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
 	}
 
-	return result, totalPage, nil
+	return &res, nil
+}
+
+func (cr CountryRepo) InsertCountry(tx *sql.Tx, data dm.Country) (int64, error) {
+	return insertReturningID(cr.DBList, tx, prqInsertCountry, data.Name, data.Code, data.IsActive)
+}
+
+// UpdateCountry return sql.ErrNoRows when the country is not exist.
+func (cr CountryRepo) UpdateCountry(tx *sql.Tx, data dm.Country) error {
+	q := fmt.Sprintf("%s%s%s%s", prqUpdateCountry, prqSetCountry, prqWhere, prqFilterCountryID)
+	return execWrite(cr.DBList, tx, q, data.Name, data.Code, data.IsActive, data.ID)
+}
+
+// DeactivateCountry return sql.ErrNoRows when the country is not exist.
+func (cr CountryRepo) DeactivateCountry(tx *sql.Tx, countryID int64) error {
+	q := fmt.Sprintf("%s%s%s%s", prqUpdateCountry, prqSetInactive, prqWhere, prqFilterCountryID)
+	return execWrite(cr.DBList, tx, q, countryID)
 }
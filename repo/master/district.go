@@ -1,12 +1,14 @@
 package master
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 
 	dg "github.com/furee/backend/domain/general"
 	dm "github.com/furee/backend/domain/master"
 	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo/listquery"
 )
 
 type DistrictRepo struct {
@@ -25,7 +27,9 @@ const (
 		district_id,
 		city_id,
 		name,
-		metadata
+		metadata,
+		code,
+		is_active
 	FROM
 		districts`
 
@@ -47,20 +51,59 @@ const (
 	dqFilterName = `
 		lower(name) LIKE ?`
 
-	dqLimitOffset = `
-	LIMIT ?
-	OFFSET ?`
+	dqFilterCityIDs = `
+		city_id IN (?)`
 
-	dqOrderBy = `
-	ORDER BY`
+	dqFilterIsActive = `
+		is_active = ?`
+
+	dqFilterSameName = `
+		lower(name) = lower(?)`
+
+	dqFilterNotDistrictID = `
+		district_id <> ?`
+
+	dqOrderByName = `
+	ORDER BY
+		name ASC`
+
+	dqInsertDistrict = `
+	INSERT INTO districts (
+		city_id,
+		name,
+		metadata,
+		code,
+		is_active
+	) VALUES (?, ?, ?, ?, ?)
+	RETURNING district_id`
+
+	dqUpdateDistrict = `
+	UPDATE
+		districts
+	SET`
+
+	dqSetDistrict = `
+		city_id = ?,
+		name = ?,
+		metadata = ?,
+		code = ?,
+		is_active = ?`
+
+	dqSetInactive = `
+		is_active = false`
 )
 
 type DistrictRepoItf interface {
 	GetByID(districtID int64) (dm.District, error)
 	GetByCityID(districtID int64) ([]dm.District, error)
+	GetByCityIDs(cityIDs []int64) ([]dm.District, error)
 	GetByName(name string) (dm.District, error)
 	GetListDistrict(pagination dg.PaginationData, filter dm.DistrictFilter) ([]dm.District, error)
 	GetTotalDataDistrict(pagination dg.PaginationData, filter dm.DistrictFilter) (int64, int64, error)
+	GetDuplicateName(data dm.District) (*dm.District, error)
+	InsertDistrict(tx *sql.Tx, data dm.District) (int64, error)
+	UpdateDistrict(tx *sql.Tx, data dm.District) error
+	DeactivateDistrict(tx *sql.Tx, districtID int64) error
 }
 
 func (dr DistrictRepo) GetByID(districtID int64) (dm.District, error) {
@@ -99,6 +142,29 @@ func (dr DistrictRepo) GetByCityID(districtID int64) ([]dm.District, error) {
 	return res, nil
 }
 
+// GetByCityIDs return the active district of all the parent at once, used to build the location tree.
+func (dr DistrictRepo) GetByCityIDs(cityIDs []int64) ([]dm.District, error) {
+	var res []dm.District
+
+	if len(cityIDs) == 0 {
+		return res, nil
+	}
+
+	q := fmt.Sprintf("%s%s%s AND%s%s", dqSelectDistrict, dqWhere, dqFilterCityIDs, dqFilterIsActive, dqOrderByName)
+	query, args, err := dr.DBList.Backend.Read.In(q, cityIDs, true)
+	if err != nil {
+		return res, err
+	}
+
+	query = dr.DBList.Backend.Read.Rebind(query)
+	err = dr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
 func (dr DistrictRepo) GetByName(name string) (dm.District, error) {
 	var res dm.District
 
@@ -117,35 +183,33 @@ func (dr DistrictRepo) GetByName(name string) (dm.District, error) {
 	return res, nil
 }
 
-func (dr DistrictRepo) GetListDistrict(pagination dg.PaginationData, filter dm.DistrictFilter) ([]dm.District, error) {
-	var result []dm.District
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, dqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
-
-	if filter.CityID.Valid {
-		fl = append(fl, dqFilterCityID)
-		param = append(param, filter.CityID.Int64)
-	}
+// districtSortable is the field that can be used to sort the district list, id is the alias of district_id.
+var districtSortable = listquery.Sortable{
+	Columns: map[string]string{
+		"id":          "district_id",
+		"district_id": "district_id",
+		"city_id":     "city_id",
+		"name":        "name",
+		"code":        "code",
+	},
+	Key: "district_id",
+}
 
-	q := dqSelectDistrict
+func (dr DistrictRepo) listQuery(filter dm.DistrictFilter) listquery.Query {
+	var lq listquery.Query
+	lq.Contains("name", filter.Name)
+	lq.Int("city_id", filter.CityID)
+	lq.Bool("is_active", filter.IsActive)
 
-	if len(fl) > 0 {
-		q += dqWhere + strings.Join(fl, " AND ")
-	}
+	return lq
+}
 
-	// Add orderby value.
-	q += " " + dqOrderBy + " " + pagination.OrderBy.String + " " + strings.ToLower(pagination.Sort)
+func (dr DistrictRepo) GetListDistrict(pagination dg.PaginationData, filter dm.DistrictFilter) ([]dm.District, error) {
+	var result []dm.District
 
-	if !pagination.IsGetAll {
-		// Add limit & page to param.
-		q += dqLimitOffset
-		param = append(param, pagination.Limit)
-		param = append(param, pagination.Offset)
+	q, param, err := dr.listQuery(filter).Select(dqSelectDistrict, districtSortable, pagination)
+	if err != nil {
+		return result, err
 	}
 
 	query, args, err := dr.DBList.Backend.Read.In(q, param...)
@@ -164,25 +228,8 @@ func (dr DistrictRepo) GetListDistrict(pagination dg.PaginationData, filter dm.D
 
 func (dr DistrictRepo) GetTotalDataDistrict(pagination dg.PaginationData, filter dm.DistrictFilter) (int64, int64, error) {
 	var result int64
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, dqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
-
-	if filter.CityID.Valid {
-		fl = append(fl, dqFilterCityID)
-		param = append(param, filter.CityID.Int64)
-	}
-
-	q := dqCountDistrict
-
-	if len(fl) > 0 {
-		q += dqWhere + strings.Join(fl, " AND ")
-	}
 
+	q, param := dr.listQuery(filter).Count(dqCountDistrict)
 	query, args, err := dr.DBList.Backend.Read.In(q, param...)
 	if err != nil {
 		return result, 0, err
@@ -195,11 +242,44 @@ func (dr DistrictRepo) GetTotalDataDistrict(pagination dg.PaginationData, filter
 		return result, 0, err
 	}
 
-	//Calculate Total Page
-	totalPage := result / int64(pagination.Limit)
-	if result%int64(pagination.Limit) > 0 {
-		totalPage++
+	return result, listquery.TotalPage(result, pagination.Limit), nil
+}
+
+// GetDuplicateName return the other district with the same name on the city, nil when not found.
+func (dr DistrictRepo) GetDuplicateName(data dm.District) (*dm.District, error) {
+	var res dm.District
+
+	q := fmt.Sprintf("%s%s%s AND%s AND%s", dqSelectDistrict, dqWhere, dqFilterCityID, dqFilterSameName, dqFilterNotDistrictID)
+	query, args, err := dr.DBList.Backend.Read.In(q, data.CityID, data.Name, data.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = dr.DBList.Backend.Read.Rebind(query)
+	err = dr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
 	}
 
-	return result, totalPage, nil
+	return &res, nil
+}
+
+func (dr DistrictRepo) InsertDistrict(tx *sql.Tx, data dm.District) (int64, error) {
+	return insertReturningID(dr.DBList, tx, dqInsertDistrict, data.CityID, data.Name, data.Metadata, data.Code, data.IsActive)
+}
+
+// UpdateDistrict return sql.ErrNoRows when the district is not exist.
+func (dr DistrictRepo) UpdateDistrict(tx *sql.Tx, data dm.District) error {
+	q := fmt.Sprintf("%s%s%s%s", dqUpdateDistrict, dqSetDistrict, dqWhere, dqFilterDistrictID)
+	return execWrite(dr.DBList, tx, q, data.CityID, data.Name, data.Metadata, data.Code, data.IsActive, data.ID)
+}
+
+// DeactivateDistrict return sql.ErrNoRows when the district is not exist.
+func (dr DistrictRepo) DeactivateDistrict(tx *sql.Tx, districtID int64) error {
+	q := fmt.Sprintf("%s%s%s%s", dqUpdateDistrict, dqSetInactive, dqWhere, dqFilterDistrictID)
+	return execWrite(dr.DBList, tx, q, districtID)
 }
@@ -0,0 +1,64 @@
+package master
+
+import (
+	"database/sql"
+
+	"github.com/furee/backend/infra"
+)
+
+// insertReturningID run the insert query that return the id, on the tx when it's not nil.
+func insertReturningID(dbList *infra.DatabaseList, tx *sql.Tx, q string, param ...interface{}) (int64, error) {
+	query, args, err := dbList.Backend.Write.In(q, param...)
+	if err != nil {
+		return 0, err
+	}
+
+	query = dbList.Backend.Write.Rebind(query)
+
+	var res *sql.Row
+	if tx == nil {
+		res = dbList.Backend.Write.QueryRow(query, args...)
+	} else {
+		res = tx.QueryRow(query, args...)
+	}
+
+	var id int64
+	err = res.Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// execWrite return sql.ErrNoRows when no row is affected.
+func execWrite(dbList *infra.DatabaseList, tx *sql.Tx, q string, param ...interface{}) error {
+	query, args, err := dbList.Backend.Write.In(q, param...)
+	if err != nil {
+		return err
+	}
+
+	query = dbList.Backend.Write.Rebind(query)
+
+	var res sql.Result
+	if tx == nil {
+		res, err = dbList.Backend.Write.Exec(query, args...)
+	} else {
+		res, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
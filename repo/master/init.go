@@ -11,6 +11,7 @@ type MasterRepo struct {
 	District    DistrictRepoItf
 	City        CityRepoItf
 	Province    ProvinceRepoItf
+	Location    LocationRepoItf
 }
 
 func NewMasterRepo(db *infra.DatabaseList, logger *logrus.Logger) MasterRepo {
@@ -20,5 +21,6 @@ func NewMasterRepo(db *infra.DatabaseList, logger *logrus.Logger) MasterRepo {
 		District:    newDistrictRepo(db),
 		City:        newCityRepo(db),
 		Province:    newProvinceRepo(db),
+		Location:    newLocationRepo(db),
 	}
 }
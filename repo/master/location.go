@@ -0,0 +1,73 @@
+package master
+
+import (
+	"database/sql"
+	"fmt"
+
+	dm "github.com/furee/backend/domain/master"
+	"github.com/furee/backend/infra"
+)
+
+type LocationRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newLocationRepo(dbList *infra.DatabaseList) LocationRepo {
+	return LocationRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	lqSelectPath = `
+	SELECT
+		c.country_id,
+		c.name AS country_name,
+		p.province_id,
+		p.name AS province_name,
+		ct.city_id,
+		ct.name AS city_name,
+		d.district_id,
+		d.name AS district_name,
+		sd.sub_district_id,
+		sd.name AS sub_district_name
+	FROM
+		sub_districts sd
+		JOIN districts d ON d.district_id = sd.district_id
+		JOIN cities ct ON ct.city_id = d.city_id
+		JOIN provinces p ON p.province_id = ct.province_id
+		JOIN countries c ON c.country_id = p.country_id`
+
+	lqWhere = `
+	WHERE`
+
+	lqFilterSubDistrictID = `
+		sd.sub_district_id = ?`
+)
+
+type LocationRepoItf interface {
+	GetPathBySubDistrictID(subDistrictID int64) (*dm.LocationPath, error)
+}
+
+// GetPathBySubDistrictID return nil when the sub district is not found.
+func (lr LocationRepo) GetPathBySubDistrictID(subDistrictID int64) (*dm.LocationPath, error) {
+	var res dm.LocationPath
+
+	q := fmt.Sprintf("%s%s%s", lqSelectPath, lqWhere, lqFilterSubDistrictID)
+	query, args, err := lr.DBList.Backend.Read.In(q, subDistrictID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = lr.DBList.Backend.Read.Rebind(query)
+	err = lr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &res, nil
+}
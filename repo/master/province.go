@@ -1,12 +1,14 @@
 package master
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 
 	dg "github.com/furee/backend/domain/general"
 	dm "github.com/furee/backend/domain/master"
 	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo/listquery"
 )
 
 type ProvinceRepo struct {
@@ -24,7 +26,9 @@ const (
 	SELECT
 		province_id,
 		country_id,
-		name
+		name,
+		code,
+		is_active
 	FROM
 		provinces`
 
@@ -46,20 +50,57 @@ const (
 	pqFilterName = `
 		lower(name) LIKE ?`
 
-	pqLimitOffset = `
-	LIMIT ?
-	OFFSET ?`
+	pqFilterCountryIDs = `
+		country_id IN (?)`
 
-	pqOrderBy = `
-	ORDER BY`
+	pqFilterIsActive = `
+		is_active = ?`
+
+	pqFilterSameName = `
+		lower(name) = lower(?)`
+
+	pqFilterNotProvinceID = `
+		province_id <> ?`
+
+	pqOrderByName = `
+	ORDER BY
+		name ASC`
+
+	pqInsertProvince = `
+	INSERT INTO provinces (
+		country_id,
+		name,
+		code,
+		is_active
+	) VALUES (?, ?, ?, ?)
+	RETURNING province_id`
+
+	pqUpdateProvince = `
+	UPDATE
+		provinces
+	SET`
+
+	pqSetProvince = `
+		country_id = ?,
+		name = ?,
+		code = ?,
+		is_active = ?`
+
+	pqSetInactive = `
+		is_active = false`
 )
 
 type ProvinceRepoItf interface {
 	GetByID(provinceID int64) (dm.Province, error)
 	GetByCountryID(countryID int64) ([]dm.Province, error)
+	GetByCountryIDs(countryIDs []int64) ([]dm.Province, error)
 	GetByName(name string) (dm.Province, error)
 	GetListProvince(pagination dg.PaginationData, filter dm.ProvinceFilter) ([]dm.Province, error)
 	GetTotalDataProvince(pagination dg.PaginationData, filter dm.ProvinceFilter) (int64, int64, error)
+	GetDuplicateName(data dm.Province) (*dm.Province, error)
+	InsertProvince(tx *sql.Tx, data dm.Province) (int64, error)
+	UpdateProvince(tx *sql.Tx, data dm.Province) error
+	DeactivateProvince(tx *sql.Tx, provinceID int64) error
 }
 
 func (pr ProvinceRepo) GetByID(provinceID int64) (dm.Province, error) {
@@ -98,6 +139,29 @@ func (pr ProvinceRepo) GetByCountryID(countryID int64) ([]dm.Province, error) {
 	return res, nil
 }
 
+// GetByCountryIDs return the active province of all the parent at once, used to build the location tree.
+func (pr ProvinceRepo) GetByCountryIDs(countryIDs []int64) ([]dm.Province, error) {
+	var res []dm.Province
+
+	if len(countryIDs) == 0 {
+		return res, nil
+	}
+
+	q := fmt.Sprintf("%s%s%s AND%s%s", pqSelectProvince, pqWhere, pqFilterCountryIDs, pqFilterIsActive, pqOrderByName)
+	query, args, err := pr.DBList.Backend.Read.In(q, countryIDs, true)
+	if err != nil {
+		return res, err
+	}
+
+	query = pr.DBList.Backend.Read.Rebind(query)
+	err = pr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
 func (pr ProvinceRepo) GetByName(name string) (dm.Province, error) {
 	var res dm.Province
 
@@ -116,35 +180,33 @@ func (pr ProvinceRepo) GetByName(name string) (dm.Province, error) {
 	return res, nil
 }
 
-func (pr ProvinceRepo) GetListProvince(pagination dg.PaginationData, filter dm.ProvinceFilter) ([]dm.Province, error) {
-	var result []dm.Province
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, pqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
-
-	if filter.CountryID.Valid {
-		fl = append(fl, pqFilterCountryID)
-		param = append(param, filter.CountryID.Int64)
-	}
+// provinceSortable is the field that can be used to sort the province list, id is the alias of province_id.
+var provinceSortable = listquery.Sortable{
+	Columns: map[string]string{
+		"id":          "province_id",
+		"province_id": "province_id",
+		"country_id":  "country_id",
+		"name":        "name",
+		"code":        "code",
+	},
+	Key: "province_id",
+}
 
-	q := pqSelectProvince
+func (pr ProvinceRepo) listQuery(filter dm.ProvinceFilter) listquery.Query {
+	var lq listquery.Query
+	lq.Contains("name", filter.Name)
+	lq.Int("country_id", filter.CountryID)
+	lq.Bool("is_active", filter.IsActive)
 
-	if len(fl) > 0 {
-		q += pqWhere + strings.Join(fl, " AND ")
-	}
+	return lq
+}
 
-	// Add orderby value.
-	q += " " + pqOrderBy + " " + pagination.OrderBy.String + " " + strings.ToLower(pagination.Sort)
+func (pr ProvinceRepo) GetListProvince(pagination dg.PaginationData, filter dm.ProvinceFilter) ([]dm.Province, error) {
+	var result []dm.Province
 
-	if !pagination.IsGetAll {
-		// Add limit & page to param.
-		q += pqLimitOffset
-		param = append(param, pagination.Limit)
-		param = append(param, pagination.Offset)
+	q, param, err := pr.listQuery(filter).Select(pqSelectProvince, provinceSortable, pagination)
+	if err != nil {
+		return result, err
 	}
 
 	query, args, err := pr.DBList.Backend.Read.In(q, param...)
@@ -163,25 +225,8 @@ func (pr ProvinceRepo) GetListProvince(pagination dg.PaginationData, filter dm.P
 
 func (pr ProvinceRepo) GetTotalDataProvince(pagination dg.PaginationData, filter dm.ProvinceFilter) (int64, int64, error) {
 	var result int64
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, pqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
-
-	if filter.CountryID.Valid {
-		fl = append(fl, pqFilterCountryID)
-		param = append(param, filter.CountryID.Int64)
-	}
-
-	q := pqCountProvince
-
-	if len(fl) > 0 {
-		q += dqWhere + strings.Join(fl, " AND ")
-	}
 
+	q, param := pr.listQuery(filter).Count(pqCountProvince)
 	query, args, err := pr.DBList.Backend.Read.In(q, param...)
 	if err != nil {
 		return result, 0, err
@@ -194,11 +239,44 @@ func (pr ProvinceRepo) GetTotalDataProvince(pagination dg.PaginationData, filter
 		return result, 0, err
 	}
 
-	//Calculate Total Page
-	totalPage := result / int64(pagination.Limit)
-	if result%int64(pagination.Limit) > 0 {
-		totalPage++
+	return result, listquery.TotalPage(result, pagination.Limit), nil
+}
+
+// GetDuplicateName return the other province with the same name on the country, nil when not found.
+func (pr ProvinceRepo) GetDuplicateName(data dm.Province) (*dm.Province, error) {
+	var res dm.Province
+
+	q := fmt.Sprintf("%s%s%s AND%s AND%s", pqSelectProvince, pqWhere, pqFilterCountryID, pqFilterSameName, pqFilterNotProvinceID)
+	query, args, err := pr.DBList.Backend.Read.In(q, data.CountryID, data.Name, data.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = pr.DBList.Backend.Read.Rebind(query)
+	err = pr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
 	}
 
-	return result, totalPage, nil
+	return &res, nil
+}
+
+func (pr ProvinceRepo) InsertProvince(tx *sql.Tx, data dm.Province) (int64, error) {
+	return insertReturningID(pr.DBList, tx, pqInsertProvince, data.CountryID, data.Name, data.Code, data.IsActive)
+}
+
+// UpdateProvince return sql.ErrNoRows when the province is not exist.
+func (pr ProvinceRepo) UpdateProvince(tx *sql.Tx, data dm.Province) error {
+	q := fmt.Sprintf("%s%s%s%s", pqUpdateProvince, pqSetProvince, pqWhere, pqFilterProvinceID)
+	return execWrite(pr.DBList, tx, q, data.CountryID, data.Name, data.Code, data.IsActive, data.ID)
+}
+
+// DeactivateProvince return sql.ErrNoRows when the province is not exist.
+func (pr ProvinceRepo) DeactivateProvince(tx *sql.Tx, provinceID int64) error {
+	q := fmt.Sprintf("%s%s%s%s", pqUpdateProvince, pqSetInactive, pqWhere, pqFilterProvinceID)
+	return execWrite(pr.DBList, tx, q, provinceID)
 }
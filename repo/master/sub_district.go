@@ -1,12 +1,14 @@
 package master
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 
 	dg "github.com/furee/backend/domain/general"
 	dm "github.com/furee/backend/domain/master"
 	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo/listquery"
 )
 
 type SubDistrictRepo struct {
@@ -24,7 +26,9 @@ const (
 	SELECT
 		sub_district_id,
 		district_id,
-		name
+		name,
+		code,
+		is_active
 	FROM
 		sub_districts`
 
@@ -46,20 +50,57 @@ const (
 	sdqFilterName = `
 		lower(name) LIKE ?`
 
-	sdqLimitOffset = `
-	LIMIT ?
-	OFFSET ?`
+	sdqFilterDistrictIDs = `
+		district_id IN (?)`
 
-	sdqOrderBy = `
-	ORDER BY`
+	sdqFilterIsActive = `
+		is_active = ?`
+
+	sdqFilterSameName = `
+		lower(name) = lower(?)`
+
+	sdqFilterNotSubDistrictID = `
+		sub_district_id <> ?`
+
+	sdqOrderByName = `
+	ORDER BY
+		name ASC`
+
+	sdqInsertSubDistrict = `
+	INSERT INTO sub_districts (
+		district_id,
+		name,
+		code,
+		is_active
+	) VALUES (?, ?, ?, ?)
+	RETURNING sub_district_id`
+
+	sdqUpdateSubDistrict = `
+	UPDATE
+		sub_districts
+	SET`
+
+	sdqSetSubDistrict = `
+		district_id = ?,
+		name = ?,
+		code = ?,
+		is_active = ?`
+
+	sdqSetInactive = `
+		is_active = false`
 )
 
 type SubDistrictRepoItf interface {
 	GetByID(subDistrictID int64) (dm.SubDistrict, error)
 	GetByDistrictID(districtID int64) ([]dm.SubDistrict, error)
+	GetByDistrictIDs(districtIDs []int64) ([]dm.SubDistrict, error)
 	GetByName(name string) (dm.SubDistrict, error)
 	GetListSubDistrict(pagination dg.PaginationData, filter dm.SubDistrictFilter) ([]dm.SubDistrict, error)
 	GetTotalDataSubDistrict(pagination dg.PaginationData, filter dm.SubDistrictFilter) (int64, int64, error)
+	GetDuplicateName(data dm.SubDistrict) (*dm.SubDistrict, error)
+	InsertSubDistrict(tx *sql.Tx, data dm.SubDistrict) (int64, error)
+	UpdateSubDistrict(tx *sql.Tx, data dm.SubDistrict) error
+	DeactivateSubDistrict(tx *sql.Tx, subDistrictID int64) error
 }
 
 func (sdr SubDistrictRepo) GetByID(subDistrictID int64) (dm.SubDistrict, error) {
@@ -98,6 +139,29 @@ func (sdr SubDistrictRepo) GetByDistrictID(districtID int64) ([]dm.SubDistrict,
 	return res, nil
 }
 
+// GetByDistrictIDs return the active sub district of all the parent at once, used to build the location tree.
+func (sdr SubDistrictRepo) GetByDistrictIDs(districtIDs []int64) ([]dm.SubDistrict, error) {
+	var res []dm.SubDistrict
+
+	if len(districtIDs) == 0 {
+		return res, nil
+	}
+
+	q := fmt.Sprintf("%s%s%s AND%s%s", sdqSelectSubDistrict, sdqWhere, sdqFilterDistrictIDs, sdqFilterIsActive, sdqOrderByName)
+	query, args, err := sdr.DBList.Backend.Read.In(q, districtIDs, true)
+	if err != nil {
+		return res, err
+	}
+
+	query = sdr.DBList.Backend.Read.Rebind(query)
+	err = sdr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
 func (sdr SubDistrictRepo) GetByName(name string) (dm.SubDistrict, error) {
 	var res dm.SubDistrict
 
@@ -116,35 +180,33 @@ func (sdr SubDistrictRepo) GetByName(name string) (dm.SubDistrict, error) {
 	return res, nil
 }
 
-func (sdr SubDistrictRepo) GetListSubDistrict(pagination dg.PaginationData, filter dm.SubDistrictFilter) ([]dm.SubDistrict, error) {
-	var result []dm.SubDistrict
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, sdqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
-
-	if filter.DistrictID.Valid {
-		fl = append(fl, sdqFilterDistrictID)
-		param = append(param, filter.DistrictID.Int64)
-	}
+// subDistrictSortable is the field that can be used to sort the sub district list, id is the alias of sub_district_id.
+var subDistrictSortable = listquery.Sortable{
+	Columns: map[string]string{
+		"id":              "sub_district_id",
+		"sub_district_id": "sub_district_id",
+		"district_id":     "district_id",
+		"name":            "name",
+		"code":            "code",
+	},
+	Key: "sub_district_id",
+}
 
-	q := sdqSelectSubDistrict
+func (sdr SubDistrictRepo) listQuery(filter dm.SubDistrictFilter) listquery.Query {
+	var lq listquery.Query
+	lq.Contains("name", filter.Name)
+	lq.Int("district_id", filter.DistrictID)
+	lq.Bool("is_active", filter.IsActive)
 
-	if len(fl) > 0 {
-		q += sdqWhere + strings.Join(fl, " AND ")
-	}
+	return lq
+}
 
-	// Add orderby value.
-	q += " " + sdqOrderBy + " " + pagination.OrderBy.String + " " + strings.ToLower(pagination.Sort)
+func (sdr SubDistrictRepo) GetListSubDistrict(pagination dg.PaginationData, filter dm.SubDistrictFilter) ([]dm.SubDistrict, error) {
+	var result []dm.SubDistrict
 
-	if !pagination.IsGetAll {
-		// Add limit & page to param.
-		q += sdqLimitOffset
-		param = append(param, pagination.Limit)
-		param = append(param, pagination.Offset)
+	q, param, err := sdr.listQuery(filter).Select(sdqSelectSubDistrict, subDistrictSortable, pagination)
+	if err != nil {
+		return result, err
 	}
 
 	query, args, err := sdr.DBList.Backend.Read.In(q, param...)
@@ -163,25 +225,8 @@ func (sdr SubDistrictRepo) GetListSubDistrict(pagination dg.PaginationData, filt
 
 func (sdr SubDistrictRepo) GetTotalDataSubDistrict(pagination dg.PaginationData, filter dm.SubDistrictFilter) (int64, int64, error) {
 	var result int64
-	param := make([]interface{}, 0)
-	var fl []string
-
-	if filter.Name.Valid {
-		fl = append(fl, sdqFilterName)
-		param = append(param, "%"+strings.Title(strings.ToLower(filter.Name.String))+"%")
-	}
-
-	if filter.DistrictID.Valid {
-		fl = append(fl, sdqFilterDistrictID)
-		param = append(param, filter.DistrictID.Int64)
-	}
-
-	q := sdqCountSubDistrict
-
-	if len(fl) > 0 {
-		q += sdqWhere + strings.Join(fl, " AND ")
-	}
 
+	q, param := sdr.listQuery(filter).Count(sdqCountSubDistrict)
 	query, args, err := sdr.DBList.Backend.Read.In(q, param...)
 	if err != nil {
 		return result, 0, err
@@ -194,11 +239,44 @@ func (sdr SubDistrictRepo) GetTotalDataSubDistrict(pagination dg.PaginationData,
 		return result, 0, err
 	}
 
-	//Calculate Total Page
-	totalPage := result / int64(pagination.Limit)
-	if result%int64(pagination.Limit) > 0 {
-		totalPage++
+	return result, listquery.TotalPage(result, pagination.Limit), nil
+}
+
+// GetDuplicateName return the other sub district with the same name on the district, nil when not found.
+func (sdr SubDistrictRepo) GetDuplicateName(data dm.SubDistrict) (*dm.SubDistrict, error) {
+	var res dm.SubDistrict
+
+	q := fmt.Sprintf("%s%s%s AND%s AND%s", sdqSelectSubDistrict, sdqWhere, sdqFilterDistrictID, sdqFilterSameName, sdqFilterNotSubDistrictID)
+	query, args, err := sdr.DBList.Backend.Read.In(q, data.DistrictID, data.Name, data.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = sdr.DBList.Backend.Read.Rebind(query)
+	err = sdr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
 	}
 
-	return result, totalPage, nil
+	return &res, nil
+}
+
+func (sdr SubDistrictRepo) InsertSubDistrict(tx *sql.Tx, data dm.SubDistrict) (int64, error) {
+	return insertReturningID(sdr.DBList, tx, sdqInsertSubDistrict, data.DistrictID, data.Name, data.Code, data.IsActive)
+}
+
+// UpdateSubDistrict return sql.ErrNoRows when the sub district is not exist.
+func (sdr SubDistrictRepo) UpdateSubDistrict(tx *sql.Tx, data dm.SubDistrict) error {
+	q := fmt.Sprintf("%s%s%s%s", sdqUpdateSubDistrict, sdqSetSubDistrict, sdqWhere, sdqFilterSubDistrictID)
+	return execWrite(sdr.DBList, tx, q, data.DistrictID, data.Name, data.Code, data.IsActive, data.ID)
+}
+
+// DeactivateSubDistrict return sql.ErrNoRows when the sub district is not exist.
+func (sdr SubDistrictRepo) DeactivateSubDistrict(tx *sql.Tx, subDistrictID int64) error {
+	q := fmt.Sprintf("%s%s%s%s", sdqUpdateSubDistrict, sdqSetInactive, sdqWhere, sdqFilterSubDistrictID)
+	return execWrite(sdr.DBList, tx, q, subDistrictID)
 }
@@ -61,6 +61,12 @@ const (
 
 	uqFilterQuantity = `
 		quantity = ?`
+
+	uqFilterOrderIDs = `
+		order_id IN (?)`
+
+	uqOrderByItemID = `
+	ORDER BY item_id`
 )
 
 type ItemDataRepoItf interface {
@@ -68,6 +74,7 @@ type ItemDataRepoItf interface {
 	GetByIDAndOrderID(itemID int64, orderID int64) (*du.Item, error)
 	GetList() ([]du.Item, error)
 	GetListByOrderID(orderID int64) ([]du.Item, error)
+	GetListByOrderIDs(orderIDs []int64) ([]du.Item, error)
 	DeleteByOrderID(tx *sql.Tx, orderID int64) error
 	InsertItem(tx *sql.Tx, data du.Item) (int64, error)
 	UpdateItem(tx *sql.Tx, data du.Item) error
@@ -159,6 +166,29 @@ func (ur ItemDataRepo) GetListByOrderID(orderID int64) ([]du.Item, error) {
 	return res, nil
 }
 
+// GetListByOrderIDs return the items of the orders in one query, used by the order list.
+func (ur ItemDataRepo) GetListByOrderIDs(orderIDs []int64) ([]du.Item, error) {
+	var res []du.Item
+
+	if len(orderIDs) == 0 {
+		return res, nil
+	}
+
+	q := fmt.Sprintf("%s %s %s %s", uqSelectItem, uqWhere, uqFilterOrderIDs, uqOrderByItemID)
+	query, args, err := ur.DBList.Backend.Read.In(q, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	query = ur.DBList.Backend.Read.Rebind(query)
+	err = ur.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (ur ItemDataRepo) InsertItem(tx *sql.Tx, data du.Item) (int64, error) {
 	param := make([]interface{}, 0)
 
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"strings"
 
+	dg "github.com/furee/backend/domain/general"
 	du "github.com/furee/backend/domain/order"
 	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo/listquery"
 )
 
 type OrderDataRepo struct {
@@ -23,17 +25,25 @@ const (
 	uqSelectOrder = `
 	SELECT
 		order_id,
+		user_id,
 		customer_name,
 		ordered_at
 	FROM
 		orders`
 
+	uqCountOrder = `
+	SELECT
+		COUNT(1) as count
+	FROM
+		orders`
+
 	uqInsertOrder = `
 	INSERT INTO orders (
+		user_id,
 		customer_name,
 		ordered_at
 	) VALUES (
-		?, ?
+		?, ?, ?
 	)
 	RETURNING order_id`
 
@@ -58,11 +68,21 @@ const (
 
 	uqFilterOrderedAt = `
 		ordered_at = ?`
+
+	uqFilterUserID = `
+		user_id = ?`
+
+	uqOrderByOrderedAt = `
+	ORDER BY
+		ordered_at DESC`
 )
 
 type OrderDataRepoItf interface {
 	GetByID(orderID int64) (*du.Order, error)
-	GetList() ([]du.Order, error)
+	GetList(pagination dg.PaginationData, filter du.OrderFilter) ([]du.Order, error)
+	GetTotalData(pagination dg.PaginationData, filter du.OrderFilter) (int64, int64, error)
+	GetListByUserID(userID int64) ([]du.Order, error)
+	AnonymizeByUserID(tx *sql.Tx, userID int64, customerName string) error
 	DeleteByID(tx *sql.Tx, orderID int64) error
 	InsertOrder(tx *sql.Tx, data du.Order) (int64, error)
 	UpdateOrder(tx *sql.Tx, data du.Order) error
@@ -90,11 +110,35 @@ func (ur OrderDataRepo) GetByID(orderID int64) (*du.Order, error) {
 	return &res, nil
 }
 
-func (ur OrderDataRepo) GetList() ([]du.Order, error) {
-	var res []du.Order
+// orderSortable is the field that can be used to sort the order list, the newest order is shown first by default.
+var orderSortable = listquery.Sortable{
+	Columns: map[string]string{
+		"id":            "order_id",
+		"order_id":      "order_id",
+		"customer_name": "customer_name",
+		"ordered_at":    "ordered_at",
+	},
+	Default: []dg.SortData{{Field: "ordered_at", Desc: true}},
+	Key:     "order_id",
+}
+
+func (ur OrderDataRepo) listQuery(filter du.OrderFilter) listquery.Query {
+	var lq listquery.Query
+	lq.Int("user_id", filter.UserID)
+	lq.Contains("customer_name", filter.CustomerName)
+
+	return lq
+}
+
+func (ur OrderDataRepo) GetList(pagination dg.PaginationData, filter du.OrderFilter) ([]du.Order, error) {
+	res := make([]du.Order, 0)
 
-	// q := fmt.Sprintf("%s", uqSelectOrder)
-	query, args, err := ur.DBList.Backend.Read.In(uqSelectOrder)
+	q, param, err := ur.listQuery(filter).Select(uqSelectOrder, orderSortable, pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := ur.DBList.Backend.Read.In(q, param...)
 	if err != nil {
 		return nil, err
 	}
@@ -104,16 +148,72 @@ func (ur OrderDataRepo) GetList() ([]du.Order, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	return res, nil
+}
+
+func (ur OrderDataRepo) GetTotalData(pagination dg.PaginationData, filter du.OrderFilter) (int64, int64, error) {
+	var result int64
+
+	q, param := ur.listQuery(filter).Count(uqCountOrder)
+	query, args, err := ur.DBList.Backend.Read.In(q, param...)
 	if err != nil {
-		return res, err
+		return result, 0, err
+	}
+
+	query = ur.DBList.Backend.Read.Rebind(query)
+	err = ur.DBList.Backend.Read.Get(&result, query, args...)
+	if err != nil {
+		return result, 0, err
+	}
+
+	return result, listquery.TotalPage(result, pagination.Limit), nil
+}
+
+func (ur OrderDataRepo) GetListByUserID(userID int64) ([]du.Order, error) {
+	res := make([]du.Order, 0)
+
+	q := fmt.Sprintf("%s%s%s%s", uqSelectOrder, uqWhere, uqFilterUserID, uqOrderByOrderedAt)
+	query, args, err := ur.DBList.Backend.Read.In(q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = ur.DBList.Backend.Read.Rebind(query)
+	err = ur.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
 	}
 
 	return res, nil
 }
 
+// AnonymizeByUserID replace the customer name of the deleted user, the order & item is kept for accounting.
+func (ur OrderDataRepo) AnonymizeByUserID(tx *sql.Tx, userID int64, customerName string) error {
+	q := fmt.Sprintf("%s %s %s %s", uqUpdateOrder, uqFilterCustomerName, uqWhere, uqFilterUserID)
+	query, args, err := ur.DBList.Backend.Write.In(q, customerName, userID)
+	if err != nil {
+		return err
+	}
+
+	query = ur.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = ur.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (ur OrderDataRepo) InsertOrder(tx *sql.Tx, data du.Order) (int64, error) {
 	param := make([]interface{}, 0)
 
+	param = append(param, data.UserID)
 	param = append(param, strings.Title(strings.ToLower(data.CustomerName)))
 	param = append(param, data.OrderedAt)
 
@@ -6,11 +6,17 @@ import (
 )
 
 type UserRepo struct {
-	User UserDataRepoItf
+	User        UserDataRepoItf
+	OTPDelivery OTPDeliveryRepoItf
+	Whitelist   OTPWhitelistRepoItf
+	Job         UserJobRepoItf
 }
 
 func NewMasterRepo(db *infra.DatabaseList, logger *logrus.Logger) UserRepo {
 	return UserRepo{
-		User: newUserDataRepo(db),
+		User:        newUserDataRepo(db),
+		OTPDelivery: newOTPDeliveryRepo(db),
+		Whitelist:   newOTPWhitelistRepo(db),
+		Job:         newUserJobRepo(db),
 	}
 }
@@ -0,0 +1,343 @@
+package user
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	cu "github.com/furee/backend/constants/user"
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/infra"
+)
+
+type UserJobRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newUserJobRepo(dbList *infra.DatabaseList) UserJobRepo {
+	return UserJobRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	ujqSelectJob = `
+	SELECT
+		user_job_id,
+		user_id,
+		job_type,
+		status,
+		run_at,
+		result,
+		error,
+		attempt,
+		finished_at,
+		created_at,
+		updated_at
+	FROM
+		user_jobs`
+
+	ujqInsertJob = `
+	INSERT INTO user_jobs (
+		user_id,
+		job_type,
+		status,
+		run_at,
+		attempt,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, 0, ?
+	)
+	RETURNING user_job_id`
+
+	// ujqClaimJob lock the next due job, SKIP LOCKED let several worker run together.
+	// The running job that is not updated since the timeout is claimed again, the worker is assumed to be dead.
+	ujqClaimJob = `
+	UPDATE
+		user_jobs
+	SET
+		status = ?,
+		attempt = attempt + 1,
+		updated_at = NOW()
+	WHERE
+		user_job_id = (
+			SELECT
+				user_job_id
+			FROM
+				user_jobs
+			WHERE
+				(status = ? AND run_at <= ?)
+				OR (status = ? AND updated_at < ?)
+			ORDER BY
+				run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+	RETURNING
+		user_job_id,
+		user_id,
+		job_type,
+		status,
+		run_at,
+		result,
+		error,
+		attempt,
+		finished_at,
+		created_at,
+		updated_at`
+
+	ujqUpdateJob = `
+	UPDATE
+		user_jobs
+	SET
+		updated_at = NOW()`
+
+	ujqSetStatus = `
+		status = ?`
+
+	ujqSetResult = `
+		result = ?`
+
+	ujqSetError = `
+		error = ?`
+
+	ujqSetRunAt = `
+		run_at = ?`
+
+	ujqSetFinished = `
+		finished_at = NOW()`
+
+	ujqSetClearResult = `
+		result = NULL`
+
+	ujqWhere = `
+	WHERE`
+
+	ujqFilterJobID = `
+		user_job_id = ?`
+
+	ujqFilterUserID = `
+		user_id = ?`
+
+	ujqFilterJobType = `
+		job_type = ?`
+
+	ujqFilterStatusIn = `
+		status IN (?)`
+
+	ujqFilterStatus = `
+		status = ?`
+
+	ujqFilterFinishedBefore = `
+		finished_at < ?`
+
+	ujqOrderLatest = `
+	ORDER BY
+		created_at DESC
+	LIMIT 1`
+)
+
+type UserJobRepoItf interface {
+	GetByID(jobID int64) (*du.UserJob, error)
+	GetActiveJob(userID int64, jobType string) (*du.UserJob, error)
+	InsertJob(tx *sql.Tx, data du.UserJob) (int64, error)
+	GetExpiredJobs(jobType string, finishedBefore time.Time) ([]du.UserJob, error)
+	ClaimNextJob(now, staleBefore time.Time) (*du.UserJob, error)
+	CompleteJob(tx *sql.Tx, jobID int64, result string) error
+	FailJob(jobID int64, errMessage string, retryAt *time.Time) error
+	CancelJob(tx *sql.Tx, jobID int64) error
+	ExpireJob(tx *sql.Tx, jobID int64) error
+	ExpireUserJobs(tx *sql.Tx, userID int64, jobType string) error
+}
+
+func (jr UserJobRepo) GetByID(jobID int64) (*du.UserJob, error) {
+	q := fmt.Sprintf("%s%s%s", ujqSelectJob, ujqWhere, ujqFilterJobID)
+	return jr.get(q, jobID)
+}
+
+// GetActiveJob return the latest pending or running job of the type, nil when there is none.
+func (jr UserJobRepo) GetActiveJob(userID int64, jobType string) (*du.UserJob, error) {
+	q := fmt.Sprintf("%s%s%s AND %s AND %s%s", ujqSelectJob, ujqWhere, ujqFilterUserID, ujqFilterJobType, ujqFilterStatusIn, ujqOrderLatest)
+	return jr.get(q, userID, jobType, []string{cu.JobStatusPending, cu.JobStatusRunning})
+}
+
+// GetExpiredJobs return the done job of the type that finished before the time.
+func (jr UserJobRepo) GetExpiredJobs(jobType string, finishedBefore time.Time) ([]du.UserJob, error) {
+	var res []du.UserJob
+
+	q := fmt.Sprintf("%s%s%s AND %s AND %s", ujqSelectJob, ujqWhere, ujqFilterJobType, ujqFilterStatus, ujqFilterFinishedBefore)
+	query, args, err := jr.DBList.Backend.Read.In(q, jobType, cu.JobStatusDone, finishedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	query = jr.DBList.Backend.Read.Rebind(query)
+	err = jr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (jr UserJobRepo) InsertJob(tx *sql.Tx, data du.UserJob) (int64, error) {
+	param := make([]interface{}, 0)
+
+	param = append(param, data.UserID)
+	param = append(param, data.JobType)
+	param = append(param, cu.JobStatusPending)
+	param = append(param, data.RunAt)
+	param = append(param, time.Now().UTC())
+
+	query, args, err := jr.DBList.Backend.Write.In(ujqInsertJob, param...)
+	if err != nil {
+		return 0, err
+	}
+
+	query = jr.DBList.Backend.Write.Rebind(query)
+
+	var res *sql.Row
+	if tx == nil {
+		res = jr.DBList.Backend.Write.QueryRow(query, args...)
+	} else {
+		res = tx.QueryRow(query, args...)
+	}
+
+	err = res.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	var jobID int64
+	err = res.Scan(&jobID)
+	if err != nil {
+		return 0, err
+	}
+
+	return jobID, nil
+}
+
+// ClaimNextJob mark the next due job as running & return it, nil when there is no due job.
+// The running job that is not updated since staleBefore is claimed again.
+func (jr UserJobRepo) ClaimNextJob(now, staleBefore time.Time) (*du.UserJob, error) {
+	var res du.UserJob
+
+	query, args, err := jr.DBList.Backend.Write.In(ujqClaimJob, cu.JobStatusRunning, cu.JobStatusPending, now, cu.JobStatusRunning, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	query = jr.DBList.Backend.Write.Rebind(query)
+	err = jr.DBList.Backend.Write.Get(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if res.ID == 0 {
+		return nil, nil
+	}
+
+	return &res, nil
+}
+
+func (jr UserJobRepo) CompleteJob(tx *sql.Tx, jobID int64, result string) error {
+	q := fmt.Sprintf("%s, %s, %s, %s %s%s", ujqUpdateJob, ujqSetStatus, ujqSetResult, ujqSetFinished, ujqWhere, ujqFilterJobID)
+	return jr.exec(tx, q, cu.JobStatusDone, result, jobID)
+}
+
+// FailJob put the job back to pending until retryAt, the job is failed for good when retryAt is nil.
+func (jr UserJobRepo) FailJob(jobID int64, errMessage string, retryAt *time.Time) error {
+	if retryAt != nil {
+		q := fmt.Sprintf("%s, %s, %s, %s %s%s", ujqUpdateJob, ujqSetStatus, ujqSetError, ujqSetRunAt, ujqWhere, ujqFilterJobID)
+		return jr.exec(nil, q, cu.JobStatusPending, errMessage, *retryAt, jobID)
+	}
+
+	q := fmt.Sprintf("%s, %s, %s, %s %s%s", ujqUpdateJob, ujqSetStatus, ujqSetError, ujqSetFinished, ujqWhere, ujqFilterJobID)
+	return jr.exec(nil, q, cu.JobStatusFailed, errMessage, jobID)
+}
+
+// CancelJob cancel the pending job, sql.ErrNoRows is returned when the job already run.
+func (jr UserJobRepo) CancelJob(tx *sql.Tx, jobID int64) error {
+	q := fmt.Sprintf("%s, %s, %s %s%s AND %s", ujqUpdateJob, ujqSetStatus, ujqSetFinished, ujqWhere, ujqFilterJobID, ujqFilterStatus)
+	query, args, err := jr.DBList.Backend.Write.In(q, cu.JobStatusCancelled, jobID, cu.JobStatusPending)
+	if err != nil {
+		return err
+	}
+
+	query = jr.DBList.Backend.Write.Rebind(query)
+
+	var res sql.Result
+	if tx == nil {
+		res, err = jr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		res, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ExpireJob mark the done job as expired, the result is removed since the file is deleted.
+func (jr UserJobRepo) ExpireJob(tx *sql.Tx, jobID int64) error {
+	q := fmt.Sprintf("%s, %s, %s %s%s AND %s", ujqUpdateJob, ujqSetStatus, ujqSetClearResult, ujqWhere, ujqFilterJobID, ujqFilterStatus)
+	return jr.exec(tx, q, cu.JobStatusExpired, jobID, cu.JobStatusDone)
+}
+
+// ExpireUserJobs mark every done job of the type of the user as expired.
+func (jr UserJobRepo) ExpireUserJobs(tx *sql.Tx, userID int64, jobType string) error {
+	q := fmt.Sprintf("%s, %s, %s %s%s AND %s AND %s", ujqUpdateJob, ujqSetStatus, ujqSetClearResult, ujqWhere, ujqFilterUserID, ujqFilterJobType, ujqFilterStatus)
+	return jr.exec(tx, q, cu.JobStatusExpired, userID, jobType, cu.JobStatusDone)
+}
+
+func (jr UserJobRepo) get(q string, param ...interface{}) (*du.UserJob, error) {
+	var res du.UserJob
+
+	query, args, err := jr.DBList.Backend.Read.In(q, param...)
+	if err != nil {
+		return nil, err
+	}
+
+	query = jr.DBList.Backend.Read.Rebind(query)
+	err = jr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if res.ID == 0 {
+		return nil, nil
+	}
+
+	return &res, nil
+}
+
+func (jr UserJobRepo) exec(tx *sql.Tx, q string, param ...interface{}) error {
+	query, args, err := jr.DBList.Backend.Write.In(q, param...)
+	if err != nil {
+		return err
+	}
+
+	query = jr.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = jr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package user
+
+import (
+	"database/sql"
+	"time"
+
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/infra"
+)
+
+type OTPDeliveryRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newOTPDeliveryRepo(dbList *infra.DatabaseList) OTPDeliveryRepo {
+	return OTPDeliveryRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	odqInsertOTPDelivery = `
+	INSERT INTO otp_deliveries (
+		user_id,
+		channel,
+		status,
+		response,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?
+	)
+	RETURNING otp_delivery_id`
+
+	odqDeleteOTPDeliveryByUserID = `
+	DELETE FROM
+		otp_deliveries
+	WHERE
+		user_id = ?`
+)
+
+type OTPDeliveryRepoItf interface {
+	InsertDelivery(tx *sql.Tx, data du.OTPDelivery) (int64, error)
+	DeleteByUserID(tx *sql.Tx, userID int64) error
+}
+
+func (or OTPDeliveryRepo) InsertDelivery(tx *sql.Tx, data du.OTPDelivery) (int64, error) {
+	param := make([]interface{}, 0)
+
+	param = append(param, data.UserID)
+	param = append(param, data.Channel)
+	param = append(param, data.Status)
+	param = append(param, data.Response)
+	param = append(param, time.Now().UTC())
+
+	query, args, err := or.DBList.Backend.Write.In(odqInsertOTPDelivery, param...)
+	if err != nil {
+		return 0, err
+	}
+
+	query = or.DBList.Backend.Write.Rebind(query)
+
+	var res *sql.Row
+	if tx == nil {
+		res = or.DBList.Backend.Write.QueryRow(query, args...)
+	} else {
+		res = tx.QueryRow(query, args...)
+	}
+
+	err = res.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	var deliveryID int64
+	err = res.Scan(&deliveryID)
+	if err != nil {
+		return 0, err
+	}
+
+	return deliveryID, nil
+}
+
+// DeleteByUserID remove the delivery of the user, the provider response contains the recipient number.
+func (or OTPDeliveryRepo) DeleteByUserID(tx *sql.Tx, userID int64) error {
+	query, args, err := or.DBList.Backend.Write.In(odqDeleteOTPDeliveryByUserID, userID)
+	if err != nil {
+		return err
+	}
+
+	query = or.DBList.Backend.Write.Rebind(query)
+
+	if tx == nil {
+		_, err = or.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	return err
+}
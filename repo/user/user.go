@@ -7,8 +7,12 @@ import (
 	"time"
 
 	cg "github.com/furee/backend/constants/general"
+	cu "github.com/furee/backend/constants/user"
+	dg "github.com/furee/backend/domain/general"
 	du "github.com/furee/backend/domain/user"
 	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo/listquery"
+	"gopkg.in/guregu/null.v4"
 )
 
 type UserDataRepo struct {
@@ -26,11 +30,19 @@ const (
 	SELECT
 		user_id,
 		name,
+		email,
 		status,
+		status_reason,
 		phone,
 		phone_filter,
+		user_type,
+		password,
 		otp,
 		otp_created_at,
+		otp_whitelist_id,
+		otp_attempt,
+		otp_lock_count,
+		otp_locked_until,
 		created_at,
 		updated_at,
 		updated_by
@@ -85,20 +97,138 @@ const (
 	uqFilterOTPCreatedAt = `
 		otp_created_at = ?`
 
+	uqSetOTPWhitelistID = `
+		otp_whitelist_id = ?`
+
+	uqFilterOTPAttempt = `
+		otp_attempt = ?`
+
+	uqFilterOTPLockCount = `
+		otp_lock_count = ?`
+
+	uqSetIncrementOTPAttempt = `
+		otp_attempt = otp_attempt + 1`
+
+	uqReturningPhoneChangeAttempt = `
+	RETURNING
+		otp_attempt`
+
+	uqReturningOTPAttempt = `
+	RETURNING
+		otp_attempt,
+		otp_lock_count`
+
+	uqFilterOTPLockedUntil = `
+		otp_locked_until = ?`
+
 	uqFilterStatus = `
 		status = ?`
+
+	uqFilterEmail = `
+		email = ?`
+
+	uqFilterLowerEmail = `
+		LOWER(email) = ?`
+
+	uqSetName = `
+		name = ?`
+
+	uqSetPassword = `
+		password = ?`
+
+	uqSetStatusReason = `
+		status_reason = ?`
+
+	uqCountUser = `
+	SELECT
+		COUNT(1) as count
+	FROM
+		users`
+
+	uqSelectPhoneChange = `
+	SELECT
+		phone_change_id,
+		user_id,
+		new_phone,
+		new_phone_filter,
+		otp,
+		otp_attempt,
+		expired_at,
+		verified_at,
+		created_at
+	FROM
+		user_phone_changes`
+
+	uqInsertPhoneChange = `
+	INSERT INTO user_phone_changes (
+		user_id,
+		new_phone,
+		new_phone_filter,
+		otp,
+		otp_attempt,
+		expired_at,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?, ?, ?
+	)
+	RETURNING phone_change_id`
+
+	uqUpdatePhoneChange = `
+	UPDATE
+		user_phone_changes
+	SET`
+
+	uqFilterPhoneChangeID = `
+		phone_change_id = ?`
+
+	uqFilterNotVerified = `
+		verified_at IS NULL`
+
+	uqSetVerifiedAt = `
+		verified_at = NOW()`
+
+	uqOrderLatest = `
+	ORDER BY
+		created_at DESC
+	LIMIT 1`
+
+	uqSetAnonymized = `
+		email = NULL,
+		password = NULL,
+		otp = NULL,
+		otp_created_at = NULL,
+		otp_whitelist_id = NULL`
+
+	uqDeletePhoneChange = `
+	DELETE FROM
+		user_phone_changes`
 )
 
 type UserDataRepoItf interface {
 	GetByID(userID int64) (*du.User, error)
 	GetByName(userName string) ([]*du.User, error)
 	GetByPhone(phoneFilter string) (*du.User, error)
+	GetByEmail(email string) (*du.User, error)
 	IsExistOTP(otp, phone string) (bool, error)
 	IsExistUser(phone string) (bool, error)
 	InsertUser(tx *sql.Tx, data du.CreateUser) (int64, error)
 	VerifyUser(tx *sql.Tx, data du.VerifyUser) error
-	UpdateStatus(tx *sql.Tx, status int, userID int64) error
-	UpdateOTP(tx *sql.Tx, otp string, userID int64) error
+	GetListUser(pagination dg.PaginationData, filter du.UserFilter) ([]du.User, error)
+	GetTotalDataUser(pagination dg.PaginationData, filter du.UserFilter) (int64, int64, error)
+	UpdateStatus(tx *sql.Tx, data du.UpdateStatus) error
+	UpdateOTP(tx *sql.Tx, otp string, whitelistID null.Int, userID int64) error
+	UpdateOTPAttempt(tx *sql.Tx, data du.OTPAttempt) error
+	IncrementOTPAttempt(tx *sql.Tx, userID int64) (du.OTPAttempt, error)
+	LockOTP(tx *sql.Tx, data du.OTPAttempt, currentLockCount int) (bool, error)
+	UpdateProfile(tx *sql.Tx, userID int64, data du.UpdateProfileRequest) error
+	UpdatePhone(tx *sql.Tx, data du.UpdatePhone) error
+	UpdatePassword(tx *sql.Tx, userID int64, password string, updatedBy int64) error
+	GetPendingPhoneChange(userID int64) (*du.PhoneChange, error)
+	InsertPhoneChange(tx *sql.Tx, data du.PhoneChange) (int64, error)
+	IncrementPhoneChangeAttempt(tx *sql.Tx, phoneChangeID int64) (int, error)
+	VerifyPhoneChange(tx *sql.Tx, phoneChangeID int64) error
+	AnonymizeUser(tx *sql.Tx, data du.AnonymizeUser) error
+	DeletePhoneChanges(tx *sql.Tx, userID int64) error
 }
 
 func (ur UserDataRepo) GetByID(userID int64) (*du.User, error) {
@@ -163,6 +293,29 @@ func (ur UserDataRepo) GetByPhone(phoneFilter string) (*du.User, error) {
 	return &res, nil
 }
 
+// GetByEmail return the user of the email, the email is compared in lowercase.
+func (ur UserDataRepo) GetByEmail(email string) (*du.User, error) {
+	var res du.User
+
+	q := fmt.Sprintf("%s%s%s", uqSelectUser, uqWhere, uqFilterLowerEmail)
+	query, args, err := ur.DBList.Backend.Read.In(q, strings.ToLower(email))
+	if err != nil {
+		return nil, err
+	}
+
+	query = ur.DBList.Backend.Read.Rebind(query)
+	err = ur.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if res.ID == 0 {
+		return nil, nil
+	}
+
+	return &res, nil
+}
+
 func (ur UserDataRepo) IsExistOTP(otp, phone string) (bool, error) {
 	var isExist bool
 
@@ -259,27 +412,113 @@ func (ur UserDataRepo) InsertUser(tx *sql.Tx, data du.CreateUser) (int64, error)
 func (ur UserDataRepo) VerifyUser(tx *sql.Tx, data du.VerifyUser) error {
 	var err error
 
-	q := fmt.Sprintf("%s, %s, %s %s %s AND %s ", uqUpdateUser, uqFilterOTP, uqFilterOTPCreatedAt, uqWhere, uqFilterPhoneFilter, uqFilterOTP)
-	query, args, err := ur.DBList.Backend.Read.In(q, cg.UpdatedBySystem, nil, nil, data.PhoneFilter, data.OTP)
+	q := fmt.Sprintf("%s, %s, %s, %s, %s, %s, %s %s %s AND %s ", uqUpdateUser, uqFilterOTP, uqFilterOTPCreatedAt, uqSetOTPWhitelistID, uqFilterOTPAttempt, uqFilterOTPLockCount, uqFilterOTPLockedUntil, uqWhere, uqFilterPhoneFilter, uqFilterOTP)
+	query, args, err := ur.DBList.Backend.Read.In(q, cg.UpdatedBySystem, nil, nil, nil, 0, 0, nil, data.PhoneFilter, data.OTP)
 	if err != nil {
 		return err
 	}
 
 	query = ur.DBList.Backend.Write.Rebind(query)
-	_, err = ur.DBList.Backend.Write.Exec(query, args...)
+
+	var res sql.Result
+	if tx == nil {
+		res, err = ur.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		res, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// OTP already consumed by another request.
+	affected, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
 	return nil
 }
 
-func (ur UserDataRepo) UpdateStatus(tx *sql.Tx, status int, userID int64) error {
+func (ur UserDataRepo) UpdateStatus(tx *sql.Tx, data du.UpdateStatus) error {
+	q := fmt.Sprintf("%s, %s, %s %s%s", uqUpdateUser, uqFilterStatus, uqSetStatusReason, uqWhere, uqFilterUserID)
+	return ur.exec(tx, q, data.UpdatedBy, data.Status, data.Reason, data.UserID)
+}
+
+// userSortable is the field that can be used to sort the user list, id is the alias of user_id.
+var userSortable = listquery.Sortable{
+	Columns: map[string]string{
+		"id":         "user_id",
+		"user_id":    "user_id",
+		"name":       "name",
+		"status":     "status",
+		"created_at": "created_at",
+	},
+	Key: "user_id",
+}
+
+func (ur UserDataRepo) GetListUser(pagination dg.PaginationData, filter du.UserFilter) ([]du.User, error) {
+	var result []du.User
+
+	q, param, err := ur.listQuery(filter).Select(uqSelectUser, userSortable, pagination)
+	if err != nil {
+		return result, err
+	}
+
+	query, args, err := ur.DBList.Backend.Read.In(q, param...)
+	if err != nil {
+		return result, err
+	}
+
+	query = ur.DBList.Backend.Read.Rebind(query)
+	err = ur.DBList.Backend.Read.Select(&result, query, args...)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (ur UserDataRepo) GetTotalDataUser(pagination dg.PaginationData, filter du.UserFilter) (int64, int64, error) {
+	var result int64
+
+	q, param := ur.listQuery(filter).Count(uqCountUser)
+	query, args, err := ur.DBList.Backend.Read.In(q, param...)
+	if err != nil {
+		return result, 0, err
+	}
+
+	//Run query to get total data
+	query = ur.DBList.Backend.Read.Rebind(query)
+	err = ur.DBList.Backend.Read.Get(&result, query, args...)
+	if err != nil {
+		return result, 0, err
+	}
+
+	return result, listquery.TotalPage(result, pagination.Limit), nil
+}
+
+func (ur UserDataRepo) listQuery(filter du.UserFilter) listquery.Query {
+	var lq listquery.Query
+	lq.Contains("name", filter.Name)
+	lq.Int("status", filter.Status)
+	lq.TimeFrom("created_at", filter.CreatedFrom)
+	lq.TimeTo("created_at", filter.CreatedTo)
+
+	return lq
+}
+
+// UpdateOTP store the new OTP of the user, whitelistID is set when the OTP is the fixed OTP of the whitelist.
+func (ur UserDataRepo) UpdateOTP(tx *sql.Tx, otp string, whitelistID null.Int, userID int64) error {
 	var err error
 
-	q := fmt.Sprintf("%s, %s %s%s", uqUpdateUser, uqFilterStatus, uqWhere, uqFilterUserID)
+	q := fmt.Sprintf("%s, %s, %s, %s %s%s", uqUpdateUser, uqFilterOTP, uqFilterOTPCreatedAt, uqSetOTPWhitelistID, uqWhere, uqFilterUserID)
 
-	query, args, err := ur.DBList.Backend.Read.In(q, cg.UpdatedBySystem, status, userID)
+	query, args, err := ur.DBList.Backend.Read.In(q, cg.UpdatedBySystem, otp, time.Now().UTC(), whitelistID, userID)
 	if err != nil {
 		return err
 	}
@@ -293,18 +532,232 @@ func (ur UserDataRepo) UpdateStatus(tx *sql.Tx, status int, userID int64) error
 	return nil
 }
 
-func (ur UserDataRepo) UpdateOTP(tx *sql.Tx, otp string, userID int64) error {
+func (ur UserDataRepo) UpdateOTPAttempt(tx *sql.Tx, data du.OTPAttempt) error {
 	var err error
 
-	q := fmt.Sprintf("%s, %s, %s %s%s", uqUpdateUser, uqFilterOTP, uqFilterOTPCreatedAt, uqWhere, uqFilterUserID)
+	q := fmt.Sprintf("%s, %s, %s, %s %s%s", uqUpdateUser, uqFilterOTPAttempt, uqFilterOTPLockCount, uqFilterOTPLockedUntil, uqWhere, uqFilterUserID)
 
-	query, args, err := ur.DBList.Backend.Read.In(q, cg.UpdatedBySystem, otp, time.Now().UTC(), userID)
+	query, args, err := ur.DBList.Backend.Read.In(q, cg.UpdatedBySystem, data.Attempt, data.LockCount, data.LockedUntil, data.UserID)
 	if err != nil {
 		return err
 	}
 
 	query = ur.DBList.Backend.Write.Rebind(query)
-	_, err = ur.DBList.Backend.Write.Exec(query, args...)
+	if tx == nil {
+		_, err = ur.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IncrementOTPAttempt add the failed attempt in one statement, so the parallel request is counted one by one.
+func (ur UserDataRepo) IncrementOTPAttempt(tx *sql.Tx, userID int64) (du.OTPAttempt, error) {
+	res := du.OTPAttempt{UserID: userID}
+
+	q := fmt.Sprintf("%s, %s %s%s%s", uqUpdateUser, uqSetIncrementOTPAttempt, uqWhere, uqFilterUserID, uqReturningOTPAttempt)
+	query, args, err := ur.DBList.Backend.Write.In(q, cg.UpdatedBySystem, userID)
+	if err != nil {
+		return res, err
+	}
+
+	query = ur.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		err = ur.DBList.Backend.Write.Get(&res, query, args...)
+	} else {
+		err = tx.QueryRow(query, args...).Scan(&res.Attempt, &res.LockCount)
+	}
+
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// LockOTP lock the phone & reset the attempt, only when the lock count is still the current one.
+// False is returned when the phone is already locked by the other request.
+func (ur UserDataRepo) LockOTP(tx *sql.Tx, data du.OTPAttempt, currentLockCount int) (bool, error) {
+	q := fmt.Sprintf("%s, %s, %s, %s %s%s AND %s", uqUpdateUser, uqFilterOTPAttempt, uqFilterOTPLockCount, uqFilterOTPLockedUntil, uqWhere, uqFilterUserID, uqFilterOTPLockCount)
+	query, args, err := ur.DBList.Backend.Write.In(q, cg.UpdatedBySystem, data.Attempt, data.LockCount, data.LockedUntil, data.UserID, currentLockCount)
+	if err != nil {
+		return false, err
+	}
+
+	query = ur.DBList.Backend.Write.Rebind(query)
+
+	var res sql.Result
+	if tx == nil {
+		res, err = ur.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		res, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (ur UserDataRepo) UpdateProfile(tx *sql.Tx, userID int64, data du.UpdateProfileRequest) error {
+	var email *string
+	if data.Email != "" {
+		email = &data.Email
+	}
+
+	q := fmt.Sprintf("%s, %s, %s %s%s", uqUpdateUser, uqSetName, uqFilterEmail, uqWhere, uqFilterUserID)
+	return ur.exec(tx, q, userID, strings.Title(strings.ToLower(data.Name)), email, userID)
+}
+
+func (ur UserDataRepo) UpdatePhone(tx *sql.Tx, data du.UpdatePhone) error {
+	q := fmt.Sprintf("%s, %s, %s %s%s", uqUpdateUser, uqFilterPhone, uqFilterPhoneFilter, uqWhere, uqFilterUserID)
+	return ur.exec(tx, q, data.UpdatedBy, data.Phone, data.PhoneFilter, data.UserID)
+}
+
+func (ur UserDataRepo) UpdatePassword(tx *sql.Tx, userID int64, password string, updatedBy int64) error {
+	q := fmt.Sprintf("%s, %s %s%s", uqUpdateUser, uqSetPassword, uqWhere, uqFilterUserID)
+	return ur.exec(tx, q, updatedBy, password, userID)
+}
+
+func (ur UserDataRepo) GetPendingPhoneChange(userID int64) (*du.PhoneChange, error) {
+	var res du.PhoneChange
+
+	q := fmt.Sprintf("%s%s%s AND %s%s", uqSelectPhoneChange, uqWhere, uqFilterUserID, uqFilterNotVerified, uqOrderLatest)
+	query, args, err := ur.DBList.Backend.Read.In(q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = ur.DBList.Backend.Read.Rebind(query)
+	err = ur.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if res.ID == 0 {
+		return nil, nil
+	}
+
+	return &res, nil
+}
+
+func (ur UserDataRepo) InsertPhoneChange(tx *sql.Tx, data du.PhoneChange) (int64, error) {
+	query, args, err := ur.DBList.Backend.Write.In(uqInsertPhoneChange, data.UserID, data.NewPhone, data.NewPhoneFilter, data.OTP, 0, data.ExpiredAt, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	query = ur.DBList.Backend.Write.Rebind(query)
+
+	var res *sql.Row
+	if tx == nil {
+		res = ur.DBList.Backend.Write.QueryRow(query, args...)
+	} else {
+		res = tx.QueryRow(query, args...)
+	}
+
+	var phoneChangeID int64
+	err = res.Scan(&phoneChangeID)
+	if err != nil {
+		return 0, err
+	}
+
+	return phoneChangeID, nil
+}
+
+// IncrementPhoneChangeAttempt add the attempt in one statement & return the new value,
+// so the parallel request is counted one by one.
+func (ur UserDataRepo) IncrementPhoneChangeAttempt(tx *sql.Tx, phoneChangeID int64) (int, error) {
+	var attempt int
+
+	q := fmt.Sprintf("%s%s %s%s%s", uqUpdatePhoneChange, uqSetIncrementOTPAttempt, uqWhere, uqFilterPhoneChangeID, uqReturningPhoneChangeAttempt)
+	query, args, err := ur.DBList.Backend.Write.In(q, phoneChangeID)
+	if err != nil {
+		return 0, err
+	}
+
+	query = ur.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		err = ur.DBList.Backend.Write.Get(&attempt, query, args...)
+	} else {
+		err = tx.QueryRow(query, args...).Scan(&attempt)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return attempt, nil
+}
+
+func (ur UserDataRepo) VerifyPhoneChange(tx *sql.Tx, phoneChangeID int64) error {
+	q := fmt.Sprintf("%s%s %s%s AND %s", uqUpdatePhoneChange, uqSetVerifiedAt, uqWhere, uqFilterPhoneChangeID, uqFilterNotVerified)
+	query, args, err := ur.DBList.Backend.Write.In(q, phoneChangeID)
+	if err != nil {
+		return err
+	}
+
+	query = ur.DBList.Backend.Write.Rebind(query)
+
+	var res sql.Result
+	if tx == nil {
+		res, err = ur.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		res, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Phone change already verified by another request.
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// AnonymizeUser remove the PII of the deleted user, the row is kept for the order & audit reference.
+func (ur UserDataRepo) AnonymizeUser(tx *sql.Tx, data du.AnonymizeUser) error {
+	q := fmt.Sprintf("%s, %s, %s, %s, %s, %s, %s %s%s", uqUpdateUser, uqSetName, uqFilterPhone, uqFilterPhoneFilter, uqFilterStatus, uqSetStatusReason, uqSetAnonymized, uqWhere, uqFilterUserID)
+	return ur.exec(tx, q, cg.UpdatedBySystem, data.Name, data.Phone, data.PhoneFilter, cu.StatusDeleted, null.String{}, data.UserID)
+}
+
+func (ur UserDataRepo) DeletePhoneChanges(tx *sql.Tx, userID int64) error {
+	q := fmt.Sprintf("%s%s%s", uqDeletePhoneChange, uqWhere, uqFilterUserID)
+	return ur.exec(tx, q, userID)
+}
+
+func (ur UserDataRepo) exec(tx *sql.Tx, q string, param ...interface{}) error {
+	query, args, err := ur.DBList.Backend.Write.In(q, param...)
+	if err != nil {
+		return err
+	}
+
+	query = ur.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = ur.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
 	if err != nil {
 		return err
 	}
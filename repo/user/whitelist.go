@@ -0,0 +1,184 @@
+package user
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/infra"
+)
+
+type OTPWhitelistRepo struct {
+	DBList *infra.DatabaseList
+}
+
+func newOTPWhitelistRepo(dbList *infra.DatabaseList) OTPWhitelistRepo {
+	return OTPWhitelistRepo{
+		DBList: dbList,
+	}
+}
+
+const (
+	owqSelectWhitelist = `
+	SELECT
+		otp_whitelist_id,
+		phone,
+		phone_filter,
+		otp,
+		reason,
+		expired_at,
+		created_by,
+		created_at
+	FROM
+		otp_whitelists`
+
+	owqInsertWhitelist = `
+	INSERT INTO otp_whitelists (
+		phone,
+		phone_filter,
+		otp,
+		reason,
+		expired_at,
+		created_by,
+		created_at
+	) VALUES (
+		?, ?, ?, ?, ?, ?, ?
+	)
+	RETURNING otp_whitelist_id`
+
+	owqDeleteWhitelist = `
+	DELETE FROM
+		otp_whitelists`
+
+	owqWhere = `
+	WHERE`
+
+	owqFilterWhitelistID = `
+		otp_whitelist_id = ?`
+
+	owqFilterNotExpired = `
+		expired_at > ?`
+
+	owqOrderByCreatedAt = `
+	ORDER BY
+		created_at DESC`
+)
+
+type OTPWhitelistRepoItf interface {
+	GetList() ([]du.OTPWhitelist, error)
+	GetListActive(now time.Time) ([]du.OTPWhitelist, error)
+	GetByID(whitelistID int64) (*du.OTPWhitelist, error)
+	InsertWhitelist(tx *sql.Tx, data du.OTPWhitelist) (int64, error)
+	DeleteWhitelist(tx *sql.Tx, whitelistID int64) error
+}
+
+func (wr OTPWhitelistRepo) GetList() ([]du.OTPWhitelist, error) {
+	q := fmt.Sprintf("%s%s", owqSelectWhitelist, owqOrderByCreatedAt)
+	return wr.list(q)
+}
+
+// GetListActive return the entry that not expired yet, used to fill the cache.
+func (wr OTPWhitelistRepo) GetListActive(now time.Time) ([]du.OTPWhitelist, error) {
+	q := fmt.Sprintf("%s%s%s", owqSelectWhitelist, owqWhere, owqFilterNotExpired)
+	return wr.list(q, now)
+}
+
+func (wr OTPWhitelistRepo) GetByID(whitelistID int64) (*du.OTPWhitelist, error) {
+	var res du.OTPWhitelist
+
+	q := fmt.Sprintf("%s%s%s", owqSelectWhitelist, owqWhere, owqFilterWhitelistID)
+	query, args, err := wr.DBList.Backend.Read.In(q, whitelistID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = wr.DBList.Backend.Read.Rebind(query)
+	err = wr.DBList.Backend.Read.Get(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if res.ID == 0 {
+		return nil, nil
+	}
+
+	return &res, nil
+}
+
+func (wr OTPWhitelistRepo) InsertWhitelist(tx *sql.Tx, data du.OTPWhitelist) (int64, error) {
+	param := make([]interface{}, 0)
+
+	param = append(param, data.Phone)
+	param = append(param, data.PhoneFilter)
+	param = append(param, data.OTP)
+	param = append(param, data.Reason)
+	param = append(param, data.ExpiredAt)
+	param = append(param, data.CreatedBy)
+	param = append(param, time.Now().UTC())
+
+	query, args, err := wr.DBList.Backend.Write.In(owqInsertWhitelist, param...)
+	if err != nil {
+		return 0, err
+	}
+
+	query = wr.DBList.Backend.Write.Rebind(query)
+
+	var res *sql.Row
+	if tx == nil {
+		res = wr.DBList.Backend.Write.QueryRow(query, args...)
+	} else {
+		res = tx.QueryRow(query, args...)
+	}
+
+	err = res.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	var whitelistID int64
+	err = res.Scan(&whitelistID)
+	if err != nil {
+		return 0, err
+	}
+
+	return whitelistID, nil
+}
+
+func (wr OTPWhitelistRepo) DeleteWhitelist(tx *sql.Tx, whitelistID int64) error {
+	q := fmt.Sprintf("%s%s%s", owqDeleteWhitelist, owqWhere, owqFilterWhitelistID)
+	query, args, err := wr.DBList.Backend.Write.In(q, whitelistID)
+	if err != nil {
+		return err
+	}
+
+	query = wr.DBList.Backend.Write.Rebind(query)
+	if tx == nil {
+		_, err = wr.DBList.Backend.Write.Exec(query, args...)
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (wr OTPWhitelistRepo) list(q string, param ...interface{}) ([]du.OTPWhitelist, error) {
+	res := make([]du.OTPWhitelist, 0)
+
+	query, args, err := wr.DBList.Backend.Read.In(q, param...)
+	if err != nil {
+		return nil, err
+	}
+
+	query = wr.DBList.Backend.Read.Rebind(query)
+	err = wr.DBList.Backend.Read.Select(&res, query, args...)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,103 @@
+package authorization
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	"github.com/furee/backend/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type APIClientUsecaseItf interface {
+	GetActiveClient(clientID string) (*da.APIClient, string, error)
+	CreateClient(name string, allowedRoutes []string) (*da.APIClient, string, error)
+}
+
+type APIClientUsecase struct {
+	Repo   ra.APIClientRepoItf
+	DBList *infra.DatabaseList
+	Conf   *general.SectionService
+	Log    *logrus.Logger
+}
+
+func newAPIClientUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList) APIClientUsecase {
+	return APIClientUsecase{
+		Repo:   r.Authorization.APIClient,
+		Conf:   conf,
+		Log:    logger,
+		DBList: dbList,
+	}
+}
+
+// GetActiveClient return the client with the decrypted secret, used to verify the request signature.
+func (au APIClientUsecase) GetActiveClient(clientID string) (*da.APIClient, string, error) {
+	client, err := au.Repo.GetByID(clientID)
+	if err != nil {
+		au.Log.WithField("client id", clientID).WithError(err).Error("GetActiveClient | fail to get client from repo")
+		return nil, "", err
+	}
+
+	if client == nil || !client.IsActive {
+		return nil, "client not found", errors.New("client not found")
+	}
+
+	secret, err := utils.GetDecrypt([]byte(au.Conf.Authorization.Public.SecretEncryptionKey), client.Secret)
+	if err != nil {
+		au.Log.WithField("client id", clientID).WithError(err).Error("GetActiveClient | fail to decrypt client secret")
+		return nil, "", err
+	}
+
+	client.Secret = secret
+
+	return client, "", nil
+}
+
+// CreateClient register new client, the plain secret is only returned here.
+func (au APIClientUsecase) CreateClient(name string, allowedRoutes []string) (*da.APIClient, string, error) {
+	if name == "" || len(allowedRoutes) == 0 {
+		return nil, "name & allowed routes cannot be empty", errors.New("name & allowed routes empty")
+	}
+
+	clientID, err := utils.GetUUID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	secretByte := make([]byte, 32)
+	_, err = rand.Read(secretByte)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret := hex.EncodeToString(secretByte)
+
+	encryptedSecret, err := utils.GetEncrypt([]byte(au.Conf.Authorization.Public.SecretEncryptionKey), secret)
+	if err != nil {
+		au.Log.WithField("name", name).WithError(err).Error("CreateClient | fail to encrypt client secret")
+		return nil, "", err
+	}
+
+	client := da.APIClient{
+		ID:            clientID,
+		Name:          name,
+		Secret:        encryptedSecret,
+		AllowedRoutes: allowedRoutes,
+		IsActive:      true,
+	}
+
+	err = au.Repo.InsertClient(nil, client)
+	if err != nil {
+		au.Log.WithField("name", name).WithError(err).Error("CreateClient | fail to insert client")
+		return nil, "", err
+	}
+
+	client.Secret = secret
+
+	return &client, "", nil
+}
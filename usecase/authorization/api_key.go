@@ -0,0 +1,291 @@
+package authorization
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	ca "github.com/furee/backend/constants/authorization"
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	// apiKeyPrefixLen & apiKeySecretLen is the byte length before hex encoded.
+	apiKeyPrefixLen = 6
+	apiKeySecretLen = 32
+	// apiKeyLastUsedInterval limit how often the last used time is updated for the same key.
+	apiKeyLastUsedInterval = time.Minute
+)
+
+type APIKeyUsecaseItf interface {
+	Authenticate(ctx context.Context, rawKey string, ip string) (*da.APIKey, string, error)
+	GetListAPIKey(ctx context.Context) ([]da.APIKey, string, error)
+	CreateAPIKey(ctx context.Context, data da.APIKeyRequest) (*da.APIKeyResponse, string, error)
+	RevokeAPIKey(ctx context.Context, apiKeyID int64) (string, error)
+}
+
+type APIKeyUsecase struct {
+	Repo         ra.APIKeyRepoItf
+	AuditLogRepo ra.AuditLogRepoItf
+	AuthEvent    AuthEventUsecaseItf
+	DBList       *infra.DatabaseList
+	Conf         *general.SectionService
+	Log          *logrus.Logger
+}
+
+func newAPIKeyUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList, event AuthEventUsecaseItf) APIKeyUsecase {
+	return APIKeyUsecase{
+		Repo:         r.Authorization.APIKey,
+		AuditLogRepo: r.Authorization.AuditLog,
+		AuthEvent:    event,
+		Conf:         conf,
+		Log:          logger,
+		DBList:       dbList,
+	}
+}
+
+// Authenticate check the "<prefix>.<secret>" key, the ip must be resolved from the remote address
+// through the trusted proxy, never from the forwarded header sent by the caller.
+// The last used time is updated in background.
+func (au APIKeyUsecase) Authenticate(ctx context.Context, rawKey string, ip string) (*da.APIKey, string, error) {
+	parts := strings.SplitN(rawKey, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		au.recordFailed(ctx, "", "invalid format")
+		return nil, cg.HandlerErrorTokenInvalid, errors.New("api key format not valid")
+	}
+
+	prefix := parts[0]
+
+	key, err := au.Repo.GetByPrefix(prefix)
+	if err != nil {
+		au.Log.WithField("prefix", prefix).WithError(err).Error("Authenticate | fail to get api key from repo")
+		return nil, "", err
+	}
+
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.SecretHash), []byte(hashAPIKeySecret(parts[1]))) != 1 {
+		au.recordFailed(ctx, prefix, "invalid key")
+		return nil, cg.HandlerErrorTokenInvalid, errors.New("api key not valid")
+	}
+
+	now := time.Now().UTC()
+
+	if key.RevokedAt != nil {
+		au.recordFailed(ctx, prefix, "revoked")
+		return nil, cg.HandlerErrorTokenInvalid, errors.New("api key revoked")
+	}
+
+	if key.ExpiredAt != nil && !now.Before(key.ExpiredAt.UTC()) {
+		au.recordFailed(ctx, prefix, "expired")
+		return nil, cg.HandlerErrorTokenInvalid, errors.New("api key expired")
+	}
+
+	if !key.IsIPAllowed(ip) {
+		au.recordFailed(ctx, prefix, "ip not allowed")
+		return nil, cg.HandlerErrorPermissionDenied, errors.New("ip not allowed")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(key.LastUsedAt.UTC()) >= apiKeyLastUsedInterval {
+		go func(apiKeyID int64) {
+			err := au.Repo.UpdateLastUsed(apiKeyID, now, ip)
+			if err != nil {
+				au.Log.WithField("api key id", apiKeyID).WithError(err).Error("Authenticate | fail to update last used")
+			}
+		}(key.ID)
+	}
+
+	key.SecretHash = ""
+
+	return key, "", nil
+}
+
+func (au APIKeyUsecase) GetListAPIKey(ctx context.Context) ([]da.APIKey, string, error) {
+	keys, err := au.Repo.GetList()
+	if err != nil {
+		au.Log.WithError(err).Error("GetListAPIKey | fail to get api key list from repo")
+		return nil, "", err
+	}
+
+	return keys, "", nil
+}
+
+// CreateAPIKey issue new key, the admin can only give the scope that the admin has & allowed for the API key.
+// The plain key is only returned here.
+func (au APIKeyUsecase) CreateAPIKey(ctx context.Context, data da.APIKeyRequest) (*da.APIKeyResponse, string, error) {
+	session, ok := da.GetSession(ctx)
+	if !ok {
+		return nil, "session not found", errors.New("session not found")
+	}
+
+	for _, scope := range data.Scopes {
+		if !da.IsAPIKeyScope(scope) || !session.HasPermission(scope) {
+			return nil, fmt.Sprintf("scope %s tidak dapat diberikan", scope), errors.New("scope not owned by admin")
+		}
+	}
+
+	for _, allowed := range data.AllowedIPs {
+		_, _, errCIDR := net.ParseCIDR(allowed)
+		if errCIDR != nil && net.ParseIP(allowed) == nil {
+			return nil, fmt.Sprintf("IP %s tidak valid", allowed), errors.New("allowed ip not valid")
+		}
+	}
+
+	now := time.Now().UTC()
+	if data.ExpiredAt != nil && !data.ExpiredAt.After(now) {
+		return nil, "Waktu kedaluwarsa harus di masa depan", errors.New("expired at is in the past")
+	}
+
+	prefixByte := make([]byte, apiKeyPrefixLen)
+	_, err := rand.Read(prefixByte)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secretByte := make([]byte, apiKeySecretLen)
+	_, err = rand.Read(secretByte)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prefix := hex.EncodeToString(prefixByte)
+	secret := hex.EncodeToString(secretByte)
+
+	key := da.APIKey{
+		Name:       data.Name,
+		Prefix:     prefix,
+		SecretHash: hashAPIKeySecret(secret),
+		Scopes:     pq.StringArray(data.Scopes),
+		AllowedIPs: pq.StringArray{},
+		ExpiredAt:  data.ExpiredAt,
+		CreatedBy:  session.UserID,
+		CreatedAt:  now,
+	}
+
+	if len(data.AllowedIPs) > 0 {
+		key.AllowedIPs = pq.StringArray(data.AllowedIPs)
+	}
+
+	tx, err := au.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key.ID, err = au.Repo.InsertAPIKey(tx, key)
+	if err != nil {
+		tx.Rollback()
+		au.Log.WithField("name", data.Name).WithError(err).Error("CreateAPIKey | fail to insert api key")
+		return nil, "", err
+	}
+
+	err = au.insertAuditLog(tx, ctx, ca.AuditActionAPIKeyCreate, key)
+	if err != nil {
+		tx.Rollback()
+		au.Log.WithField("api key id", key.ID).WithError(err).Error("CreateAPIKey | fail to insert audit log")
+		return nil, "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key.SecretHash = ""
+
+	return &da.APIKeyResponse{
+		APIKey: key,
+		Key:    fmt.Sprintf("%s.%s", prefix, secret),
+	}, "success create api key", nil
+}
+
+func (au APIKeyUsecase) RevokeAPIKey(ctx context.Context, apiKeyID int64) (string, error) {
+	key, err := au.Repo.GetByID(apiKeyID)
+	if err != nil {
+		au.Log.WithField("api key id", apiKeyID).WithError(err).Error("RevokeAPIKey | fail to get api key from repo")
+		return "", err
+	}
+
+	if key == nil || key.RevokedAt != nil {
+		return "api key not found", errors.New("api key not found")
+	}
+
+	tx, err := au.DBList.Backend.Write.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	err = au.Repo.RevokeAPIKey(tx, apiKeyID)
+	if err != nil {
+		tx.Rollback()
+
+		if err == sql.ErrNoRows {
+			return "api key not found", err
+		}
+
+		au.Log.WithField("api key id", apiKeyID).WithError(err).Error("RevokeAPIKey | fail to revoke api key")
+		return "", err
+	}
+
+	err = au.insertAuditLog(tx, ctx, ca.AuditActionAPIKeyRevoke, *key)
+	if err != nil {
+		tx.Rollback()
+		au.Log.WithField("api key id", apiKeyID).WithError(err).Error("RevokeAPIKey | fail to insert audit log")
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	return "success revoke api key", nil
+}
+
+// insertAuditLog record the admin change, the secret is never included on the detail.
+func (au APIKeyUsecase) insertAuditLog(tx *sql.Tx, ctx context.Context, action string, key da.APIKey) error {
+	detail, err := json.Marshal(map[string]interface{}{
+		"name":        key.Name,
+		"prefix":      key.Prefix,
+		"scopes":      key.Scopes,
+		"allowed_ips": key.AllowedIPs,
+		"expired_at":  key.ExpiredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return au.AuditLogRepo.InsertAuditLog(tx, da.AuditLog{
+		ActorID:    null.NewInt(da.CurrentUserID(ctx), da.CurrentUserID(ctx) != 0),
+		Action:     action,
+		EntityType: ca.AuditEntityAPIKey,
+		EntityID:   fmt.Sprintf("%v", key.ID),
+		Detail:     string(detail),
+	})
+}
+
+func (au APIKeyUsecase) recordFailed(ctx context.Context, prefix, reason string) {
+	au.AuthEvent.Record(ctx, ca.AuthEventAPIKeyFailed, 0, "", map[string]interface{}{
+		"prefix": prefix,
+		"reason": reason,
+	})
+}
+
+func hashAPIKeySecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
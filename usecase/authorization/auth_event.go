@@ -0,0 +1,116 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	"github.com/furee/backend/utils"
+	"github.com/furee/backend/utils/phone"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+)
+
+type AuthEventUsecaseItf interface {
+	Record(ctx context.Context, eventType string, userID int64, phoneHash string, detail map[string]interface{})
+	GetListAuthEvent(ctx context.Context, pagination general.PaginationData, filter da.AuthEventFilter) ([]da.AuthEvent, general.PaginationData, string, error)
+}
+
+type AuthEventUsecase struct {
+	Repo   ra.AuthEventRepoItf
+	DBList *infra.DatabaseList
+	Conf   *general.SectionService
+	Log    *logrus.Logger
+}
+
+func newAuthEventUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList) AuthEventUsecase {
+	return AuthEventUsecase{
+		Repo:   r.Authorization.AuthEvent,
+		Conf:   conf,
+		Log:    logger,
+		DBList: dbList,
+	}
+}
+
+// Record write the authentication event & publish it to NSQ when the producer is configured.
+// The IP, user agent & request id are taken from the context, userID 0 & empty phoneHash mean unknown.
+// Failure is only logged so the authentication itself is never blocked by the event log.
+func (au AuthEventUsecase) Record(ctx context.Context, eventType string, userID int64, phoneHash string, detail map[string]interface{}) {
+	device := da.GetDevice(ctx)
+
+	event := da.AuthEvent{
+		EventType: eventType,
+		UserID:    null.NewInt(userID, userID != 0),
+		PhoneHash: null.NewString(phoneHash, phoneHash != ""),
+		IPAddress: device.IPAddress,
+		UserAgent: device.UserAgent,
+		RequestID: da.GetRequestID(ctx),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if len(detail) > 0 {
+		data, err := json.Marshal(detail)
+		if err != nil {
+			au.Log.WithField("event type", eventType).WithError(err).Error("Record | fail to marshal event detail")
+		} else {
+			event.Detail = null.StringFrom(string(data))
+		}
+	}
+
+	id, err := au.Repo.InsertAuthEvent(event)
+	if err != nil {
+		au.Log.WithField("event", utils.StructToString(event)).WithError(err).Error("Record | fail to insert auth event")
+		return
+	}
+
+	event.ID = id
+
+	if au.DBList.NSQProducer == nil {
+		return
+	}
+
+	err = au.DBList.NSQProducer.Publish(cg.NSQTopicAuthEvent, event)
+	if err != nil {
+		au.Log.WithField("auth event id", id).WithError(err).Error("Record | fail to publish auth event")
+	}
+}
+
+// GetListAuthEvent return the paginated auth event for the admin, the phone filter is converted to the phone hash.
+func (au AuthEventUsecase) GetListAuthEvent(ctx context.Context, pagination general.PaginationData, filter da.AuthEventFilter) ([]da.AuthEvent, general.PaginationData, string, error) {
+	if filter.Phone.Valid {
+		number, err := phone.Parse(filter.Phone.String, phone.RegionID)
+		if err != nil {
+			return nil, pagination, "Nomor telepon tidak valid", err
+		}
+
+		filter.PhoneHash = null.StringFrom(phone.BlindIndex([]byte(au.Conf.Authorization.Phone.FilterPepper), number.E164))
+		filter.Phone = null.String{}
+	}
+
+	events, err := au.Repo.GetListAuthEvent(pagination, filter)
+	if err != nil {
+		au.Log.WithField("filter", utils.StructToString(filter)).WithError(err).Error("GetListAuthEvent | fail to get auth event list from repo")
+		return nil, pagination, "", err
+	}
+
+	count, page, err := au.Repo.GetTotalAuthEvent(pagination, filter)
+	if err != nil {
+		au.Log.WithField("filter", utils.StructToString(filter)).WithError(err).Error("GetListAuthEvent | fail to get total auth event from repo")
+		return nil, pagination, "", err
+	}
+
+	pagination.TotalData = int(count)
+	pagination.TotalPage = int(page)
+
+	if events == nil {
+		events = make([]da.AuthEvent, 0)
+	}
+
+	return events, pagination, "", nil
+}
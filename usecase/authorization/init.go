@@ -0,0 +1,35 @@
+package authorization
+
+import (
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	"github.com/sirupsen/logrus"
+)
+
+type AuthorizationUsecase struct {
+	Token     TokenUsecaseItf
+	Role      RoleUsecaseItf
+	APIClient APIClientUsecaseItf
+	MFA       MFAUsecaseItf
+	Session   SessionUsecaseItf
+	AuthEvent AuthEventUsecaseItf
+	OIDC      OIDCUsecaseItf
+	APIKey    APIKeyUsecaseItf
+}
+
+func NewUsecase(repo repo.Repo, conf *general.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger) AuthorizationUsecase {
+	event := newAuthEventUsecase(repo, conf, logger, dbList)
+	token := newTokenUsecase(repo, conf, logger, dbList, event)
+
+	return AuthorizationUsecase{
+		Token:     token,
+		Role:      newRoleUsecase(repo, conf, logger, dbList),
+		APIClient: newAPIClientUsecase(repo, conf, logger, dbList),
+		MFA:       newMFAUsecase(repo, conf, logger, dbList, token, event),
+		Session:   newSessionUsecase(repo, conf, logger, dbList),
+		AuthEvent: event,
+		OIDC:      newOIDCUsecase(repo, conf, logger, dbList, token, event),
+		APIKey:    newAPIKeyUsecase(repo, conf, logger, dbList, event),
+	}
+}
@@ -0,0 +1,463 @@
+package authorization
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	ca "github.com/furee/backend/constants/authorization"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	ru "github.com/furee/backend/repo/user"
+	"github.com/furee/backend/utils"
+	"github.com/sirupsen/logrus"
+	qrcode "github.com/skip2/go-qrcode"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	mfaRecoveryCodeCount = 10
+	mfaRecoveryCodeLen   = 5 // bytes, shown as 10 hex char
+	mfaTOTPSkew          = 1
+	mfaQRCodeSize        = 256
+)
+
+type MFAUsecaseItf interface {
+	Enroll(ctx context.Context) (*da.MFAEnrollment, string, error)
+	Activate(ctx context.Context, data da.MFACodeRequest) (*da.MFARecoveryCodes, string, error)
+	Verify(ctx context.Context, data da.MFAVerifyRequest) (*general.JWTAccess, string, error)
+	Reset(ctx context.Context, userID int64) (string, error)
+}
+
+type MFAUsecase struct {
+	Repo             ra.MFARepoItf
+	RevokedTokenRepo ra.RevokedTokenRepoItf
+	UserRepo         ru.UserDataRepoItf
+	AuditLogRepo     ra.AuditLogRepoItf
+	Token            TokenUsecaseItf
+	AuthEvent        AuthEventUsecaseItf
+	DBList           *infra.DatabaseList
+	Conf             *general.SectionService
+	Log              *logrus.Logger
+}
+
+func newMFAUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList, token TokenUsecaseItf, event AuthEventUsecaseItf) MFAUsecase {
+	return MFAUsecase{
+		Repo:             r.Authorization.MFA,
+		RevokedTokenRepo: r.Authorization.RevokedToken,
+		UserRepo:         r.User.User,
+		AuditLogRepo:     r.Authorization.AuditLog,
+		Token:            token,
+		AuthEvent:        event,
+		Conf:             conf,
+		Log:              logger,
+		DBList:           dbList,
+	}
+}
+
+// Enroll generate new TOTP secret for the current user. The previous secret is replaced
+// & MFA stay disabled until the user activate it with a valid code.
+func (mu MFAUsecase) Enroll(ctx context.Context) (*da.MFAEnrollment, string, error) {
+	user, ok := da.CurrentUser(ctx)
+	if !ok {
+		return nil, "user not found", errors.New("user not found")
+	}
+
+	mfa, err := mu.Repo.GetByUserID(user.ID)
+	if err != nil {
+		mu.Log.WithField("user id", user.ID).WithError(err).Error("Enroll | fail to get mfa data from repo")
+		return nil, "", err
+	}
+
+	if mfa != nil && mfa.IsEnabled {
+		return nil, "MFA sudah aktif", errors.New("mfa already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	encrypted, err := utils.GetEncrypt([]byte(mu.Conf.Authorization.MFA.SecretKey), secret)
+	if err != nil {
+		mu.Log.WithField("user id", user.ID).WithError(err).Error("Enroll | fail to encrypt secret")
+		return nil, "", err
+	}
+
+	err = mu.Repo.UpsertSecret(nil, user.ID, encrypted)
+	if err != nil {
+		mu.Log.WithField("user id", user.ID).WithError(err).Error("Enroll | fail to store secret")
+		return nil, "", err
+	}
+
+	account := fmt.Sprintf("%v", user.ID)
+	if user.Email.Valid {
+		account = user.Email.String
+	}
+
+	uri := utils.TOTPProvisioningURI(mu.Conf.Authorization.MFA.Issuer, account, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, mfaQRCodeSize)
+	if err != nil {
+		mu.Log.WithField("user id", user.ID).WithError(err).Error("Enroll | fail to generate qr code")
+		return nil, "", err
+	}
+
+	return &da.MFAEnrollment{
+		Secret: secret,
+		URI:    uri,
+		QRCode: base64.StdEncoding.EncodeToString(png),
+	}, "success enroll MFA", nil
+}
+
+// Activate enable MFA after the user prove the authenticator app is set up.
+// The recovery codes is only shown once, only the hash is stored.
+func (mu MFAUsecase) Activate(ctx context.Context, data da.MFACodeRequest) (*da.MFARecoveryCodes, string, error) {
+	userID := da.CurrentUserID(ctx)
+
+	mfa, err := mu.Repo.GetByUserID(userID)
+	if err != nil {
+		mu.Log.WithField("user id", userID).WithError(err).Error("Activate | fail to get mfa data from repo")
+		return nil, "", err
+	}
+
+	if mfa == nil {
+		return nil, "MFA belum didaftarkan", errors.New("mfa not enrolled")
+	}
+
+	if mfa.IsEnabled {
+		return nil, "MFA sudah aktif", errors.New("mfa already enabled")
+	}
+
+	step, message, err := mu.validateCode(mfa, data.Code)
+	if err != nil {
+		return nil, message, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, "", err
+	}
+
+	tx, err := mu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = mu.Repo.Enable(tx, userID, step)
+	if err != nil {
+		tx.Rollback()
+		mu.Log.WithField("user id", userID).WithError(err).Error("Activate | fail to enable mfa")
+		return nil, "", err
+	}
+
+	err = mu.Repo.DeleteRecoveryCodes(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		mu.Log.WithField("user id", userID).WithError(err).Error("Activate | fail to delete old recovery codes")
+		return nil, "", err
+	}
+
+	err = mu.Repo.InsertRecoveryCodes(tx, userID, hashes)
+	if err != nil {
+		tx.Rollback()
+		mu.Log.WithField("user id", userID).WithError(err).Error("Activate | fail to insert recovery codes")
+		return nil, "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &da.MFARecoveryCodes{Codes: codes}, "success activate MFA", nil
+}
+
+// Verify is the second step of the login. The mfa_pending token is revoked & replaced
+// with the normal token pair when the code is valid.
+func (mu MFAUsecase) Verify(ctx context.Context, data da.MFAVerifyRequest) (*general.JWTAccess, string, error) {
+	session, ok := da.GetSession(ctx)
+	if !ok || !session.MFAPending {
+		return nil, "session not valid", errors.New("session not mfa pending")
+	}
+
+	mfa, err := mu.Repo.GetByUserID(session.UserID)
+	if err != nil {
+		mu.Log.WithField("user id", session.UserID).WithError(err).Error("Verify | fail to get mfa data from repo")
+		return nil, "", err
+	}
+
+	if mfa == nil || !mfa.IsEnabled {
+		return nil, "MFA tidak aktif", errors.New("mfa not enabled")
+	}
+
+	if data.Code == "" && data.RecoveryCode == "" {
+		return nil, "Kode MFA wajib diisi", errors.New("mfa code is empty")
+	}
+
+	now := time.Now().UTC()
+	if message, locked := mfaLockedMessage(mfa.LockedUntil, now); locked {
+		return nil, message, errors.New("mfa locked")
+	}
+
+	// The attempt is counted before the code is compared, so the parallel guess can not pass the limit.
+	attempt, err := mu.Repo.IncrementFailedAttempt(nil, session.UserID)
+	if err != nil {
+		mu.Log.WithField("user id", session.UserID).WithError(err).Error("Verify | fail to update failed attempt")
+		return nil, "", err
+	}
+
+	if attempt > mu.Conf.Authorization.OTP.MaxAttempt {
+		message, err := mu.lock(ctx, mfa, now)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return nil, message, errors.New("too many mfa attempt")
+	}
+
+	switch {
+	case data.Code != "":
+		step, message, err := mu.validateCode(mfa, data.Code)
+		if err != nil {
+			return nil, mu.failedMessage(ctx, mfa, attempt, now, message), err
+		}
+
+		err = mu.Repo.UpdateLastUsedStep(nil, session.UserID, step)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, mu.failedMessage(ctx, mfa, attempt, now, "Kode MFA sudah digunakan"), errors.New("totp code reused")
+			}
+
+			mu.Log.WithField("user id", session.UserID).WithError(err).Error("Verify | fail to update last used step")
+			return nil, "", err
+		}
+	default:
+		err = mu.Repo.UseRecoveryCode(nil, session.UserID, hashRecoveryCode(data.RecoveryCode))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, mu.failedMessage(ctx, mfa, attempt, now, "Kode pemulihan tidak valid"), errors.New("recovery code not valid")
+			}
+
+			mu.Log.WithField("user id", session.UserID).WithError(err).Error("Verify | fail to use recovery code")
+			return nil, "", err
+		}
+
+		mu.Log.WithField("user id", session.UserID).Warn("Verify | login with recovery code")
+	}
+
+	err = mu.Repo.ResetFailedAttempt(nil, session.UserID)
+	if err != nil {
+		mu.Log.WithField("user id", session.UserID).WithError(err).Error("Verify | fail to reset failed attempt")
+		return nil, "", err
+	}
+
+	err = mu.RevokedTokenRepo.InsertRevokedToken(nil, da.RevokedAccessToken{
+		ID:        session.AccessTokenID,
+		ExpiredAt: session.AccessTokenExpired,
+	})
+	if err != nil {
+		mu.Log.WithField("token id", session.AccessTokenID).WithError(err).Error("Verify | fail to revoke mfa pending token")
+		return nil, "", err
+	}
+
+	jwtAccess, err := mu.Token.IssueVerifiedToken(ctx, session.UserID)
+	if err == ErrUserNotActive {
+		return nil, "user is not active", err
+	}
+
+	if err != nil {
+		mu.Log.WithField("user id", session.UserID).WithError(err).Error("Verify | fail to issue token")
+		return nil, "", err
+	}
+
+	return jwtAccess, "success login", nil
+}
+
+// Reset remove the MFA of the user, used by admin when the user lost the authenticator app
+// & the recovery codes. Every session of the user is revoked.
+func (mu MFAUsecase) Reset(ctx context.Context, userID int64) (string, error) {
+	user, err := mu.UserRepo.GetByID(userID)
+	if err != nil {
+		mu.Log.WithField("user id", userID).WithError(err).Error("Reset | fail to get user data from repo")
+		return "", err
+	}
+
+	if user == nil {
+		return "user not found", errors.New("user not found")
+	}
+
+	mfa, err := mu.Repo.GetByUserID(userID)
+	if err != nil {
+		mu.Log.WithField("user id", userID).WithError(err).Error("Reset | fail to get mfa data from repo")
+		return "", err
+	}
+
+	detail := map[string]interface{}{
+		"is_enabled": mfa != nil && mfa.IsEnabled,
+	}
+
+	if mfa != nil {
+		detail["enabled_at"] = mfa.EnabledAt
+	}
+
+	actorID := da.CurrentUserID(ctx)
+
+	tx, err := mu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	err = mu.Repo.DeleteRecoveryCodes(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		mu.Log.WithField("user id", userID).WithError(err).Error("Reset | fail to delete recovery codes")
+		return "", err
+	}
+
+	err = mu.Repo.Delete(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		mu.Log.WithField("user id", userID).WithError(err).Error("Reset | fail to delete mfa")
+		return "", err
+	}
+
+	err = mu.insertAuditLog(tx, actorID, ca.AuditActionMFAReset, userID, detail)
+	if err != nil {
+		tx.Rollback()
+		mu.Log.WithField("user id", userID).WithError(err).Error("Reset | fail to insert audit log")
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	err = mu.Token.RevokeUserSessions(userID)
+	if err != nil {
+		return "", err
+	}
+
+	mu.Log.WithField("user id", userID).WithField("admin id", actorID).Info("Reset | user mfa reset")
+
+	return "success reset MFA", nil
+}
+
+// insertAuditLog record the admin action on the user MFA.
+func (mu MFAUsecase) insertAuditLog(tx *sql.Tx, actorID int64, action string, userID int64, detail map[string]interface{}) error {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+
+	return mu.AuditLogRepo.InsertAuditLog(tx, da.AuditLog{
+		ActorID:    null.NewInt(actorID, actorID != 0),
+		Action:     action,
+		EntityType: ca.AuditEntityUser,
+		EntityID:   fmt.Sprintf("%v", userID),
+		Detail:     string(data),
+	})
+}
+
+// failedMessage return the message of the wrong code, the verification is locked when it is the last attempt.
+// The internal error is returned as empty message.
+func (mu MFAUsecase) failedMessage(ctx context.Context, mfa *da.UserMFA, attempt int, now time.Time, message string) string {
+	if message == "" || attempt < mu.Conf.Authorization.OTP.MaxAttempt {
+		return message
+	}
+
+	lockMessage, err := mu.lock(ctx, mfa, now)
+	if err != nil {
+		return ""
+	}
+
+	return lockMessage
+}
+
+// lock lock the MFA verification of the user, the lock duration & max attempt follow the OTP setting.
+func (mu MFAUsecase) lock(ctx context.Context, mfa *da.UserMFA, now time.Time) (string, error) {
+	lockDuration := mu.Conf.Authorization.OTP.LockDurationOf(mfa.LockCount)
+	lockedUntil := now.Add(lockDuration)
+
+	locked, err := mu.Repo.Lock(nil, mfa.UserID, mfa.LockCount, lockedUntil)
+	if err != nil {
+		mu.Log.WithField("user id", mfa.UserID).WithError(err).Error("Verify | fail to lock mfa")
+		return "", err
+	}
+
+	// The parallel request that reach the limit together is only locked once.
+	if locked {
+		mu.AuthEvent.Record(ctx, ca.AuthEventLockout, mfa.UserID, "", map[string]interface{}{
+			"factor":       "mfa",
+			"lock_count":   mfa.LockCount + 1,
+			"locked_until": lockedUntil,
+		})
+	}
+
+	return fmt.Sprintf("Terlalu banyak percobaan kode MFA. Silahkan coba lagi dalam %d menit", int(lockDuration.Minutes())), nil
+}
+
+func mfaLockedMessage(lockedUntil *time.Time, now time.Time) (string, bool) {
+	if lockedUntil == nil || !now.Before(lockedUntil.UTC()) {
+		return "", false
+	}
+
+	remaining := int(math.Ceil(lockedUntil.UTC().Sub(now).Minutes()))
+
+	return fmt.Sprintf("Terlalu banyak percobaan kode MFA. Silahkan coba lagi dalam %d menit", remaining), true
+}
+
+func (mu MFAUsecase) validateCode(mfa *da.UserMFA, code string) (int64, string, error) {
+	secret, err := utils.GetDecrypt([]byte(mu.Conf.Authorization.MFA.SecretKey), mfa.Secret)
+	if err != nil {
+		mu.Log.WithField("user id", mfa.UserID).WithError(err).Error("validateCode | fail to decrypt secret")
+		return 0, "", err
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now().UTC(), mfaTOTPSkew)
+	if !ok || step <= mfa.LastUsedStep {
+		return 0, "Kode MFA tidak valid", errors.New("totp code not valid")
+	}
+
+	return step, "", nil
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0)
+	hashes := make([]string, 0)
+
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		b := make([]byte, mfaRecoveryCodeLen)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		code := hex.EncodeToString(b)
+		code = fmt.Sprintf("%s-%s", code[:5], code[5:])
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode normalize the code so it's accepted with or without the dash & in any case.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,311 @@
+package authorization
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	ca "github.com/furee/backend/constants/authorization"
+	cg "github.com/furee/backend/constants/general"
+	cu "github.com/furee/backend/constants/user"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	ru "github.com/furee/backend/repo/user"
+	"github.com/furee/backend/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// oidcRandomLen is the byte length of the state, nonce & PKCE code verifier.
+	oidcRandomLen = 32
+	// oidcDefaultStateDuration is used when the state duration is not configured.
+	oidcDefaultStateDuration = 10 * time.Minute
+)
+
+type OIDCUsecaseItf interface {
+	StartLogin(ctx context.Context) (*da.OIDCLoginResponse, string, string, error)
+	Callback(ctx context.Context, data da.OIDCCallbackRequest, encryptedState string) (*general.JWTAccess, string, error)
+}
+
+type OIDCUsecase struct {
+	UserRepo  ru.UserDataRepoItf
+	RoleRepo  ra.RoleRepoItf
+	Provider  *infra.OIDCProvider
+	Token     TokenUsecaseItf
+	AuthEvent AuthEventUsecaseItf
+	DBList    *infra.DatabaseList
+	Conf      *general.SectionService
+	Log       *logrus.Logger
+}
+
+func newOIDCUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList, token TokenUsecaseItf, event AuthEventUsecaseItf) OIDCUsecase {
+	return OIDCUsecase{
+		UserRepo:  r.User.User,
+		RoleRepo:  r.Authorization.Role,
+		Provider:  infra.NewOIDCProvider(conf.Authorization.OIDC),
+		Token:     token,
+		AuthEvent: event,
+		Conf:      conf,
+		Log:       logger,
+		DBList:    dbList,
+	}
+}
+
+// StartLogin return the authorization URL of the identity provider and the encrypted state,
+// the state is stored on the cookie so the callback can be matched with the login that started it.
+func (ou OIDCUsecase) StartLogin(ctx context.Context) (*da.OIDCLoginResponse, string, string, error) {
+	if !ou.Conf.Authorization.OIDC.IsActive {
+		return nil, "", "Login staf tidak tersedia", errors.New("oidc login not active")
+	}
+
+	state, err := generateOIDCRandom()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	nonce, err := generateOIDCRandom()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	codeVerifier, err := generateOIDCRandom()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	authURL, err := ou.Provider.AuthCodeURL(state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		ou.Log.WithError(err).Error("StartLogin | fail to get authorization url")
+		return nil, "", "", err
+	}
+
+	expiredAt := time.Now().UTC().Add(ou.stateDuration())
+
+	data, err := json.Marshal(da.OIDCState{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiredAt:    expiredAt,
+	})
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	encryptedState, err := utils.GetEncrypt([]byte(ou.Conf.App.SecretKey), string(data))
+	if err != nil {
+		ou.Log.WithError(err).Error("StartLogin | fail to encrypt state")
+		return nil, "", "", err
+	}
+
+	return &da.OIDCLoginResponse{
+		AuthorizationURL: authURL,
+		ExpiredAt:        expiredAt,
+	}, encryptedState, "", nil
+}
+
+// Callback finish the login after the identity provider redirect back with the code.
+// The staff is linked by the verified email, the role of the staff is replaced with the role mapped from the groups.
+func (ou OIDCUsecase) Callback(ctx context.Context, data da.OIDCCallbackRequest, encryptedState string) (*general.JWTAccess, string, error) {
+	if !ou.Conf.Authorization.OIDC.IsActive {
+		return nil, "Login staf tidak tersedia", errors.New("oidc login not active")
+	}
+
+	state, err := ou.getState(encryptedState)
+	if err != nil {
+		ou.recordFailed(ctx, 0, err.Error())
+		return nil, "Sesi login tidak valid. Silahkan ulangi login", err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(state.State), []byte(data.State)) != 1 {
+		ou.recordFailed(ctx, 0, "state not match")
+		return nil, "Sesi login tidak valid. Silahkan ulangi login", errors.New("state not match")
+	}
+
+	token, err := ou.Provider.Exchange(data.Code, state.CodeVerifier)
+	if err != nil {
+		ou.Log.WithError(err).Error("Callback | fail to exchange code")
+		ou.recordFailed(ctx, 0, "code exchange failed")
+		return nil, "Kode login tidak valid. Silahkan ulangi login", err
+	}
+
+	identity, err := ou.Provider.VerifyIDToken(token.IDToken, state.Nonce)
+	if err != nil {
+		ou.Log.WithError(err).Error("Callback | fail to verify id token")
+		ou.recordFailed(ctx, 0, err.Error())
+		return nil, "Kode login tidak valid. Silahkan ulangi login", err
+	}
+
+	if !identity.EmailVerified || !ou.isDomainAllowed(identity.Email) {
+		ou.recordFailed(ctx, 0, "email not allowed")
+		return nil, "Email tidak diizinkan untuk login staf", errors.New("email not allowed")
+	}
+
+	roleNames := ou.mapGroupRoles(identity.Groups)
+	if len(roleNames) == 0 {
+		ou.recordFailed(ctx, 0, "group not mapped")
+		return nil, "Akun Anda tidak memiliki akses ke internal tools", errors.New("group not mapped to any role")
+	}
+
+	user, err := ou.UserRepo.GetByEmail(identity.Email)
+	if err != nil {
+		ou.Log.WithField("email", identity.Email).WithError(err).Error("Callback | fail to get user data from repo")
+		return nil, "", err
+	}
+
+	if user == nil {
+		ou.recordFailed(ctx, 0, "user not exist")
+		return nil, "Akun staf belum terdaftar", errors.New("user not exist")
+	}
+
+	if user.Status != cu.StatusActive {
+		ou.recordFailed(ctx, user.ID, "user not active")
+		return nil, "user is not active", errors.New("user is not active")
+	}
+
+	roles, err := ou.RoleRepo.GetByNames(roleNames)
+	if err != nil {
+		ou.Log.WithField("roles", roleNames).WithError(err).Error("Callback | fail to get role from repo")
+		return nil, "", err
+	}
+
+	if len(roles) != len(roleNames) {
+		ou.Log.WithField("roles", roleNames).Warn("Callback | some of the mapped role is not exist")
+	}
+
+	tx, err := ou.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = ou.RoleRepo.DeleteUserRoles(tx, user.ID)
+	if err != nil {
+		tx.Rollback()
+		ou.Log.WithField("user id", user.ID).WithError(err).Error("Callback | fail to delete user role")
+		return nil, "", err
+	}
+
+	for _, role := range roles {
+		err = ou.RoleRepo.InsertUserRole(tx, user.ID, role.ID, int64(cg.UpdatedBySystem))
+		if err != nil {
+			tx.Rollback()
+			ou.Log.WithField("user id", user.ID).WithField("role id", role.ID).WithError(err).Error("Callback | fail to insert user role")
+			return nil, "", err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", err
+	}
+
+	ou.AuthEvent.Record(ctx, ca.AuthEventOIDCLogin, user.ID, "", map[string]interface{}{
+		"subject": identity.Subject,
+		"groups":  identity.Groups,
+		"roles":   roleNames,
+	})
+
+	jwtAccess, err := ou.Token.IssueToken(ctx, user.ID)
+	if err == ErrUserNotActive {
+		return nil, "user is not active", err
+	}
+
+	if err != nil {
+		ou.Log.WithField("user id", user.ID).WithError(err).Error("Callback | fail to get token data from infra")
+		return nil, "", err
+	}
+
+	return jwtAccess, "success login", nil
+}
+
+func (ou OIDCUsecase) getState(encryptedState string) (*da.OIDCState, error) {
+	if encryptedState == "" {
+		return nil, errors.New("state cookie not found")
+	}
+
+	data, err := utils.GetDecrypt([]byte(ou.Conf.App.SecretKey), encryptedState)
+	if err != nil {
+		return nil, errors.New("state cookie not valid")
+	}
+
+	var state da.OIDCState
+	err = json.Unmarshal([]byte(data), &state)
+	if err != nil {
+		return nil, errors.New("state cookie not valid")
+	}
+
+	if time.Now().UTC().After(state.ExpiredAt) {
+		return nil, errors.New("state cookie expired")
+	}
+
+	return &state, nil
+}
+
+func (ou OIDCUsecase) isDomainAllowed(email string) bool {
+	idx := strings.LastIndex(email, "@")
+	if idx <= 0 {
+		return false
+	}
+
+	domain := email[idx+1:]
+	for _, allowed := range ou.Conf.Authorization.OIDC.AllowedDomains {
+		if allowed != "" && strings.EqualFold(strings.TrimSpace(allowed), domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mapGroupRoles return the unique role name of the groups, the group is matched in lowercase.
+func (ou OIDCUsecase) mapGroupRoles(groups []string) []string {
+	roles := make([]string, 0)
+	isAdded := make(map[string]bool)
+
+	for _, group := range groups {
+		for _, role := range ou.Conf.Authorization.OIDC.GroupRoles[strings.ToLower(group)] {
+			if role == "" || isAdded[role] {
+				continue
+			}
+
+			isAdded[role] = true
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+func (ou OIDCUsecase) stateDuration() time.Duration {
+	if ou.Conf.Authorization.OIDC.StateDuration <= 0 {
+		return oidcDefaultStateDuration
+	}
+
+	return time.Duration(ou.Conf.Authorization.OIDC.StateDuration) * time.Minute
+}
+
+func (ou OIDCUsecase) recordFailed(ctx context.Context, userID int64, reason string) {
+	ou.AuthEvent.Record(ctx, ca.AuthEventOIDCFailed, userID, "", map[string]interface{}{
+		"reason": reason,
+	})
+}
+
+func generateOIDCRandom() (string, error) {
+	b := make([]byte, oidcRandomLen)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
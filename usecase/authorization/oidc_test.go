@@ -0,0 +1,349 @@
+package authorization
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	ca "github.com/furee/backend/constants/authorization"
+	cu "github.com/furee/backend/constants/user"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/infra"
+	ra "github.com/furee/backend/repo/authorization"
+	ru "github.com/furee/backend/repo/user"
+	"github.com/furee/backend/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// txDriver is the database driver that only support the transaction, the query is done by the stub repo.
+type txDriver struct{}
+
+func (txDriver) Open(name string) (driver.Conn, error) { return txConn{}, nil }
+
+type txConn struct{}
+
+func (txConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (txConn) Close() error                              { return nil }
+func (txConn) Begin() (driver.Tx, error)                 { return txConn{}, nil }
+func (txConn) Commit() error                             { return nil }
+func (txConn) Rollback() error                           { return nil }
+
+func init() {
+	sql.Register("oidc-test-tx", txDriver{})
+}
+
+type stubDatabase struct {
+	infra.Database
+	db *sql.DB
+}
+
+func (sd stubDatabase) Begin() (*sql.Tx, error) { return sd.db.Begin() }
+
+type stubUserRepo struct {
+	ru.UserDataRepoItf
+	users map[string]du.User
+}
+
+func (sr stubUserRepo) GetByEmail(email string) (*du.User, error) {
+	user, ok := sr.users[email]
+	if !ok {
+		return nil, nil
+	}
+
+	return &user, nil
+}
+
+type stubRoleRepo struct {
+	ra.RoleRepoItf
+	assigned map[int64][]int64
+}
+
+var stubRoles = map[string]int64{"admin": 1, "support": 2}
+
+func (sr stubRoleRepo) GetByNames(names []string) ([]da.Role, error) {
+	var roles []da.Role
+	for _, name := range names {
+		if id, ok := stubRoles[name]; ok {
+			roles = append(roles, da.Role{ID: id, Name: name})
+		}
+	}
+
+	return roles, nil
+}
+
+func (sr stubRoleRepo) DeleteUserRoles(tx *sql.Tx, userID int64) error {
+	delete(sr.assigned, userID)
+	return nil
+}
+
+func (sr stubRoleRepo) InsertUserRole(tx *sql.Tx, userID, roleID, createdBy int64) error {
+	sr.assigned[userID] = append(sr.assigned[userID], roleID)
+	return nil
+}
+
+type stubTokenUsecase struct {
+	TokenUsecaseItf
+}
+
+func (st stubTokenUsecase) IssueToken(ctx context.Context, userID int64) (*general.JWTAccess, error) {
+	return &general.JWTAccess{}, nil
+}
+
+type stubAuthEvent struct {
+	AuthEventUsecaseItf
+	events *[]string
+}
+
+func (se stubAuthEvent) Record(ctx context.Context, eventType string, userID int64, phoneHash string, detail map[string]interface{}) {
+	*se.events = append(*se.events, eventType)
+}
+
+// oidcTestProvider is the identity provider that return the ID token with the claims of the test case.
+type oidcTestProvider struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	signKey  *rsa.PrivateKey
+	claims   jwt.MapClaims
+	verifier string
+}
+
+func newOIDCTestProvider(t *testing.T) *oidcTestProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tp := &oidcTestProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(res http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(res).Encode(da.OIDCDiscovery{
+			Issuer:                tp.server.URL,
+			AuthorizationEndpoint: tp.server.URL + "/authorize",
+			TokenEndpoint:         tp.server.URL + "/token",
+			JWKSURI:               tp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/token", func(res http.ResponseWriter, req *http.Request) {
+		tp.verifier = req.FormValue("code_verifier")
+		if req.FormValue("code") != "valid-code" {
+			res.WriteHeader(http.StatusBadRequest)
+			res.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, tp.claims)
+		token.Header["kid"] = "test"
+
+		idToken, err := token.SignedString(tp.signKey)
+		if err != nil {
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(res).Encode(da.OIDCTokenResponse{IDToken: idToken, TokenType: "Bearer"})
+	})
+	mux.HandleFunc("/jwks", func(res http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(res).Encode(general.JWKS{Keys: []general.JWK{{
+			KeyType:  "RSA",
+			KeyID:    "test",
+			Use:      "sig",
+			Modulus:  base64.RawURLEncoding.EncodeToString(tp.key.N.Bytes()),
+			Exponent: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(tp.key.E)).Bytes()),
+		}}})
+	})
+
+	tp.server = httptest.NewServer(mux)
+	t.Cleanup(tp.server.Close)
+
+	return tp
+}
+
+func newTestOIDCUsecase(t *testing.T, issuerURL string, events *[]string, assigned map[int64][]int64) OIDCUsecase {
+	db, err := sql.Open("oidc-test-tx", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	conf := &general.SectionService{}
+	conf.App.SecretKey = "0123456789abcdef0123456789abcdef"
+	conf.Authorization.OIDC = general.OIDCCredential{
+		IsActive:       true,
+		IssuerURL:      issuerURL,
+		ClientID:       "internal-tools",
+		ClientSecret:   "secret",
+		RedirectURL:    "http://localhost/callback",
+		Scopes:         []string{"openid", "email"},
+		AllowedDomains: []string{"furee.id"},
+		GroupClaim:     "groups",
+		GroupRoles: map[string][]string{
+			"engineering": {"admin"},
+			"cs":          {"support"},
+		},
+	}
+
+	return OIDCUsecase{
+		UserRepo: stubUserRepo{users: map[string]du.User{
+			"staff@furee.id":    {ID: 10, Status: cu.StatusActive},
+			"inactive@furee.id": {ID: 11, Status: cu.StatusBlocked},
+		}},
+		RoleRepo:  stubRoleRepo{assigned: assigned},
+		Provider:  infra.NewOIDCProvider(conf.Authorization.OIDC),
+		Token:     stubTokenUsecase{},
+		AuthEvent: stubAuthEvent{events: events},
+		DBList:    &infra.DatabaseList{Backend: infra.DatabaseType{Write: stubDatabase{db: db}}},
+		Conf:      conf,
+		Log:       logrus.New(),
+	}
+}
+
+func TestOIDCCallback(t *testing.T) {
+	tp := newOIDCTestProvider(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		change    func(claims jwt.MapClaims)
+		code      string
+		state     string
+		signKey   *rsa.PrivateKey
+		wantRoles []int64
+		wantEvent string
+	}{
+		{name: "success", wantRoles: []int64{1}, wantEvent: ca.AuthEventOIDCLogin},
+		{name: "group mapped to other role", change: func(c jwt.MapClaims) { c["groups"] = []string{"CS", "unknown"} }, wantRoles: []int64{2}, wantEvent: ca.AuthEventOIDCLogin},
+		{name: "state not match", state: "other-state", wantEvent: ca.AuthEventOIDCFailed},
+		{name: "invalid code", code: "other-code", wantEvent: ca.AuthEventOIDCFailed},
+		{name: "nonce not match", change: func(c jwt.MapClaims) { c["nonce"] = "other-nonce" }, wantEvent: ca.AuthEventOIDCFailed},
+		{name: "wrong audience", change: func(c jwt.MapClaims) { c["aud"] = "other-client" }, wantEvent: ca.AuthEventOIDCFailed},
+		{name: "wrong issuer", change: func(c jwt.MapClaims) { c["iss"] = "http://other-issuer" }, wantEvent: ca.AuthEventOIDCFailed},
+		{name: "expired", change: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, wantEvent: ca.AuthEventOIDCFailed},
+		{name: "signed by other key", signKey: otherKey, wantEvent: ca.AuthEventOIDCFailed},
+		{name: "email not verified", change: func(c jwt.MapClaims) { c["email_verified"] = false }, wantEvent: ca.AuthEventOIDCFailed},
+		{name: "email domain not allowed", change: func(c jwt.MapClaims) { c["email"] = "staff@other.id" }, wantEvent: ca.AuthEventOIDCFailed},
+		{name: "group not mapped", change: func(c jwt.MapClaims) { c["groups"] = []string{"finance"} }, wantEvent: ca.AuthEventOIDCFailed},
+		{name: "user not exist", change: func(c jwt.MapClaims) { c["email"] = "new@furee.id" }, wantEvent: ca.AuthEventOIDCFailed},
+		{name: "user not active", change: func(c jwt.MapClaims) { c["email"] = "inactive@furee.id" }, wantEvent: ca.AuthEventOIDCFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := &[]string{}
+			assigned := map[int64][]int64{10: {2}, 11: {2}}
+			ou := newTestOIDCUsecase(t, tp.server.URL, events, assigned)
+
+			login, encryptedState, _, err := ou.StartLogin(context.Background())
+			if err != nil {
+				t.Fatalf("StartLogin error = %v", err)
+			}
+
+			authURL, err := url.Parse(login.AuthorizationURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := authURL.Query()
+
+			tp.signKey = tp.key
+			if tt.signKey != nil {
+				tp.signKey = tt.signKey
+			}
+
+			tp.claims = jwt.MapClaims{
+				"iss":            tp.server.URL,
+				"sub":            "staff",
+				"aud":            "internal-tools",
+				"exp":            time.Now().Add(time.Minute).Unix(),
+				"nonce":          query.Get("nonce"),
+				"email":          "Staff@furee.id",
+				"email_verified": true,
+				"groups":         []string{"engineering"},
+			}
+			if tt.change != nil {
+				tt.change(tp.claims)
+			}
+
+			code, state := "valid-code", query.Get("state")
+			if tt.code != "" {
+				code = tt.code
+			}
+			if tt.state != "" {
+				state = tt.state
+			}
+
+			_, message, err := ou.Callback(context.Background(), da.OIDCCallbackRequest{Code: code, State: state}, encryptedState)
+
+			if tt.wantRoles != nil {
+				if err != nil {
+					t.Fatalf("Callback error = %v (%s)", err, message)
+				}
+
+				challenge := sha256.Sum256([]byte(tp.verifier))
+				if base64.RawURLEncoding.EncodeToString(challenge[:]) != query.Get("code_challenge") {
+					t.Errorf("code verifier doesn't match the code challenge")
+				}
+
+				if got := assigned[10]; len(got) != len(tt.wantRoles) || got[0] != tt.wantRoles[0] {
+					t.Errorf("roles = %v, want %v", got, tt.wantRoles)
+				}
+			} else {
+				if err == nil || message == "" {
+					t.Fatalf("Callback = (%q, %v), want rejected", message, err)
+				}
+
+				if got := assigned[10]; len(got) != 1 || got[0] != 2 {
+					t.Errorf("roles of the rejected login = %v, want unchanged", got)
+				}
+			}
+
+			if len(*events) != 1 || (*events)[0] != tt.wantEvent {
+				t.Errorf("auth events = %v, want [%s]", *events, tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackStateCookie(t *testing.T) {
+	tp := newOIDCTestProvider(t)
+	ou := newTestOIDCUsecase(t, tp.server.URL, &[]string{}, map[int64][]int64{})
+
+	expired, err := json.Marshal(da.OIDCState{State: "state", ExpiredAt: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiredState, err := utils.GetEncrypt([]byte(ou.Conf.App.SecretKey), string(expired))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, encryptedState := range map[string]string{
+		"empty":   "",
+		"invalid": "not-encrypted",
+		"expired": expiredState,
+	} {
+		_, message, err := ou.Callback(context.Background(), da.OIDCCallbackRequest{Code: "valid-code", State: "state"}, encryptedState)
+		if err == nil || message == "" {
+			t.Errorf("%s: Callback = (%q, %v), want rejected", name, message, err)
+		}
+	}
+}
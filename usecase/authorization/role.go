@@ -0,0 +1,166 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ca "github.com/furee/backend/constants/authorization"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	ru "github.com/furee/backend/repo/user"
+	"github.com/furee/backend/utils"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+)
+
+type RoleUsecaseItf interface {
+	AssignRoles(ctx context.Context, userID int64, data da.AssignRoleRequest) ([]da.Role, string, error)
+}
+
+type RoleUsecase struct {
+	Repo         ra.RoleRepoItf
+	UserRepo     ru.UserDataRepoItf
+	AuditLogRepo ra.AuditLogRepoItf
+	DBList       *infra.DatabaseList
+	Conf         *general.SectionService
+	Log          *logrus.Logger
+}
+
+func newRoleUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList) RoleUsecase {
+	return RoleUsecase{
+		Repo:         r.Authorization.Role,
+		UserRepo:     r.User.User,
+		AuditLogRepo: r.Authorization.AuditLog,
+		Conf:         conf,
+		Log:          logger,
+		DBList:       dbList,
+	}
+}
+
+// AssignRoles replace the role of the user with the given roles.
+// The admin can only give & take the role which permission is owned by the admin, and can not change the own role.
+// The new role is included on the user token after the next login or renew token.
+func (ru RoleUsecase) AssignRoles(ctx context.Context, userID int64, data da.AssignRoleRequest) ([]da.Role, string, error) {
+	var roles []da.Role
+	var err error
+
+	session, ok := da.GetSession(ctx)
+	if !ok {
+		return nil, "session not found", errors.New("session not found")
+	}
+
+	actorID := da.CurrentUserID(ctx)
+	if userID == actorID {
+		return nil, "cannot change own role", errors.New("cannot change own role")
+	}
+
+	user, err := ru.UserRepo.GetByID(userID)
+	if err != nil {
+		ru.Log.WithField("user id", userID).WithError(err).Error("AssignRoles | fail to get user from repo")
+		return nil, "", err
+	}
+
+	if user == nil {
+		return nil, "user not found", errors.New("user not found")
+	}
+
+	if len(data.Roles) > 0 {
+		roles, err = ru.Repo.GetByNames(data.Roles)
+		if err != nil {
+			ru.Log.WithField("request", utils.StructToString(data)).WithError(err).Error("AssignRoles | fail to get role from repo")
+			return nil, "", err
+		}
+
+		if len(roles) != len(data.Roles) {
+			return nil, "role not found", errors.New("role not found")
+		}
+	}
+
+	currentRoles, err := ru.Repo.GetByUserID(userID)
+	if err != nil {
+		ru.Log.WithField("user id", userID).WithError(err).Error("AssignRoles | fail to get user role from repo")
+		return nil, "", err
+	}
+
+	// Both the given & the removed role is checked, so the admin can not strip the role of a higher admin.
+	roleIDs := make([]int64, 0, len(roles)+len(currentRoles))
+	for _, role := range append(append([]da.Role{}, roles...), currentRoles...) {
+		roleIDs = append(roleIDs, role.ID)
+	}
+
+	permissions, err := ru.Repo.GetPermissionsByRoleIDs(roleIDs)
+	if err != nil {
+		ru.Log.WithField("user id", userID).WithError(err).Error("AssignRoles | fail to get role permission from repo")
+		return nil, "", err
+	}
+
+	for _, permission := range permissions {
+		if !session.HasPermission(permission) {
+			return nil, fmt.Sprintf("permission %s is not owned", permission), errors.New("permission not owned by admin")
+		}
+	}
+
+	tx, err := ru.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = ru.Repo.DeleteUserRoles(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		ru.Log.WithField("user id", userID).WithError(err).Error("AssignRoles | fail to delete user role")
+		return nil, "", err
+	}
+
+	for _, role := range roles {
+		err = ru.Repo.InsertUserRole(tx, userID, role.ID, actorID)
+		if err != nil {
+			tx.Rollback()
+			ru.Log.WithField("user id", userID).WithField("role id", role.ID).WithError(err).Error("AssignRoles | fail to insert user role")
+			return nil, "", err
+		}
+	}
+
+	detail, err := json.Marshal(map[string]interface{}{
+		"roles":          roleNames(roles),
+		"previous_roles": roleNames(currentRoles),
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	err = ru.AuditLogRepo.InsertAuditLog(tx, da.AuditLog{
+		ActorID:    null.NewInt(actorID, actorID != 0),
+		Action:     ca.AuditActionRoleAssign,
+		EntityType: ca.AuditEntityUser,
+		EntityID:   fmt.Sprintf("%v", userID),
+		Detail:     string(detail),
+	})
+	if err != nil {
+		tx.Rollback()
+		ru.Log.WithField("user id", userID).WithError(err).Error("AssignRoles | fail to insert audit log")
+		return nil, "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return roles, "success assign role", nil
+}
+
+func roleNames(roles []da.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+
+	return names
+}
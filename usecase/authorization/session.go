@@ -0,0 +1,156 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	cg "github.com/furee/backend/constants/general"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	"github.com/sirupsen/logrus"
+)
+
+// sessionLastSeenInterval limit how often the last seen time is updated for the same session.
+const sessionLastSeenInterval = time.Minute
+
+type SessionUsecaseItf interface {
+	GetListSession(ctx context.Context) ([]da.SessionResponse, string, error)
+	RevokeSession(ctx context.Context, sessionID string) (string, error)
+	ValidateSession(session da.TokenSession) (string, error)
+}
+
+type SessionUsecase struct {
+	Repo             ra.SessionRepoItf
+	RefreshTokenRepo ra.RefreshTokenRepoItf
+	DBList           *infra.DatabaseList
+	Conf             *general.SectionService
+	Log              *logrus.Logger
+}
+
+func newSessionUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList) SessionUsecase {
+	return SessionUsecase{
+		Repo:             r.Authorization.Session,
+		RefreshTokenRepo: r.Authorization.RefreshToken,
+		Conf:             conf,
+		Log:              logger,
+		DBList:           dbList,
+	}
+}
+
+// GetListSession return the active session of the current user, the session of the request is marked as current.
+func (su SessionUsecase) GetListSession(ctx context.Context) ([]da.SessionResponse, string, error) {
+	session, ok := da.GetSession(ctx)
+	if !ok {
+		return nil, "session not found", errors.New("session not found")
+	}
+
+	sessions, err := su.Repo.GetListByUserID(session.UserID)
+	if err != nil {
+		su.Log.WithField("user id", session.UserID).WithError(err).Error("GetListSession | fail to get session from repo")
+		return nil, "", err
+	}
+
+	res := make([]da.SessionResponse, 0)
+	for _, s := range sessions {
+		res = append(res, da.SessionResponse{
+			ID:         s.ID,
+			DeviceName: s.DeviceName,
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			IsCurrent:  s.FamilyID == session.FamilyID,
+		})
+	}
+
+	return res, "", nil
+}
+
+// RevokeSession revoke the session & the token family of the session, the access token
+// of the session is rejected on the next request.
+func (su SessionUsecase) RevokeSession(ctx context.Context, sessionID string) (string, error) {
+	userID := da.CurrentUserID(ctx)
+
+	stored, err := su.Repo.GetByID(sessionID)
+	if err != nil {
+		su.Log.WithField("session id", sessionID).WithError(err).Error("RevokeSession | fail to get session from repo")
+		return "", err
+	}
+
+	if stored == nil || stored.UserID != userID || stored.RevokedAt != nil {
+		return "session not found", errors.New("session not found")
+	}
+
+	tx, err := su.DBList.Backend.Write.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	err = su.Repo.RevokeByID(tx, stored.ID)
+	if err != nil {
+		tx.Rollback()
+		su.Log.WithField("session id", sessionID).WithError(err).Error("RevokeSession | fail to revoke session")
+		return "", err
+	}
+
+	err = su.RefreshTokenRepo.RevokeFamily(tx, stored.FamilyID)
+	if err != nil {
+		tx.Rollback()
+		su.Log.WithField("session id", sessionID).WithError(err).Error("RevokeSession | fail to revoke token family")
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	return "success revoke session", nil
+}
+
+// ValidateSession reject the token when the session is revoked or removed & update the last seen time in background.
+// Only the mfa_pending token is accepted without session, any other token without jti or family (e.g. issued
+// before the session is recorded) is rejected since it can't be revoked.
+func (su SessionUsecase) ValidateSession(session da.TokenSession) (string, error) {
+	if session.AccessTokenID == "" {
+		return cg.HandlerErrorTokenInvalid, errors.New("token without jti")
+	}
+
+	if session.MFAPending {
+		return "", nil
+	}
+
+	if session.FamilyID == "" {
+		return cg.HandlerErrorTokenInvalid, errors.New("token without family")
+	}
+
+	stored, err := su.Repo.GetByFamilyID(session.FamilyID)
+	if err != nil {
+		su.Log.WithField("family id", session.FamilyID).WithError(err).Error("ValidateSession | fail to get session from repo")
+		return "", err
+	}
+
+	if stored == nil {
+		return "Session not found", errors.New("session not found")
+	}
+
+	if stored.RevokedAt != nil {
+		return "Session revoked", errors.New("session revoked")
+	}
+
+	now := time.Now().UTC()
+	if now.Sub(stored.LastSeenAt) >= sessionLastSeenInterval {
+		go func() {
+			err := su.Repo.UpdateLastSeen(session.FamilyID, now)
+			if err != nil {
+				su.Log.WithField("family id", session.FamilyID).WithError(err).Error("ValidateSession | fail to update last seen")
+			}
+		}()
+	}
+
+	return "", nil
+}
@@ -0,0 +1,449 @@
+package authorization
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	ca "github.com/furee/backend/constants/authorization"
+	cu "github.com/furee/backend/constants/user"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	ru "github.com/furee/backend/repo/user"
+	"github.com/furee/backend/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUserNotActive is returned when the token is requested for the user that is not active.
+var ErrUserNotActive = errors.New("user is not active")
+
+type TokenUsecaseItf interface {
+	IssueToken(ctx context.Context, userID int64) (*general.JWTAccess, error)
+	IssueVerifiedToken(ctx context.Context, userID int64) (*general.JWTAccess, error)
+	RenewToken(ctx context.Context, refreshToken string) (*general.JWTAccess, string, error)
+	Logout(ctx context.Context) error
+	LogoutAll(ctx context.Context) error
+	RevokeOtherSessions(ctx context.Context) error
+	RevokeUserSessions(userID int64) error
+	IsAccessTokenRevoked(tokenID string) (bool, error)
+}
+
+type TokenUsecase struct {
+	RefreshTokenRepo ra.RefreshTokenRepoItf
+	RevokedTokenRepo ra.RevokedTokenRepoItf
+	RoleRepo         ra.RoleRepoItf
+	MFARepo          ra.MFARepoItf
+	SessionRepo      ra.SessionRepoItf
+	UserRepo         ru.UserDataRepoItf
+	AuthEvent        AuthEventUsecaseItf
+	DBList           *infra.DatabaseList
+	Conf             *general.SectionService
+	Log              *logrus.Logger
+}
+
+func newTokenUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList, event AuthEventUsecaseItf) TokenUsecase {
+	return TokenUsecase{
+		RefreshTokenRepo: r.Authorization.RefreshToken,
+		RevokedTokenRepo: r.Authorization.RevokedToken,
+		RoleRepo:         r.Authorization.Role,
+		MFARepo:          r.Authorization.MFA,
+		SessionRepo:      r.Authorization.Session,
+		UserRepo:         r.User.User,
+		AuthEvent:        event,
+		Conf:             conf,
+		Log:              logger,
+		DBList:           dbList,
+	}
+}
+
+// IssueToken create a new token family & session for the user, used after login succeed.
+// The device of the session is taken from the context. When the user has MFA enabled, only short lived access token with mfa_pending claim is returned,
+// the token family is created after the second factor verified.
+// ErrUserNotActive is returned for the blocked or deleted user.
+func (tu TokenUsecase) IssueToken(ctx context.Context, userID int64) (*general.JWTAccess, error) {
+	err := tu.checkActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := utils.GetEncrypt([]byte(tu.Conf.App.SecretKey), fmt.Sprintf("%v", userID))
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := tu.MFARepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa != nil && mfa.IsEnabled {
+		token, err := utils.GenerateMFAPendingToken(session, time.Duration(tu.Conf.Authorization.MFA.PendingDuration)*time.Minute)
+		if err != nil {
+			return nil, err
+		}
+
+		return &general.JWTAccess{
+			AccessToken:        token.AccessToken,
+			AccessTokenExpired: token.AccessTokenExpired.Format(time.RFC3339),
+			MFAPending:         true,
+		}, nil
+	}
+
+	return tu.issueToken(ctx, userID, session)
+}
+
+// IssueVerifiedToken create a new token family for the user that already pass the second factor.
+// ErrUserNotActive is returned for the blocked or deleted user.
+func (tu TokenUsecase) IssueVerifiedToken(ctx context.Context, userID int64) (*general.JWTAccess, error) {
+	err := tu.checkActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := utils.GetEncrypt([]byte(tu.Conf.App.SecretKey), fmt.Sprintf("%v", userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return tu.issueToken(ctx, userID, session)
+}
+
+// checkActiveUser return ErrUserNotActive when the user is not exist or not active.
+func (tu TokenUsecase) checkActiveUser(userID int64) error {
+	user, err := tu.UserRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil || user.Status != cu.StatusActive {
+		return ErrUserNotActive
+	}
+
+	return nil
+}
+
+func (tu TokenUsecase) issueToken(ctx context.Context, userID int64, session string) (*general.JWTAccess, error) {
+	access, err := tu.getTokenAccess(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateJWT(session, "", access)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := utils.GetUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := tu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tu.RefreshTokenRepo.InsertRefreshToken(tx, da.RefreshToken{
+		ID:        token.RefreshTokenID,
+		FamilyID:  token.FamilyID,
+		UserID:    userID,
+		ExpiredAt: token.RefreshTokenExpired,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	device := da.GetDevice(ctx)
+	err = tu.SessionRepo.InsertSession(tx, da.UserSession{
+		ID:         sessionID,
+		UserID:     userID,
+		FamilyID:   token.FamilyID,
+		DeviceName: device.Name,
+		UserAgent:  device.UserAgent,
+		IPAddress:  device.IPAddress,
+		ExpiredAt:  token.RefreshTokenExpired,
+		LastSeenAt: time.Now().UTC(),
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return toJWTAccess(token), nil
+}
+
+// RenewToken rotate the refresh token. The old refresh token can not be used anymore,
+// when it's used again the whole token family is revoked because the token is leaked.
+func (tu TokenUsecase) RenewToken(ctx context.Context, refreshToken string) (*general.JWTAccess, string, error) {
+	claims, err := utils.CheckRefreshToken(refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tokenID := utils.GetClaimString(claims, "jti")
+	session := utils.GetClaimString(claims, "session")
+
+	stored, err := tu.RefreshTokenRepo.GetByID(tokenID)
+	if err != nil {
+		tu.Log.WithField("token id", tokenID).WithError(err).Error("RenewToken | fail to get refresh token from repo")
+		return nil, "", err
+	}
+
+	if stored == nil {
+		return nil, "refresh token not valid", errors.New("refresh token not found")
+	}
+
+	if stored.RevokedAt != nil {
+		tu.revokeReusedFamily(ctx, stored)
+		return nil, "refresh token not valid", errors.New("refresh token reused")
+	}
+
+	if time.Now().UTC().After(stored.ExpiredAt.UTC()) {
+		return nil, "refresh token expired", errors.New("refresh token expired")
+	}
+
+	// Blocked or deleted user can not renew the token, the family is revoked so the session is ended.
+	user, err := tu.UserRepo.GetByID(stored.UserID)
+	if err != nil {
+		tu.Log.WithField("user id", stored.UserID).WithError(err).Error("RenewToken | fail to get user from repo")
+		return nil, "", err
+	}
+
+	if user == nil || user.Status != cu.StatusActive {
+		tu.revokeFamily(stored)
+		return nil, "refresh token not valid", errors.New("user is not active")
+	}
+
+	// Role is loaded again, so the change of user role is applied on the next renew.
+	access, err := tu.getTokenAccess(stored.UserID)
+	if err != nil {
+		tu.Log.WithField("user id", stored.UserID).WithError(err).Error("RenewToken | fail to get user role")
+		return nil, "", err
+	}
+
+	token, err := utils.GenerateJWT(session, stored.FamilyID, access)
+	if err != nil {
+		tu.Log.WithField("token id", tokenID).WithError(err).Error("RenewToken | fail to generate token")
+		return nil, "", err
+	}
+
+	tx, err := tu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = tu.RefreshTokenRepo.InsertRefreshToken(tx, da.RefreshToken{
+		ID:        token.RefreshTokenID,
+		FamilyID:  stored.FamilyID,
+		UserID:    stored.UserID,
+		ExpiredAt: token.RefreshTokenExpired,
+	})
+	if err != nil {
+		tx.Rollback()
+		tu.Log.WithField("token id", tokenID).WithError(err).Error("RenewToken | fail to insert refresh token")
+		return nil, "", err
+	}
+
+	err = tu.RefreshTokenRepo.RotateRefreshToken(tx, stored.ID, token.RefreshTokenID)
+	if err != nil {
+		tx.Rollback()
+
+		// Another request already rotated this token.
+		if err == sql.ErrNoRows {
+			tu.revokeReusedFamily(ctx, stored)
+			return nil, "refresh token not valid", errors.New("refresh token reused")
+		}
+
+		tu.Log.WithField("token id", tokenID).WithError(err).Error("RenewToken | fail to rotate refresh token")
+		return nil, "", err
+	}
+
+	err = tu.SessionRepo.UpdateExpired(tx, stored.FamilyID, token.RefreshTokenExpired)
+	if err != nil {
+		tx.Rollback()
+		tu.Log.WithField("family id", stored.FamilyID).WithError(err).Error("RenewToken | fail to update session")
+		return nil, "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", err
+	}
+
+	tu.AuthEvent.Record(ctx, ca.AuthEventTokenRenew, stored.UserID, "", map[string]interface{}{
+		"family_id": stored.FamilyID,
+	})
+
+	return toJWTAccess(token), "success generate new access token", nil
+}
+
+// Logout revoke the token family of the current session & the access token itself.
+func (tu TokenUsecase) Logout(ctx context.Context) error {
+	session, ok := da.GetSession(ctx)
+	if !ok {
+		return errors.New("session not found")
+	}
+
+	err := tu.RefreshTokenRepo.RevokeFamily(nil, session.FamilyID)
+	if err != nil {
+		tu.Log.WithField("family id", session.FamilyID).WithError(err).Error("Logout | fail to revoke token family")
+		return err
+	}
+
+	err = tu.SessionRepo.RevokeByFamilyID(nil, session.FamilyID)
+	if err != nil {
+		tu.Log.WithField("family id", session.FamilyID).WithError(err).Error("Logout | fail to revoke session")
+		return err
+	}
+
+	tu.AuthEvent.Record(ctx, ca.AuthEventLogout, session.UserID, "", map[string]interface{}{
+		"family_id": session.FamilyID,
+	})
+
+	return tu.revokeAccessToken(session)
+}
+
+// LogoutAll revoke every token family of the user & the current access token.
+func (tu TokenUsecase) LogoutAll(ctx context.Context) error {
+	session, ok := da.GetSession(ctx)
+	if !ok {
+		return errors.New("session not found")
+	}
+
+	err := tu.RefreshTokenRepo.RevokeByUserID(nil, session.UserID)
+	if err != nil {
+		tu.Log.WithField("user id", session.UserID).WithError(err).Error("LogoutAll | fail to revoke user token")
+		return err
+	}
+
+	err = tu.SessionRepo.RevokeByUserID(nil, session.UserID)
+	if err != nil {
+		tu.Log.WithField("user id", session.UserID).WithError(err).Error("LogoutAll | fail to revoke user session")
+		return err
+	}
+
+	tu.AuthEvent.Record(ctx, ca.AuthEventLogout, session.UserID, "", map[string]interface{}{
+		"all_sessions": true,
+	})
+
+	return tu.revokeAccessToken(session)
+}
+
+// RevokeOtherSessions revoke every token family of the user except the current session.
+func (tu TokenUsecase) RevokeOtherSessions(ctx context.Context) error {
+	session, ok := da.GetSession(ctx)
+	if !ok {
+		return errors.New("session not found")
+	}
+
+	err := tu.RefreshTokenRepo.RevokeOtherFamilies(nil, session.UserID, session.FamilyID)
+	if err != nil {
+		tu.Log.WithField("user id", session.UserID).WithError(err).Error("RevokeOtherSessions | fail to revoke other token family")
+		return err
+	}
+
+	err = tu.SessionRepo.RevokeOtherFamilies(nil, session.UserID, session.FamilyID)
+	if err != nil {
+		tu.Log.WithField("user id", session.UserID).WithError(err).Error("RevokeOtherSessions | fail to revoke other session")
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserSessions revoke every token family & session of the given user, used by admin to force logout.
+// Access token that already issued is rejected because the session is revoked.
+func (tu TokenUsecase) RevokeUserSessions(userID int64) error {
+	err := tu.RefreshTokenRepo.RevokeByUserID(nil, userID)
+	if err != nil {
+		tu.Log.WithField("user id", userID).WithError(err).Error("RevokeUserSessions | fail to revoke user token")
+		return err
+	}
+
+	err = tu.SessionRepo.RevokeByUserID(nil, userID)
+	if err != nil {
+		tu.Log.WithField("user id", userID).WithError(err).Error("RevokeUserSessions | fail to revoke user session")
+		return err
+	}
+
+	return nil
+}
+
+func (tu TokenUsecase) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	return tu.RevokedTokenRepo.IsRevoked(tokenID)
+}
+
+func (tu TokenUsecase) revokeAccessToken(session da.TokenSession) error {
+	err := tu.RevokedTokenRepo.InsertRevokedToken(nil, da.RevokedAccessToken{
+		ID:        session.AccessTokenID,
+		ExpiredAt: session.AccessTokenExpired,
+	})
+	if err != nil {
+		tu.Log.WithField("token id", session.AccessTokenID).WithError(err).Error("fail to revoke access token")
+		return err
+	}
+
+	return nil
+}
+
+func (tu TokenUsecase) revokeReusedFamily(ctx context.Context, token *da.RefreshToken) {
+	tu.Log.WithField("family id", token.FamilyID).WithField("user id", token.UserID).Warn("RenewToken | refresh token reuse detected, revoke token family")
+	tu.AuthEvent.Record(ctx, ca.AuthEventTokenReuse, token.UserID, "", map[string]interface{}{
+		"family_id": token.FamilyID,
+	})
+
+	tu.revokeFamily(token)
+}
+
+// revokeFamily revoke the refresh token & session of the token family, the error is only logged.
+func (tu TokenUsecase) revokeFamily(token *da.RefreshToken) {
+	err := tu.RefreshTokenRepo.RevokeFamily(nil, token.FamilyID)
+	if err != nil {
+		tu.Log.WithField("family id", token.FamilyID).WithError(err).Error("RenewToken | fail to revoke token family")
+	}
+
+	err = tu.SessionRepo.RevokeByFamilyID(nil, token.FamilyID)
+	if err != nil {
+		tu.Log.WithField("family id", token.FamilyID).WithError(err).Error("RenewToken | fail to revoke session")
+	}
+}
+
+func (tu TokenUsecase) getTokenAccess(userID int64) (utils.TokenAccess, error) {
+	var access utils.TokenAccess
+
+	roles, err := tu.RoleRepo.GetByUserID(userID)
+	if err != nil {
+		return access, err
+	}
+
+	for _, role := range roles {
+		access.Roles = append(access.Roles, role.Name)
+	}
+
+	access.Permissions, err = tu.RoleRepo.GetPermissionsByUserID(userID)
+	if err != nil {
+		return access, err
+	}
+
+	return access, nil
+}
+
+func toJWTAccess(token utils.JWTToken) *general.JWTAccess {
+	return &general.JWTAccess{
+		AccessToken:        token.AccessToken,
+		AccessTokenExpired: token.AccessTokenExpired.Format(time.RFC3339),
+		RenewToken:         token.RefreshToken,
+		RenewTokenExpired:  token.RefreshTokenExpired.Format(time.RFC3339),
+	}
+}
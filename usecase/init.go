@@ -4,6 +4,7 @@ import (
 	"github.com/furee/backend/domain/general"
 	"github.com/furee/backend/infra"
 	"github.com/furee/backend/repo"
+	"github.com/furee/backend/usecase/authorization"
 	"github.com/furee/backend/usecase/master"
 	"github.com/furee/backend/usecase/order"
 	"github.com/furee/backend/usecase/user"
@@ -11,15 +12,24 @@ import (
 )
 
 type Usecase struct {
-	Master master.MasterUsecase
-	User   user.UserUsecase
-	Order  order.OrderUsecase
+	Master        master.MasterUsecase
+	User          user.UserUsecase
+	Order         order.OrderUsecase
+	Authorization authorization.AuthorizationUsecase
 }
 
-func NewUsecase(repo repo.Repo, conf *general.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger) Usecase {
-	return Usecase{
-		Master: master.NewUsecase(repo, conf, dbList, logger),
-		User:   user.NewUsecase(repo, conf, dbList, logger),
-		Order:  order.NewUsecase(repo, conf, dbList, logger),
+func NewUsecase(repo repo.Repo, conf *general.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger) (Usecase, error) {
+	auth := authorization.NewUsecase(repo, conf, dbList, logger)
+
+	masterUsecase, err := master.NewUsecase(repo, conf, dbList, logger)
+	if err != nil {
+		return Usecase{}, err
 	}
+
+	return Usecase{
+		Master:        masterUsecase,
+		User:          user.NewUsecase(repo, conf, dbList, logger, auth),
+		Order:         order.NewUsecase(repo, conf, dbList, logger),
+		Authorization: auth,
+	}, nil
 }
@@ -0,0 +1,868 @@
+package master
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	ca "github.com/furee/backend/constants/authorization"
+	da "github.com/furee/backend/domain/authorization"
+	gen "github.com/furee/backend/domain/general"
+	domain "github.com/furee/backend/domain/master"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	"github.com/furee/backend/repo/master"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/dealancer/validate.v2"
+	"gopkg.in/guregu/null.v4"
+)
+
+type AdminUsecaseItf interface {
+	CreateCountry(ctx context.Context, data domain.CountryRequest) (*domain.Country, string, error)
+	UpdateCountry(ctx context.Context, countryID int64, data domain.CountryRequest) (*domain.Country, string, error)
+	DeactivateCountry(ctx context.Context, countryID int64) (string, error)
+	CreateProvince(ctx context.Context, data domain.ProvinceRequest) (*domain.Province, string, error)
+	UpdateProvince(ctx context.Context, provinceID int64, data domain.ProvinceRequest) (*domain.Province, string, error)
+	DeactivateProvince(ctx context.Context, provinceID int64) (string, error)
+	CreateCity(ctx context.Context, data domain.CityRequest) (*domain.City, string, error)
+	UpdateCity(ctx context.Context, cityID int64, data domain.CityRequest) (*domain.City, string, error)
+	DeactivateCity(ctx context.Context, cityID int64) (string, error)
+	CreateDistrict(ctx context.Context, data domain.DistrictRequest) (*domain.District, string, error)
+	UpdateDistrict(ctx context.Context, districtID int64, data domain.DistrictRequest) (*domain.District, string, error)
+	DeactivateDistrict(ctx context.Context, districtID int64) (string, error)
+	CreateSubDistrict(ctx context.Context, data domain.SubDistrictRequest) (*domain.SubDistrict, string, error)
+	UpdateSubDistrict(ctx context.Context, subDistrictID int64, data domain.SubDistrictRequest) (*domain.SubDistrict, string, error)
+	DeactivateSubDistrict(ctx context.Context, subDistrictID int64) (string, error)
+	ImportLocation(ctx context.Context, files map[string]io.Reader, dryRun bool) (*domain.LocationImportReport, string, error)
+}
+
+// AdminUsecase write the master data, every change is recorded on the audit log
+// & the master cache is invalidated after the change is committed.
+type AdminUsecase struct {
+	CountryRepo     master.CountryRepoItf
+	ProvinceRepo    master.ProvinceRepoItf
+	CityRepo        master.CityRepoItf
+	DistrictRepo    master.DistrictRepoItf
+	SubDistrictRepo master.SubDistrictRepoItf
+	AuditLogRepo    ra.AuditLogRepoItf
+	Cache           CacheUsecaseItf
+	DBList          *infra.DatabaseList
+	Log             *logrus.Logger
+}
+
+func newAdminUsecase(r repo.Repo, logger *logrus.Logger, dbList *infra.DatabaseList, cache CacheUsecaseItf) AdminUsecase {
+	return AdminUsecase{
+		CountryRepo:     r.Master.Country,
+		ProvinceRepo:    r.Master.Province,
+		CityRepo:        r.Master.City,
+		DistrictRepo:    r.Master.District,
+		SubDistrictRepo: r.Master.SubDistrict,
+		AuditLogRepo:    r.Authorization.AuditLog,
+		Cache:           cache,
+		Log:             logger,
+		DBList:          dbList,
+	}
+}
+
+func (au AdminUsecase) CreateCountry(ctx context.Context, data domain.CountryRequest) (*domain.Country, string, error) {
+	country := domain.Country{
+		Name:     strings.TrimSpace(data.Name),
+		IsActive: true,
+	}
+
+	if country.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	message, err := au.checkDuplicateCountry(country)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterCreate, ca.AuditEntityCountry, map[string]interface{}{"after": &country}, func(tx *sql.Tx) (int64, error) {
+		var err error
+		country.ID, err = au.CountryRepo.InsertCountry(tx, country)
+		return country.ID, err
+	})
+	if err != nil {
+		au.Log.WithField("name", country.Name).WithError(err).Error("CreateCountry | fail to save country")
+		return nil, "", err
+	}
+
+	return &country, "success create country", nil
+}
+
+func (au AdminUsecase) UpdateCountry(ctx context.Context, countryID int64, data domain.CountryRequest) (*domain.Country, string, error) {
+	before, err := au.CountryRepo.GetByID(countryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "country not found", err
+		}
+
+		au.Log.WithField("country id", countryID).WithError(err).Error("UpdateCountry | fail to get country from repo")
+		return nil, "", err
+	}
+
+	country := before
+	country.Name = strings.TrimSpace(data.Name)
+
+	if country.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	message, err := au.checkDuplicateCountry(country)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterUpdate, ca.AuditEntityCountry, map[string]interface{}{"before": before, "after": country}, func(tx *sql.Tx) (int64, error) {
+		return country.ID, au.CountryRepo.UpdateCountry(tx, country)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "country not found", err
+		}
+
+		au.Log.WithField("country id", countryID).WithError(err).Error("UpdateCountry | fail to save country")
+		return nil, "", err
+	}
+
+	return &country, "success update country", nil
+}
+
+// DeactivateCountry is refused while the country still has active province, so no active data is orphaned.
+func (au AdminUsecase) DeactivateCountry(ctx context.Context, countryID int64) (string, error) {
+	before, err := au.CountryRepo.GetByID(countryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "country not found", err
+		}
+
+		au.Log.WithField("country id", countryID).WithError(err).Error("DeactivateCountry | fail to get country from repo")
+		return "", err
+	}
+
+	if !before.IsActive {
+		return "country already inactive", errors.New("already inactive")
+	}
+
+	total, err := au.countActiveChildren(ca.AuditEntityCountry, countryID)
+	if err != nil {
+		au.Log.WithField("country id", countryID).WithError(err).Error("DeactivateCountry | fail to count active province")
+		return "", err
+	}
+
+	if total > 0 {
+		return "country still has active province", errors.New("active children exist")
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterDeactivate, ca.AuditEntityCountry, map[string]interface{}{"before": before}, func(tx *sql.Tx) (int64, error) {
+		return countryID, au.CountryRepo.DeactivateCountry(tx, countryID)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "country not found", err
+		}
+
+		au.Log.WithField("country id", countryID).WithError(err).Error("DeactivateCountry | fail to save country")
+		return "", err
+	}
+
+	return "success deactivate country", nil
+}
+
+func (au AdminUsecase) checkDuplicateCountry(data domain.Country) (string, error) {
+	duplicate, err := au.CountryRepo.GetDuplicateName(data)
+	if err != nil {
+		au.Log.WithField("name", data.Name).WithError(err).Error("checkDuplicateCountry | fail to get country from repo")
+		return "", err
+	}
+
+	if duplicate != nil {
+		return "country name already exist", errors.New("duplicate name")
+	}
+
+	return "", nil
+}
+
+func (au AdminUsecase) CreateProvince(ctx context.Context, data domain.ProvinceRequest) (*domain.Province, string, error) {
+	province := domain.Province{
+		CountryID: data.CountryID,
+		Name:      strings.TrimSpace(data.Name),
+		IsActive:  true,
+	}
+
+	if province.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	message, err := au.checkParent(ca.AuditEntityCountry, province.CountryID)
+	if err != nil {
+		return nil, message, err
+	}
+
+	message, err = au.checkDuplicateProvince(province)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterCreate, ca.AuditEntityProvince, map[string]interface{}{"after": &province}, func(tx *sql.Tx) (int64, error) {
+		var err error
+		province.ID, err = au.ProvinceRepo.InsertProvince(tx, province)
+		return province.ID, err
+	})
+	if err != nil {
+		au.Log.WithField("name", province.Name).WithError(err).Error("CreateProvince | fail to save province")
+		return nil, "", err
+	}
+
+	return &province, "success create province", nil
+}
+
+func (au AdminUsecase) UpdateProvince(ctx context.Context, provinceID int64, data domain.ProvinceRequest) (*domain.Province, string, error) {
+	before, err := au.ProvinceRepo.GetByID(provinceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "province not found", err
+		}
+
+		au.Log.WithField("province id", provinceID).WithError(err).Error("UpdateProvince | fail to get province from repo")
+		return nil, "", err
+	}
+
+	province := before
+	province.CountryID = data.CountryID
+	province.Name = strings.TrimSpace(data.Name)
+
+	if province.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	// The parent is only checked when moved, so the name is still editable under the deactivated parent.
+	if province.CountryID != before.CountryID {
+		message, err := au.checkParent(ca.AuditEntityCountry, province.CountryID)
+		if err != nil {
+			return nil, message, err
+		}
+	}
+
+	message, err := au.checkDuplicateProvince(province)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterUpdate, ca.AuditEntityProvince, map[string]interface{}{"before": before, "after": province}, func(tx *sql.Tx) (int64, error) {
+		return province.ID, au.ProvinceRepo.UpdateProvince(tx, province)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "province not found", err
+		}
+
+		au.Log.WithField("province id", provinceID).WithError(err).Error("UpdateProvince | fail to save province")
+		return nil, "", err
+	}
+
+	return &province, "success update province", nil
+}
+
+// DeactivateProvince is refused while the province still has active city, so no active data is orphaned.
+func (au AdminUsecase) DeactivateProvince(ctx context.Context, provinceID int64) (string, error) {
+	before, err := au.ProvinceRepo.GetByID(provinceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "province not found", err
+		}
+
+		au.Log.WithField("province id", provinceID).WithError(err).Error("DeactivateProvince | fail to get province from repo")
+		return "", err
+	}
+
+	if !before.IsActive {
+		return "province already inactive", errors.New("already inactive")
+	}
+
+	total, err := au.countActiveChildren(ca.AuditEntityProvince, provinceID)
+	if err != nil {
+		au.Log.WithField("province id", provinceID).WithError(err).Error("DeactivateProvince | fail to count active city")
+		return "", err
+	}
+
+	if total > 0 {
+		return "province still has active city", errors.New("active children exist")
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterDeactivate, ca.AuditEntityProvince, map[string]interface{}{"before": before}, func(tx *sql.Tx) (int64, error) {
+		return provinceID, au.ProvinceRepo.DeactivateProvince(tx, provinceID)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "province not found", err
+		}
+
+		au.Log.WithField("province id", provinceID).WithError(err).Error("DeactivateProvince | fail to save province")
+		return "", err
+	}
+
+	return "success deactivate province", nil
+}
+
+func (au AdminUsecase) checkDuplicateProvince(data domain.Province) (string, error) {
+	duplicate, err := au.ProvinceRepo.GetDuplicateName(data)
+	if err != nil {
+		au.Log.WithField("name", data.Name).WithError(err).Error("checkDuplicateProvince | fail to get province from repo")
+		return "", err
+	}
+
+	if duplicate != nil {
+		return "province name already exist on the country", errors.New("duplicate name")
+	}
+
+	return "", nil
+}
+
+func (au AdminUsecase) CreateCity(ctx context.Context, data domain.CityRequest) (*domain.City, string, error) {
+	city := domain.City{
+		ProvinceID: data.ProvinceID,
+		Name:       strings.TrimSpace(data.Name),
+		IsActive:   true,
+	}
+
+	if city.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	message, err := au.checkParent(ca.AuditEntityProvince, city.ProvinceID)
+	if err != nil {
+		return nil, message, err
+	}
+
+	message, err = au.checkDuplicateCity(city)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterCreate, ca.AuditEntityCity, map[string]interface{}{"after": &city}, func(tx *sql.Tx) (int64, error) {
+		var err error
+		city.ID, err = au.CityRepo.InsertCity(tx, city)
+		return city.ID, err
+	})
+	if err != nil {
+		au.Log.WithField("name", city.Name).WithError(err).Error("CreateCity | fail to save city")
+		return nil, "", err
+	}
+
+	return &city, "success create city", nil
+}
+
+func (au AdminUsecase) UpdateCity(ctx context.Context, cityID int64, data domain.CityRequest) (*domain.City, string, error) {
+	before, err := au.CityRepo.GetByID(cityID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "city not found", err
+		}
+
+		au.Log.WithField("city id", cityID).WithError(err).Error("UpdateCity | fail to get city from repo")
+		return nil, "", err
+	}
+
+	city := before
+	city.ProvinceID = data.ProvinceID
+	city.Name = strings.TrimSpace(data.Name)
+
+	if city.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	// The parent is only checked when moved, so the name is still editable under the deactivated parent.
+	if city.ProvinceID != before.ProvinceID {
+		message, err := au.checkParent(ca.AuditEntityProvince, city.ProvinceID)
+		if err != nil {
+			return nil, message, err
+		}
+	}
+
+	message, err := au.checkDuplicateCity(city)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterUpdate, ca.AuditEntityCity, map[string]interface{}{"before": before, "after": city}, func(tx *sql.Tx) (int64, error) {
+		return city.ID, au.CityRepo.UpdateCity(tx, city)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "city not found", err
+		}
+
+		au.Log.WithField("city id", cityID).WithError(err).Error("UpdateCity | fail to save city")
+		return nil, "", err
+	}
+
+	return &city, "success update city", nil
+}
+
+// DeactivateCity is refused while the city still has active district, so no active data is orphaned.
+func (au AdminUsecase) DeactivateCity(ctx context.Context, cityID int64) (string, error) {
+	before, err := au.CityRepo.GetByID(cityID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "city not found", err
+		}
+
+		au.Log.WithField("city id", cityID).WithError(err).Error("DeactivateCity | fail to get city from repo")
+		return "", err
+	}
+
+	if !before.IsActive {
+		return "city already inactive", errors.New("already inactive")
+	}
+
+	total, err := au.countActiveChildren(ca.AuditEntityCity, cityID)
+	if err != nil {
+		au.Log.WithField("city id", cityID).WithError(err).Error("DeactivateCity | fail to count active district")
+		return "", err
+	}
+
+	if total > 0 {
+		return "city still has active district", errors.New("active children exist")
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterDeactivate, ca.AuditEntityCity, map[string]interface{}{"before": before}, func(tx *sql.Tx) (int64, error) {
+		return cityID, au.CityRepo.DeactivateCity(tx, cityID)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "city not found", err
+		}
+
+		au.Log.WithField("city id", cityID).WithError(err).Error("DeactivateCity | fail to save city")
+		return "", err
+	}
+
+	return "success deactivate city", nil
+}
+
+func (au AdminUsecase) checkDuplicateCity(data domain.City) (string, error) {
+	duplicate, err := au.CityRepo.GetDuplicateName(data)
+	if err != nil {
+		au.Log.WithField("name", data.Name).WithError(err).Error("checkDuplicateCity | fail to get city from repo")
+		return "", err
+	}
+
+	if duplicate != nil {
+		return "city name already exist on the province", errors.New("duplicate name")
+	}
+
+	return "", nil
+}
+
+func (au AdminUsecase) CreateDistrict(ctx context.Context, data domain.DistrictRequest) (*domain.District, string, error) {
+	metadata, err := parseDistrictMetadata(data.Metadata)
+	if err != nil {
+		return nil, "metadata not valid", err
+	}
+
+	district := domain.District{
+		CityID:   data.CityID,
+		Name:     strings.TrimSpace(data.Name),
+		IsActive: true,
+		Metadata: metadata,
+	}
+
+	if district.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	message, err := au.checkParent(ca.AuditEntityCity, district.CityID)
+	if err != nil {
+		return nil, message, err
+	}
+
+	message, err = au.checkDuplicateDistrict(district)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterCreate, ca.AuditEntityDistrict, map[string]interface{}{"after": &district}, func(tx *sql.Tx) (int64, error) {
+		var err error
+		district.ID, err = au.DistrictRepo.InsertDistrict(tx, district)
+		return district.ID, err
+	})
+	if err != nil {
+		au.Log.WithField("name", district.Name).WithError(err).Error("CreateDistrict | fail to save district")
+		return nil, "", err
+	}
+
+	return &district, "success create district", nil
+}
+
+func (au AdminUsecase) UpdateDistrict(ctx context.Context, districtID int64, data domain.DistrictRequest) (*domain.District, string, error) {
+	before, err := au.DistrictRepo.GetByID(districtID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "district not found", err
+		}
+
+		au.Log.WithField("district id", districtID).WithError(err).Error("UpdateDistrict | fail to get district from repo")
+		return nil, "", err
+	}
+
+	district := before
+	district.CityID = data.CityID
+	district.Name = strings.TrimSpace(data.Name)
+
+	district.Metadata, err = parseDistrictMetadata(data.Metadata)
+	if err != nil {
+		return nil, "metadata not valid", err
+	}
+
+	if district.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	// The parent is only checked when moved, so the name is still editable under the deactivated parent.
+	if district.CityID != before.CityID {
+		message, err := au.checkParent(ca.AuditEntityCity, district.CityID)
+		if err != nil {
+			return nil, message, err
+		}
+	}
+
+	message, err := au.checkDuplicateDistrict(district)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterUpdate, ca.AuditEntityDistrict, map[string]interface{}{"before": before, "after": district}, func(tx *sql.Tx) (int64, error) {
+		return district.ID, au.DistrictRepo.UpdateDistrict(tx, district)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "district not found", err
+		}
+
+		au.Log.WithField("district id", districtID).WithError(err).Error("UpdateDistrict | fail to save district")
+		return nil, "", err
+	}
+
+	return &district, "success update district", nil
+}
+
+// DeactivateDistrict is refused while the district still has active sub district, so no active data is orphaned.
+func (au AdminUsecase) DeactivateDistrict(ctx context.Context, districtID int64) (string, error) {
+	before, err := au.DistrictRepo.GetByID(districtID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "district not found", err
+		}
+
+		au.Log.WithField("district id", districtID).WithError(err).Error("DeactivateDistrict | fail to get district from repo")
+		return "", err
+	}
+
+	if !before.IsActive {
+		return "district already inactive", errors.New("already inactive")
+	}
+
+	total, err := au.countActiveChildren(ca.AuditEntityDistrict, districtID)
+	if err != nil {
+		au.Log.WithField("district id", districtID).WithError(err).Error("DeactivateDistrict | fail to count active sub district")
+		return "", err
+	}
+
+	if total > 0 {
+		return "district still has active sub district", errors.New("active children exist")
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterDeactivate, ca.AuditEntityDistrict, map[string]interface{}{"before": before}, func(tx *sql.Tx) (int64, error) {
+		return districtID, au.DistrictRepo.DeactivateDistrict(tx, districtID)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "district not found", err
+		}
+
+		au.Log.WithField("district id", districtID).WithError(err).Error("DeactivateDistrict | fail to save district")
+		return "", err
+	}
+
+	return "success deactivate district", nil
+}
+
+func (au AdminUsecase) checkDuplicateDistrict(data domain.District) (string, error) {
+	duplicate, err := au.DistrictRepo.GetDuplicateName(data)
+	if err != nil {
+		au.Log.WithField("name", data.Name).WithError(err).Error("checkDuplicateDistrict | fail to get district from repo")
+		return "", err
+	}
+
+	if duplicate != nil {
+		return "district name already exist on the city", errors.New("duplicate name")
+	}
+
+	return "", nil
+}
+
+func (au AdminUsecase) CreateSubDistrict(ctx context.Context, data domain.SubDistrictRequest) (*domain.SubDistrict, string, error) {
+	subDistrict := domain.SubDistrict{
+		DistrictID: data.DistrictID,
+		Name:       strings.TrimSpace(data.Name),
+		IsActive:   true,
+	}
+
+	if subDistrict.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	message, err := au.checkParent(ca.AuditEntityDistrict, subDistrict.DistrictID)
+	if err != nil {
+		return nil, message, err
+	}
+
+	message, err = au.checkDuplicateSubDistrict(subDistrict)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterCreate, ca.AuditEntitySubDistrict, map[string]interface{}{"after": &subDistrict}, func(tx *sql.Tx) (int64, error) {
+		var err error
+		subDistrict.ID, err = au.SubDistrictRepo.InsertSubDistrict(tx, subDistrict)
+		return subDistrict.ID, err
+	})
+	if err != nil {
+		au.Log.WithField("name", subDistrict.Name).WithError(err).Error("CreateSubDistrict | fail to save sub district")
+		return nil, "", err
+	}
+
+	return &subDistrict, "success create sub district", nil
+}
+
+func (au AdminUsecase) UpdateSubDistrict(ctx context.Context, subDistrictID int64, data domain.SubDistrictRequest) (*domain.SubDistrict, string, error) {
+	before, err := au.SubDistrictRepo.GetByID(subDistrictID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "sub district not found", err
+		}
+
+		au.Log.WithField("sub district id", subDistrictID).WithError(err).Error("UpdateSubDistrict | fail to get sub district from repo")
+		return nil, "", err
+	}
+
+	subDistrict := before
+	subDistrict.DistrictID = data.DistrictID
+	subDistrict.Name = strings.TrimSpace(data.Name)
+
+	if subDistrict.Name == "" {
+		return nil, "name cannot be empty", errors.New("name empty")
+	}
+
+	// The parent is only checked when moved, so the name is still editable under the deactivated parent.
+	if subDistrict.DistrictID != before.DistrictID {
+		message, err := au.checkParent(ca.AuditEntityDistrict, subDistrict.DistrictID)
+		if err != nil {
+			return nil, message, err
+		}
+	}
+
+	message, err := au.checkDuplicateSubDistrict(subDistrict)
+	if err != nil {
+		return nil, message, err
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterUpdate, ca.AuditEntitySubDistrict, map[string]interface{}{"before": before, "after": subDistrict}, func(tx *sql.Tx) (int64, error) {
+		return subDistrict.ID, au.SubDistrictRepo.UpdateSubDistrict(tx, subDistrict)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "sub district not found", err
+		}
+
+		au.Log.WithField("sub district id", subDistrictID).WithError(err).Error("UpdateSubDistrict | fail to save sub district")
+		return nil, "", err
+	}
+
+	return &subDistrict, "success update sub district", nil
+}
+
+func (au AdminUsecase) DeactivateSubDistrict(ctx context.Context, subDistrictID int64) (string, error) {
+	before, err := au.SubDistrictRepo.GetByID(subDistrictID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "sub district not found", err
+		}
+
+		au.Log.WithField("sub district id", subDistrictID).WithError(err).Error("DeactivateSubDistrict | fail to get sub district from repo")
+		return "", err
+	}
+
+	if !before.IsActive {
+		return "sub district already inactive", errors.New("already inactive")
+	}
+
+	err = au.save(ctx, ca.AuditActionMasterDeactivate, ca.AuditEntitySubDistrict, map[string]interface{}{"before": before}, func(tx *sql.Tx) (int64, error) {
+		return subDistrictID, au.SubDistrictRepo.DeactivateSubDistrict(tx, subDistrictID)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "sub district not found", err
+		}
+
+		au.Log.WithField("sub district id", subDistrictID).WithError(err).Error("DeactivateSubDistrict | fail to save sub district")
+		return "", err
+	}
+
+	return "success deactivate sub district", nil
+}
+
+func (au AdminUsecase) checkDuplicateSubDistrict(data domain.SubDistrict) (string, error) {
+	duplicate, err := au.SubDistrictRepo.GetDuplicateName(data)
+	if err != nil {
+		au.Log.WithField("name", data.Name).WithError(err).Error("checkDuplicateSubDistrict | fail to get sub district from repo")
+		return "", err
+	}
+
+	if duplicate != nil {
+		return "sub district name already exist on the district", errors.New("duplicate name")
+	}
+
+	return "", nil
+}
+
+// checkParent make sure the parent exist & active, so the new data is not orphaned.
+func (au AdminUsecase) checkParent(entity string, parentID int64) (string, error) {
+	var isActive bool
+	var err error
+
+	switch entity {
+	case ca.AuditEntityCountry:
+		var data domain.Country
+		data, err = au.CountryRepo.GetByID(parentID)
+		isActive = data.IsActive
+	case ca.AuditEntityProvince:
+		var data domain.Province
+		data, err = au.ProvinceRepo.GetByID(parentID)
+		isActive = data.IsActive
+	case ca.AuditEntityCity:
+		var data domain.City
+		data, err = au.CityRepo.GetByID(parentID)
+		isActive = data.IsActive
+	case ca.AuditEntityDistrict:
+		var data domain.District
+		data, err = au.DistrictRepo.GetByID(parentID)
+		isActive = data.IsActive
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Sprintf("%s not found", entity), err
+		}
+
+		au.Log.WithField("entity", entity).WithField("id", parentID).WithError(err).Error("checkParent | fail to get parent from repo")
+		return "", err
+	}
+
+	if !isActive {
+		return fmt.Sprintf("%s is not active", entity), errors.New("parent not active")
+	}
+
+	return "", nil
+}
+
+func (au AdminUsecase) countActiveChildren(entity string, id int64) (int64, error) {
+	pagination := gen.GetPagination()
+	pagination.Limit = 1
+
+	var total int64
+	var err error
+
+	switch entity {
+	case ca.AuditEntityCountry:
+		total, _, err = au.ProvinceRepo.GetTotalDataProvince(pagination, domain.ProvinceFilter{CountryID: null.IntFrom(id), IsActive: null.BoolFrom(true)})
+	case ca.AuditEntityProvince:
+		total, _, err = au.CityRepo.GetTotalDataCity(pagination, domain.CityFilter{ProvinceID: null.IntFrom(id), IsActive: null.BoolFrom(true)})
+	case ca.AuditEntityCity:
+		total, _, err = au.DistrictRepo.GetTotalDataDistrict(pagination, domain.DistrictFilter{CityID: null.IntFrom(id), IsActive: null.BoolFrom(true)})
+	case ca.AuditEntityDistrict:
+		total, _, err = au.SubDistrictRepo.GetTotalDataSubDistrict(pagination, domain.SubDistrictFilter{DistrictID: null.IntFrom(id), IsActive: null.BoolFrom(true)})
+	}
+
+	return total, err
+}
+
+// save run the write & the audit log on the same transaction, then invalidate the master cache.
+// The detail is marshalled after the write, so the id of the new data is included.
+func (au AdminUsecase) save(ctx context.Context, action, entityType string, detail interface{}, write func(tx *sql.Tx) (int64, error)) error {
+	tx, err := au.DBList.Backend.Write.Begin()
+	if err != nil {
+		return err
+	}
+
+	entityID, err := write(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	raw, err := json.Marshal(detail)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = au.AuditLogRepo.InsertAuditLog(tx, da.AuditLog{
+		ActorID:    null.NewInt(da.CurrentUserID(ctx), da.CurrentUserID(ctx) != 0),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   fmt.Sprintf("%v", entityID),
+		Detail:     string(raw),
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	au.Cache.Invalidate(ctx)
+
+	return nil
+}
+
+// parseDistrictMetadata refuse the unknown field, the metadata is stored in the DistrictMetadata format.
+func parseDistrictMetadata(raw json.RawMessage) (null.String, error) {
+	if len(bytes.TrimSpace(raw)) == 0 || string(bytes.TrimSpace(raw)) == "null" {
+		return null.String{}, nil
+	}
+
+	var metadata domain.DistrictMetadata
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&metadata)
+	if err != nil {
+		return null.String{}, err
+	}
+
+	err = validate.Validate(metadata)
+	if err != nil {
+		return null.String{}, err
+	}
+
+	value, err := json.Marshal(metadata)
+	if err != nil {
+		return null.String{}, err
+	}
+
+	return null.StringFrom(string(value)), nil
+}
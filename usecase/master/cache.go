@@ -0,0 +1,260 @@
+package master
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	cg "github.com/furee/backend/constants/general"
+	cm "github.com/furee/backend/constants/master"
+	gen "github.com/furee/backend/domain/general"
+	domain "github.com/furee/backend/domain/master"
+	"github.com/furee/backend/infra"
+	"github.com/sirupsen/logrus"
+)
+
+const masterCachePrefix = "master:"
+
+type CacheUsecaseItf interface {
+	Invalidate(ctx context.Context)
+}
+
+// CacheUsecase keep the master data list keyed by the filter & pagination.
+// The cache fail is only logged, the data is taken from the database.
+type CacheUsecase struct {
+	Cache infra.Cache
+	Conf  *gen.SectionService
+	Log   *logrus.Logger
+}
+
+func newCacheUsecase(conf *gen.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList) (CacheUsecase, error) {
+	cache, err := infra.NewCache(conf.Cache.Backend, conf.Cache.MaxEntries, dbList.Redis)
+	if err != nil {
+		return CacheUsecase{}, err
+	}
+
+	return CacheUsecase{
+		Cache: cache,
+		Conf:  conf,
+		Log:   logger,
+	}, nil
+}
+
+// Invalidate remove all the master data cache, must be called after the master data is written.
+func (cu CacheUsecase) Invalidate(ctx context.Context) {
+	err := cu.Cache.DeletePrefix(ctx, masterCachePrefix)
+	if err != nil {
+		cu.Log.WithError(err).Error("Invalidate | fail to delete master cache")
+	}
+}
+
+func (cu CacheUsecase) get(name string, pagination gen.PaginationData, filter interface{}, dest interface{}) bool {
+	if cu.Conf.Cache.GetTTL(name) <= 0 {
+		return false
+	}
+
+	key, err := cu.key(name, pagination, filter)
+	if err != nil {
+		return false
+	}
+
+	value, ok, err := cu.Cache.Get(context.Background(), key)
+	if err != nil {
+		cu.Log.WithField("key", key).WithError(err).Error("get | fail to get master cache")
+		return false
+	}
+
+	if !ok {
+		return false
+	}
+
+	err = json.Unmarshal(value, dest)
+	if err != nil {
+		cu.Log.WithField("key", key).WithError(err).Error("get | fail to unmarshal master cache")
+		return false
+	}
+
+	return true
+}
+
+func (cu CacheUsecase) set(name string, pagination gen.PaginationData, filter interface{}, data interface{}) {
+	ttl := cu.Conf.Cache.GetTTL(name)
+	if ttl <= 0 {
+		return
+	}
+
+	key, err := cu.key(name, pagination, filter)
+	if err != nil {
+		cu.Log.WithField("name", name).WithError(err).Error("set | fail to create master cache key")
+		return
+	}
+
+	value, err := json.Marshal(data)
+	if err != nil {
+		cu.Log.WithField("key", key).WithError(err).Error("set | fail to marshal master cache")
+		return
+	}
+
+	err = cu.Cache.Set(context.Background(), key, value, ttl)
+	if err != nil {
+		cu.Log.WithField("key", key).WithError(err).Error("set | fail to set master cache")
+	}
+}
+
+func (cu CacheUsecase) key(name string, pagination gen.PaginationData, filter interface{}) (string, error) {
+	raw, err := json.Marshal(struct {
+		Pagination gen.PaginationData
+		Filter     interface{}
+	}{
+		Pagination: pagination,
+		Filter:     filter,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(raw)
+
+	return masterCachePrefix + name + ":" + hex.EncodeToString(hash[:]), nil
+}
+
+type cachedCountryUsecase struct {
+	CountryUsecaseItf
+	cache CacheUsecase
+}
+
+func (cu cachedCountryUsecase) GetListCountry(pagination gen.PaginationData, filter domain.CountryFilter) ([]domain.Country, gen.PaginationData, string, error) {
+	var cached struct {
+		Data       []domain.Country
+		Pagination gen.PaginationData
+	}
+
+	if cu.cache.get(cm.LocationLevelCountry, pagination, filter, &cached) {
+		return cached.Data, cached.Pagination, cg.SourceFromCache, nil
+	}
+
+	data, result, source, err := cu.CountryUsecaseItf.GetListCountry(pagination, filter)
+	if err != nil {
+		return data, result, source, err
+	}
+
+	// The key use the requested pagination, the result has the total data.
+	cached.Data = data
+	cached.Pagination = result
+	cu.cache.set(cm.LocationLevelCountry, pagination, filter, cached)
+
+	return data, result, source, nil
+}
+
+type cachedProvinceUsecase struct {
+	ProvinceUsecaseItf
+	cache CacheUsecase
+}
+
+func (cu cachedProvinceUsecase) GetListProvince(pagination gen.PaginationData, filter domain.ProvinceFilter) ([]domain.Province, gen.PaginationData, string, error) {
+	var cached struct {
+		Data       []domain.Province
+		Pagination gen.PaginationData
+	}
+
+	if cu.cache.get(cm.LocationLevelProvince, pagination, filter, &cached) {
+		return cached.Data, cached.Pagination, cg.SourceFromCache, nil
+	}
+
+	data, result, source, err := cu.ProvinceUsecaseItf.GetListProvince(pagination, filter)
+	if err != nil {
+		return data, result, source, err
+	}
+
+	// The key use the requested pagination, the result has the total data.
+	cached.Data = data
+	cached.Pagination = result
+	cu.cache.set(cm.LocationLevelProvince, pagination, filter, cached)
+
+	return data, result, source, nil
+}
+
+type cachedCityUsecase struct {
+	CityUsecaseItf
+	cache CacheUsecase
+}
+
+func (cu cachedCityUsecase) GetListCity(pagination gen.PaginationData, filter domain.CityFilter) ([]domain.City, gen.PaginationData, string, error) {
+	var cached struct {
+		Data       []domain.City
+		Pagination gen.PaginationData
+	}
+
+	if cu.cache.get(cm.LocationLevelCity, pagination, filter, &cached) {
+		return cached.Data, cached.Pagination, cg.SourceFromCache, nil
+	}
+
+	data, result, source, err := cu.CityUsecaseItf.GetListCity(pagination, filter)
+	if err != nil {
+		return data, result, source, err
+	}
+
+	// The key use the requested pagination, the result has the total data.
+	cached.Data = data
+	cached.Pagination = result
+	cu.cache.set(cm.LocationLevelCity, pagination, filter, cached)
+
+	return data, result, source, nil
+}
+
+type cachedDistrictUsecase struct {
+	DistrictUsecaseItf
+	cache CacheUsecase
+}
+
+func (cu cachedDistrictUsecase) GetListDistrict(pagination gen.PaginationData, filter domain.DistrictFilter) ([]domain.District, gen.PaginationData, string, error) {
+	var cached struct {
+		Data       []domain.District
+		Pagination gen.PaginationData
+	}
+
+	if cu.cache.get(cm.LocationLevelDistrict, pagination, filter, &cached) {
+		return cached.Data, cached.Pagination, cg.SourceFromCache, nil
+	}
+
+	data, result, source, err := cu.DistrictUsecaseItf.GetListDistrict(pagination, filter)
+	if err != nil {
+		return data, result, source, err
+	}
+
+	// The key use the requested pagination, the result has the total data.
+	cached.Data = data
+	cached.Pagination = result
+	cu.cache.set(cm.LocationLevelDistrict, pagination, filter, cached)
+
+	return data, result, source, nil
+}
+
+type cachedSubDistrictUsecase struct {
+	SubDistrictUsecaseItf
+	cache CacheUsecase
+}
+
+func (cu cachedSubDistrictUsecase) GetListSubDistrict(pagination gen.PaginationData, filter domain.SubDistrictFilter) ([]domain.SubDistrict, gen.PaginationData, string, error) {
+	var cached struct {
+		Data       []domain.SubDistrict
+		Pagination gen.PaginationData
+	}
+
+	if cu.cache.get(cm.LocationLevelSubDistrict, pagination, filter, &cached) {
+		return cached.Data, cached.Pagination, cg.SourceFromCache, nil
+	}
+
+	data, result, source, err := cu.SubDistrictUsecaseItf.GetListSubDistrict(pagination, filter)
+	if err != nil {
+		return data, result, source, err
+	}
+
+	// The key use the requested pagination, the result has the total data.
+	cached.Data = data
+	cached.Pagination = result
+	cu.cache.set(cm.LocationLevelSubDistrict, pagination, filter, cached)
+
+	return data, result, source, nil
+}
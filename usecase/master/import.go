@@ -0,0 +1,593 @@
+package master
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	ca "github.com/furee/backend/constants/authorization"
+	cm "github.com/furee/backend/constants/master"
+	da "github.com/furee/backend/domain/authorization"
+	gen "github.com/furee/backend/domain/general"
+	domain "github.com/furee/backend/domain/master"
+	"gopkg.in/guregu/null.v4"
+)
+
+// importEntity is the common field of the location on every level, used while the import is compared.
+type importEntity struct {
+	id       int64
+	parentID int64
+	name     string
+	code     null.String
+	isActive bool
+	metadata null.String
+}
+
+// ImportLocation upsert the location by the official region code, level by level from the country.
+// The level without file is not changed, the active data with code that no longer exist on the file is deactivated.
+// The active children of the deactivated data is deactivated too, on every level below it.
+// On dry run the change is rolled back, so the report show the exact change without committing it.
+func (au AdminUsecase) ImportLocation(ctx context.Context, files map[string]io.Reader, dryRun bool) (*domain.LocationImportReport, string, error) {
+	rows := make(map[string][]domain.LocationImportRow)
+
+	for _, level := range cm.LocationLevels {
+		file, ok := files[level]
+		if !ok {
+			continue
+		}
+
+		data, message, err := parseLocationCSV(level, file)
+		if err != nil {
+			return nil, message, err
+		}
+
+		rows[level] = data
+	}
+
+	if len(rows) == 0 {
+		return nil, "no location file to import", errors.New("no file")
+	}
+
+	report := domain.LocationImportReport{
+		DryRun: dryRun,
+	}
+
+	// Code to id of the active data of every level that already processed, used to find the parent.
+	codeIDs := make(map[string]map[string]int64)
+
+	// Id of the data that deactivated by the import on every level, the active children is deactivated too.
+	removedIDs := make(map[string]map[int64]bool)
+
+	tx, err := au.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for i, level := range cm.LocationLevels {
+		var parentLevel string
+		var parentRemoved map[int64]bool
+
+		if i > 0 {
+			parentLevel = cm.LocationLevels[i-1]
+			parentRemoved = removedIDs[parentLevel]
+		}
+
+		data, ok := rows[level]
+		if !ok && len(parentRemoved) == 0 {
+			continue
+		}
+
+		var parentIDs map[string]int64
+		if ok && parentLevel != "" {
+			if _, loaded := codeIDs[parentLevel]; !loaded {
+				parents, err := au.loadImportEntity(parentLevel)
+				if err != nil {
+					tx.Rollback()
+					au.Log.WithField("level", parentLevel).WithError(err).Error("ImportLocation | fail to get location from repo")
+					return nil, "", err
+				}
+
+				codeIDs[parentLevel] = make(map[string]int64)
+				for _, parent := range parents {
+					if parent.code.Valid && parent.isActive {
+						codeIDs[parentLevel][parent.code.String] = parent.id
+					}
+				}
+			}
+
+			parentIDs = codeIDs[parentLevel]
+		}
+
+		var levelReport domain.LocationImportLevelReport
+		var ids map[string]int64
+		var removed map[int64]bool
+		var message string
+
+		if ok {
+			levelReport, ids, removed, message, err = au.importLevel(tx, level, data, parentIDs, parentRemoved)
+		} else {
+			levelReport, ids, removed, err = au.cascadeLevel(tx, level, parentRemoved)
+		}
+
+		if err != nil {
+			tx.Rollback()
+
+			if message == "" {
+				au.Log.WithField("level", level).WithError(err).Error("ImportLocation | fail to import location")
+			}
+
+			return nil, message, err
+		}
+
+		codeIDs[level] = ids
+		removedIDs[level] = removed
+
+		if ok || len(levelReport.Cascaded) > 0 {
+			report.Levels = append(report.Levels, levelReport)
+		}
+	}
+
+	summary := make(map[string]interface{})
+	for _, v := range report.Levels {
+		summary[v.Level] = map[string]int{
+			"total":       v.Total,
+			"added":       len(v.Added),
+			"linked":      len(v.Linked),
+			"renamed":     len(v.Renamed),
+			"moved":       len(v.Moved),
+			"reactivated": len(v.Reactivated),
+			"removed":     len(v.Removed),
+			"cascaded":    len(v.Cascaded),
+		}
+	}
+
+	detail, err := json.Marshal(summary)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", err
+	}
+
+	err = au.AuditLogRepo.InsertAuditLog(tx, da.AuditLog{
+		ActorID:    null.NewInt(da.CurrentUserID(ctx), da.CurrentUserID(ctx) != 0),
+		Action:     ca.AuditActionMasterImport,
+		EntityType: ca.AuditEntityLocation,
+		EntityID:   strings.Join(levelNames(report.Levels), ","),
+		Detail:     string(detail),
+	})
+	if err != nil {
+		tx.Rollback()
+		au.Log.WithError(err).Error("ImportLocation | fail to insert audit log")
+		return nil, "", err
+	}
+
+	if dryRun {
+		tx.Rollback()
+		return &report, "dry run, no change is saved", nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", err
+	}
+
+	au.Cache.Invalidate(ctx)
+
+	return &report, "success import location", nil
+}
+
+// importLevel return the code to id of the active data of the level after the import, including the data that not on the file,
+// & the id of the data that deactivated. parentIDs only contain the active parent, so the row of the inactive parent is refused.
+func (au AdminUsecase) importLevel(tx *sql.Tx, level string, rows []domain.LocationImportRow, parentIDs map[string]int64, parentRemoved map[int64]bool) (domain.LocationImportLevelReport, map[string]int64, map[int64]bool, string, error) {
+	report := domain.LocationImportLevelReport{
+		Level: level,
+		Total: len(rows),
+	}
+
+	existing, err := au.loadImportEntity(level)
+	if err != nil {
+		return report, nil, nil, "", err
+	}
+
+	byCode := make(map[string]*importEntity)
+	byName := make(map[string]*importEntity)
+	ids := make(map[string]int64)
+
+	for i := range existing {
+		e := &existing[i]
+		if e.code.Valid {
+			byCode[e.code.String] = e
+			if e.isActive {
+				ids[e.code.String] = e.id
+			}
+
+			continue
+		}
+
+		byName[importNameKey(e.parentID, e.name)] = e
+	}
+
+	seen := make(map[string]bool)
+
+	for _, row := range rows {
+		seen[row.Code] = true
+
+		var parentID int64
+		if parentIDs != nil {
+			id, ok := parentIDs[row.ParentCode]
+			if !ok {
+				return report, nil, nil, fmt.Sprintf("%s line %d: parent code %s not found or inactive", level, row.Line, row.ParentCode), errors.New("parent not found")
+			}
+
+			parentID = id
+		}
+
+		change := domain.LocationImportChange{
+			Code:       row.Code,
+			Name:       row.Name,
+			ParentCode: row.ParentCode,
+		}
+
+		e, ok := byCode[row.Code]
+		if !ok {
+			// The existing data from before the code is used, linked so the id of the address is kept.
+			e, ok = byName[importNameKey(parentID, row.Name)]
+			if ok {
+				delete(byName, importNameKey(parentID, row.Name))
+				report.Linked = append(report.Linked, change)
+			}
+		}
+
+		if !ok {
+			id, err := au.insertImportEntity(tx, level, importEntity{
+				parentID: parentID,
+				name:     row.Name,
+				code:     null.StringFrom(row.Code),
+				isActive: true,
+			})
+			if err != nil {
+				return report, nil, nil, "", err
+			}
+
+			ids[row.Code] = id
+			report.Added = append(report.Added, change)
+			continue
+		}
+
+		changed := !e.code.Valid
+
+		if e.name != row.Name {
+			change.OldName = e.name
+			report.Renamed = append(report.Renamed, change)
+			changed = true
+		}
+
+		if e.parentID != parentID {
+			report.Moved = append(report.Moved, change)
+			changed = true
+		}
+
+		if !e.isActive {
+			report.Reactivated = append(report.Reactivated, change)
+			changed = true
+		}
+
+		ids[row.Code] = e.id
+
+		if !changed {
+			report.Unchanged++
+			continue
+		}
+
+		e.parentID = parentID
+		e.name = row.Name
+		e.code = null.StringFrom(row.Code)
+		e.isActive = true
+
+		err = au.updateImportEntity(tx, level, *e)
+		if err != nil {
+			return report, nil, nil, "", err
+		}
+	}
+
+	removed := make(map[int64]bool)
+
+	// Deactivated instead of deleted, so the existing address is still resolved.
+	for i := range existing {
+		e := &existing[i]
+		if !e.code.Valid || !e.isActive || seen[e.code.String] {
+			continue
+		}
+
+		err = au.deactivateImportEntity(tx, level, e.id)
+		if err != nil {
+			return report, nil, nil, "", err
+		}
+
+		e.isActive = false
+		removed[e.id] = true
+		delete(ids, e.code.String)
+
+		report.Removed = append(report.Removed, domain.LocationImportChange{
+			Code: e.code.String,
+			Name: e.name,
+		})
+	}
+
+	// The data on the file is already moved to the active parent, only the data that not on the file is left.
+	err = au.cascadeEntity(tx, level, existing, parentRemoved, &report, ids, removed)
+	if err != nil {
+		return report, nil, nil, "", err
+	}
+
+	return report, ids, removed, "", nil
+}
+
+// cascadeLevel deactivate the active data of the level without file that the parent is deactivated.
+func (au AdminUsecase) cascadeLevel(tx *sql.Tx, level string, parentRemoved map[int64]bool) (domain.LocationImportLevelReport, map[string]int64, map[int64]bool, error) {
+	report := domain.LocationImportLevelReport{
+		Level: level,
+	}
+
+	existing, err := au.loadImportEntity(level)
+	if err != nil {
+		return report, nil, nil, err
+	}
+
+	ids := make(map[string]int64)
+	for _, e := range existing {
+		if e.code.Valid && e.isActive {
+			ids[e.code.String] = e.id
+		}
+	}
+
+	removed := make(map[int64]bool)
+
+	err = au.cascadeEntity(tx, level, existing, parentRemoved, &report, ids, removed)
+	if err != nil {
+		return report, nil, nil, err
+	}
+
+	return report, ids, removed, nil
+}
+
+// cascadeEntity deactivate the active entity that the parent is deactivated, it's added to the cascaded report & the removed id.
+func (au AdminUsecase) cascadeEntity(tx *sql.Tx, level string, entities []importEntity, parentRemoved map[int64]bool, report *domain.LocationImportLevelReport, ids map[string]int64, removed map[int64]bool) error {
+	for i := range entities {
+		e := &entities[i]
+		if !e.isActive || !parentRemoved[e.parentID] {
+			continue
+		}
+
+		err := au.deactivateImportEntity(tx, level, e.id)
+		if err != nil {
+			return err
+		}
+
+		e.isActive = false
+		removed[e.id] = true
+
+		if e.code.Valid {
+			delete(ids, e.code.String)
+		}
+
+		report.Cascaded = append(report.Cascaded, domain.LocationImportChange{
+			Code: e.code.String,
+			Name: e.name,
+		})
+	}
+
+	return nil
+}
+
+func (au AdminUsecase) loadImportEntity(level string) ([]importEntity, error) {
+	pagination := gen.GetPagination()
+	pagination.IsGetAll = true
+	pagination.Sorts = []gen.SortData{{Field: "id"}}
+
+	var res []importEntity
+
+	switch level {
+	case cm.LocationLevelCountry:
+		data, err := au.CountryRepo.GetListCountry(pagination, domain.CountryFilter{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range data {
+			res = append(res, importEntity{id: v.ID, name: v.Name, code: v.Code, isActive: v.IsActive})
+		}
+	case cm.LocationLevelProvince:
+		data, err := au.ProvinceRepo.GetListProvince(pagination, domain.ProvinceFilter{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range data {
+			res = append(res, importEntity{id: v.ID, parentID: v.CountryID, name: v.Name, code: v.Code, isActive: v.IsActive})
+		}
+	case cm.LocationLevelCity:
+		data, err := au.CityRepo.GetListCity(pagination, domain.CityFilter{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range data {
+			res = append(res, importEntity{id: v.ID, parentID: v.ProvinceID, name: v.Name, code: v.Code, isActive: v.IsActive})
+		}
+	case cm.LocationLevelDistrict:
+		data, err := au.DistrictRepo.GetListDistrict(pagination, domain.DistrictFilter{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range data {
+			res = append(res, importEntity{id: v.ID, parentID: v.CityID, name: v.Name, code: v.Code, isActive: v.IsActive, metadata: v.Metadata})
+		}
+	case cm.LocationLevelSubDistrict:
+		data, err := au.SubDistrictRepo.GetListSubDistrict(pagination, domain.SubDistrictFilter{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range data {
+			res = append(res, importEntity{id: v.ID, parentID: v.DistrictID, name: v.Name, code: v.Code, isActive: v.IsActive})
+		}
+	}
+
+	return res, nil
+}
+
+func (au AdminUsecase) insertImportEntity(tx *sql.Tx, level string, e importEntity) (int64, error) {
+	switch level {
+	case cm.LocationLevelCountry:
+		return au.CountryRepo.InsertCountry(tx, domain.Country{Name: e.name, Code: e.code, IsActive: e.isActive})
+	case cm.LocationLevelProvince:
+		return au.ProvinceRepo.InsertProvince(tx, domain.Province{CountryID: e.parentID, Name: e.name, Code: e.code, IsActive: e.isActive})
+	case cm.LocationLevelCity:
+		return au.CityRepo.InsertCity(tx, domain.City{ProvinceID: e.parentID, Name: e.name, Code: e.code, IsActive: e.isActive})
+	case cm.LocationLevelDistrict:
+		return au.DistrictRepo.InsertDistrict(tx, domain.District{CityID: e.parentID, Name: e.name, Code: e.code, IsActive: e.isActive, Metadata: e.metadata})
+	case cm.LocationLevelSubDistrict:
+		return au.SubDistrictRepo.InsertSubDistrict(tx, domain.SubDistrict{DistrictID: e.parentID, Name: e.name, Code: e.code, IsActive: e.isActive})
+	}
+
+	return 0, errors.New("level not valid")
+}
+
+func (au AdminUsecase) updateImportEntity(tx *sql.Tx, level string, e importEntity) error {
+	switch level {
+	case cm.LocationLevelCountry:
+		return au.CountryRepo.UpdateCountry(tx, domain.Country{ID: e.id, Name: e.name, Code: e.code, IsActive: e.isActive})
+	case cm.LocationLevelProvince:
+		return au.ProvinceRepo.UpdateProvince(tx, domain.Province{ID: e.id, CountryID: e.parentID, Name: e.name, Code: e.code, IsActive: e.isActive})
+	case cm.LocationLevelCity:
+		return au.CityRepo.UpdateCity(tx, domain.City{ID: e.id, ProvinceID: e.parentID, Name: e.name, Code: e.code, IsActive: e.isActive})
+	case cm.LocationLevelDistrict:
+		return au.DistrictRepo.UpdateDistrict(tx, domain.District{ID: e.id, CityID: e.parentID, Name: e.name, Code: e.code, IsActive: e.isActive, Metadata: e.metadata})
+	case cm.LocationLevelSubDistrict:
+		return au.SubDistrictRepo.UpdateSubDistrict(tx, domain.SubDistrict{ID: e.id, DistrictID: e.parentID, Name: e.name, Code: e.code, IsActive: e.isActive})
+	}
+
+	return errors.New("level not valid")
+}
+
+func (au AdminUsecase) deactivateImportEntity(tx *sql.Tx, level string, id int64) error {
+	switch level {
+	case cm.LocationLevelCountry:
+		return au.CountryRepo.DeactivateCountry(tx, id)
+	case cm.LocationLevelProvince:
+		return au.ProvinceRepo.DeactivateProvince(tx, id)
+	case cm.LocationLevelCity:
+		return au.CityRepo.DeactivateCity(tx, id)
+	case cm.LocationLevelDistrict:
+		return au.DistrictRepo.DeactivateDistrict(tx, id)
+	case cm.LocationLevelSubDistrict:
+		return au.SubDistrictRepo.DeactivateSubDistrict(tx, id)
+	}
+
+	return errors.New("level not valid")
+}
+
+// parseLocationCSV read the CSV with code, name & optional parent_code header.
+// When the parent code is empty, it's taken from the dotted code, e.g. 11.01 is the parent of 11.01.01.
+func parseLocationCSV(level string, file io.Reader) ([]domain.LocationImportRow, string, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Sprintf("%s: fail to read the header", level), err
+	}
+
+	index := map[string]int{
+		"code":        -1,
+		"name":        -1,
+		"parent_code": -1,
+	}
+
+	for i, v := range header {
+		// Remove the byte order mark that added by the spreadsheet.
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(v, "\ufeff")))
+		if _, ok := index[key]; ok {
+			index[key] = i
+		}
+	}
+
+	if index["code"] < 0 || index["name"] < 0 {
+		return nil, fmt.Sprintf("%s: code and name column are required", level), errors.New("header not valid")
+	}
+
+	var rows []domain.LocationImportRow
+	codes := make(map[string]bool)
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Sprintf("%s line %d: %s", level, line, err.Error()), err
+		}
+
+		row := domain.LocationImportRow{
+			Line: line,
+			Code: csvField(record, index["code"]),
+			Name: csvField(record, index["name"]),
+		}
+
+		if row.Code == "" || row.Name == "" {
+			return nil, fmt.Sprintf("%s line %d: code and name are required", level, line), errors.New("row not valid")
+		}
+
+		if codes[row.Code] {
+			return nil, fmt.Sprintf("%s line %d: duplicate code %s", level, line, row.Code), errors.New("duplicate code")
+		}
+
+		codes[row.Code] = true
+
+		if level != cm.LocationLevelCountry {
+			row.ParentCode = csvField(record, index["parent_code"])
+
+			if row.ParentCode == "" {
+				dot := strings.LastIndex(row.Code, ".")
+				if dot <= 0 {
+					return nil, fmt.Sprintf("%s line %d: parent code is required", level, line), errors.New("parent code empty")
+				}
+
+				row.ParentCode = row.Code[:dot]
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, "", nil
+}
+
+func csvField(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[i])
+}
+
+func importNameKey(parentID int64, name string) string {
+	return fmt.Sprintf("%d|%s", parentID, strings.ToLower(strings.TrimSpace(name)))
+}
+
+func levelNames(levels []domain.LocationImportLevelReport) []string {
+	names := make([]string, 0, len(levels))
+	for _, v := range levels {
+		names = append(names, v.Level)
+	}
+
+	return names
+}
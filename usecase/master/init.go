@@ -13,14 +13,25 @@ type MasterUsecase struct {
 	City        CityUsecaseItf
 	District    DistrictUsecaseItf
 	SubDistrict SubDistrictUsecaseItf
+	Location    LocationUsecaseItf
+	Cache       CacheUsecaseItf
+	Admin       AdminUsecaseItf
 }
 
-func NewUsecase(repo repo.Repo, conf *general.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger) MasterUsecase {
-	return MasterUsecase{
-		Country:     newCountryUsecase(repo, logger, dbList),
-		Province:    newProvinceUsecase(repo, logger, dbList),
-		City:        newCityUsecase(repo, logger, dbList),
-		District:    newDistrictUsecase(repo, logger, dbList),
-		SubDistrict: newSubDistrictUsecase(repo, logger, dbList),
+func NewUsecase(repo repo.Repo, conf *general.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger) (MasterUsecase, error) {
+	cache, err := newCacheUsecase(conf, logger, dbList)
+	if err != nil {
+		return MasterUsecase{}, err
 	}
+
+	return MasterUsecase{
+		Country:     cachedCountryUsecase{newCountryUsecase(repo, logger, dbList), cache},
+		Province:    cachedProvinceUsecase{newProvinceUsecase(repo, logger, dbList), cache},
+		City:        cachedCityUsecase{newCityUsecase(repo, logger, dbList), cache},
+		District:    cachedDistrictUsecase{newDistrictUsecase(repo, logger, dbList), cache},
+		SubDistrict: cachedSubDistrictUsecase{newSubDistrictUsecase(repo, logger, dbList), cache},
+		Location:    newLocationUsecase(repo, logger, dbList),
+		Cache:       cache,
+		Admin:       newAdminUsecase(repo, logger, dbList, cache),
+	}, nil
 }
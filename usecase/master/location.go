@@ -0,0 +1,275 @@
+package master
+
+import (
+	"database/sql"
+	"errors"
+
+	cm "github.com/furee/backend/constants/master"
+	gen "github.com/furee/backend/domain/general"
+	domain "github.com/furee/backend/domain/master"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	"github.com/furee/backend/repo/master"
+	"github.com/furee/backend/utils"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+)
+
+type LocationUsecaseItf interface {
+	GetPath(subDistrictID int64) ([]domain.LocationNode, string, error)
+	GetTree(filter domain.LocationTreeFilter) ([]domain.LocationNode, string, error)
+}
+
+type LocationUsecase struct {
+	Repo            master.LocationRepoItf
+	CountryRepo     master.CountryRepoItf
+	ProvinceRepo    master.ProvinceRepoItf
+	CityRepo        master.CityRepoItf
+	DistrictRepo    master.DistrictRepoItf
+	SubDistrictRepo master.SubDistrictRepoItf
+	DBList          *infra.DatabaseList
+	Log             *logrus.Logger
+}
+
+func newLocationUsecase(r repo.Repo, logger *logrus.Logger, dbList *infra.DatabaseList) LocationUsecase {
+	return LocationUsecase{
+		Repo:            r.Master.Location,
+		CountryRepo:     r.Master.Country,
+		ProvinceRepo:    r.Master.Province,
+		CityRepo:        r.Master.City,
+		DistrictRepo:    r.Master.District,
+		SubDistrictRepo: r.Master.SubDistrict,
+		Log:             logger,
+		DBList:          dbList,
+	}
+}
+
+// locationRow is a node with the id of the parent, used while the tree is built.
+type locationRow struct {
+	parentID int64
+	node     domain.LocationNode
+}
+
+// GetPath return the ancestor of the sub district, from the country to the sub district.
+func (lu LocationUsecase) GetPath(subDistrictID int64) ([]domain.LocationNode, string, error) {
+	path, err := lu.Repo.GetPathBySubDistrictID(subDistrictID)
+	if err != nil {
+		lu.Log.WithField("sub district id", subDistrictID).WithError(err).Error("GetPath | fail to get location path from repo")
+		return nil, "", err
+	}
+
+	if path == nil {
+		return nil, "location not found", errors.New("location not found")
+	}
+
+	return []domain.LocationNode{
+		{ID: path.CountryID, Name: path.CountryName, Level: cm.LocationLevelCountry},
+		{ID: path.ProvinceID, Name: path.ProvinceName, Level: cm.LocationLevelProvince},
+		{ID: path.CityID, Name: path.CityName, Level: cm.LocationLevelCity},
+		{ID: path.DistrictID, Name: path.DistrictName, Level: cm.LocationLevelDistrict},
+		{ID: path.SubDistrictID, Name: path.SubDistrictName, Level: cm.LocationLevelSubDistrict},
+	}, "", nil
+}
+
+// GetTree return the nested location rooted at the filter level & id, or all the country when the id is not set.
+// The depth is the number of level below the root, limited to the bottom of the hierarchy.
+func (lu LocationUsecase) GetTree(filter domain.LocationTreeFilter) ([]domain.LocationNode, string, error) {
+	if filter.Level == "" {
+		filter.Level = cm.LocationLevelCountry
+	}
+
+	rootIndex := locationLevelIndex(filter.Level)
+	if rootIndex < 0 {
+		return nil, "level not valid", errors.New("level not valid")
+	}
+
+	if !filter.ID.Valid && filter.Level != cm.LocationLevelCountry {
+		return nil, "id is required for the level", errors.New("id is required")
+	}
+
+	if filter.Depth < 0 {
+		return nil, "depth not valid", errors.New("depth not valid")
+	}
+
+	if rootIndex+filter.Depth > len(cm.LocationLevels)-1 {
+		filter.Depth = len(cm.LocationLevels) - 1 - rootIndex
+	}
+
+	roots, err := lu.getRoot(filter.Level, filter.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "location not found", err
+		}
+
+		lu.Log.WithField("filter", utils.StructToString(filter)).WithError(err).Error("GetTree | fail to get root location from repo")
+		return nil, "", err
+	}
+
+	// Select each level below the root at once, then attach the children from the bottom.
+	levels := [][]locationRow{roots}
+	for i := 1; i <= filter.Depth; i++ {
+		parent := levels[i-1]
+
+		parentIDs := make([]int64, 0, len(parent))
+		for _, row := range parent {
+			parentIDs = append(parentIDs, row.node.ID)
+		}
+
+		rows, err := lu.getChildren(cm.LocationLevels[rootIndex+i], parentIDs)
+		if err != nil {
+			lu.Log.WithField("filter", utils.StructToString(filter)).WithError(err).Error("GetTree | fail to get location from repo")
+			return nil, "", err
+		}
+
+		levels = append(levels, rows)
+	}
+
+	for i := len(levels) - 1; i > 0; i-- {
+		children := make(map[int64][]domain.LocationNode)
+		for _, row := range levels[i] {
+			children[row.parentID] = append(children[row.parentID], row.node)
+		}
+
+		for j := range levels[i-1] {
+			levels[i-1][j].node.Children = children[levels[i-1][j].node.ID]
+		}
+	}
+
+	res := make([]domain.LocationNode, 0, len(roots))
+	for _, row := range levels[0] {
+		res = append(res, row.node)
+	}
+
+	return res, "", nil
+}
+
+// getRoot return the active root of the tree, sql.ErrNoRows when the root is not found or inactive.
+func (lu LocationUsecase) getRoot(level string, id null.Int) ([]locationRow, error) {
+	if !id.Valid {
+		pagination := gen.GetPagination()
+		pagination.IsGetAll = true
+		pagination.Sorts = []gen.SortData{{Field: "name"}}
+
+		countries, err := lu.CountryRepo.GetListCountry(pagination, domain.CountryFilter{IsActive: null.BoolFrom(true)})
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([]locationRow, 0, len(countries))
+		for _, country := range countries {
+			rows = append(rows, locationRow{node: domain.LocationNode{ID: country.ID, Name: country.Name, Level: level}})
+		}
+
+		return rows, nil
+	}
+
+	var node domain.LocationNode
+	var isActive bool
+
+	switch level {
+	case cm.LocationLevelCountry:
+		data, err := lu.CountryRepo.GetByID(id.Int64)
+		if err != nil {
+			return nil, err
+		}
+
+		node = domain.LocationNode{ID: data.ID, Name: data.Name}
+		isActive = data.IsActive
+	case cm.LocationLevelProvince:
+		data, err := lu.ProvinceRepo.GetByID(id.Int64)
+		if err != nil {
+			return nil, err
+		}
+
+		node = domain.LocationNode{ID: data.ID, Name: data.Name}
+		isActive = data.IsActive
+	case cm.LocationLevelCity:
+		data, err := lu.CityRepo.GetByID(id.Int64)
+		if err != nil {
+			return nil, err
+		}
+
+		node = domain.LocationNode{ID: data.ID, Name: data.Name}
+		isActive = data.IsActive
+	case cm.LocationLevelDistrict:
+		data, err := lu.DistrictRepo.GetByID(id.Int64)
+		if err != nil {
+			return nil, err
+		}
+
+		node = domain.LocationNode{ID: data.ID, Name: data.Name}
+		isActive = data.IsActive
+	case cm.LocationLevelSubDistrict:
+		data, err := lu.SubDistrictRepo.GetByID(id.Int64)
+		if err != nil {
+			return nil, err
+		}
+
+		node = domain.LocationNode{ID: data.ID, Name: data.Name}
+		isActive = data.IsActive
+	}
+
+	// The inactive location is hidden like the children below it.
+	if !isActive {
+		return nil, sql.ErrNoRows
+	}
+
+	node.Level = level
+
+	return []locationRow{{node: node}}, nil
+}
+
+func (lu LocationUsecase) getChildren(level string, parentIDs []int64) ([]locationRow, error) {
+	var rows []locationRow
+
+	switch level {
+	case cm.LocationLevelProvince:
+		data, err := lu.ProvinceRepo.GetByCountryIDs(parentIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range data {
+			rows = append(rows, locationRow{parentID: v.CountryID, node: domain.LocationNode{ID: v.ID, Name: v.Name, Level: level}})
+		}
+	case cm.LocationLevelCity:
+		data, err := lu.CityRepo.GetByProvinceIDs(parentIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range data {
+			rows = append(rows, locationRow{parentID: v.ProvinceID, node: domain.LocationNode{ID: v.ID, Name: v.Name, Level: level}})
+		}
+	case cm.LocationLevelDistrict:
+		data, err := lu.DistrictRepo.GetByCityIDs(parentIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range data {
+			rows = append(rows, locationRow{parentID: v.CityID, node: domain.LocationNode{ID: v.ID, Name: v.Name, Level: level}})
+		}
+	case cm.LocationLevelSubDistrict:
+		data, err := lu.SubDistrictRepo.GetByDistrictIDs(parentIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range data {
+			rows = append(rows, locationRow{parentID: v.DistrictID, node: domain.LocationNode{ID: v.ID, Name: v.Name, Level: level}})
+		}
+	}
+
+	return rows, nil
+}
+
+func locationLevelIndex(level string) int {
+	for i, v := range cm.LocationLevels {
+		if v == level {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -1,23 +1,27 @@
 package order
 
 import (
+	"context"
 	"errors"
 	"time"
 
+	da "github.com/furee/backend/domain/authorization"
 	"github.com/furee/backend/domain/general"
 	du "github.com/furee/backend/domain/order"
 	"github.com/furee/backend/infra"
 	"github.com/furee/backend/repo"
 	ru "github.com/furee/backend/repo/order"
+	"github.com/furee/backend/utils"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
 )
 
 type OrderDataUsecaseItf interface {
-	GetList() ([]du.Order, error)
-	GetByID(orderID int64) (*du.Order, error)
-	DeleteByID(orderID int64) (bool, error)
-	CreateOrder(data du.OrderRequest) (int64, error)
-	UpdateOrder(data du.OrderRequest) (bool, error)
+	GetList(ctx context.Context, pagination general.PaginationData, filter du.OrderFilter) ([]du.Order, general.PaginationData, error)
+	GetByID(ctx context.Context, orderID int64) (*du.Order, error)
+	DeleteByID(ctx context.Context, orderID int64) (bool, error)
+	CreateOrder(ctx context.Context, data du.OrderRequest) (int64, error)
+	UpdateOrder(ctx context.Context, data du.OrderRequest) (bool, error)
 }
 
 type OrderDataUsecase struct {
@@ -38,29 +42,48 @@ func newOrderDataUsecase(r repo.Repo, conf *general.SectionService, logger *logr
 	}
 }
 
-func (uu OrderDataUsecase) GetList() ([]du.Order, error) {
-	orders, err := uu.Repo.GetList()
+func (uu OrderDataUsecase) GetList(ctx context.Context, pagination general.PaginationData, filter du.OrderFilter) ([]du.Order, general.PaginationData, error) {
+	orders, err := uu.Repo.GetList(pagination, filter)
 	if err != nil {
-		// uu.Log.WithField("request", utils.StructToString(data)).WithError(err).Errorf("fail to checking is exist order")
-		return nil, err
+		uu.Log.WithField("filter", utils.StructToString(filter)).WithError(err).Error("GetList | fail to get order list from repo")
+		return nil, pagination, err
 	}
 
-	retOrders := []du.Order{}
+	count, page, err := uu.Repo.GetTotalData(pagination, filter)
+	if err != nil {
+		uu.Log.WithField("filter", utils.StructToString(filter)).WithError(err).Error("GetList | fail to get total order from repo")
+		return nil, pagination, err
+	}
+
+	pagination.TotalData = int(count)
+	pagination.TotalPage = int(page)
+
+	orderIDs := make([]int64, 0, len(orders))
 	for _, order := range orders {
-		items, err := uu.RepoItem.GetListByOrderID(order.OrderID)
+		orderIDs = append(orderIDs, order.OrderID)
+	}
 
-		if err != nil {
-			return orders, err
-		}
+	items, err := uu.RepoItem.GetListByOrderIDs(orderIDs)
+	if err != nil {
+		uu.Log.WithField("filter", utils.StructToString(filter)).WithError(err).Error("GetList | fail to get order items from repo")
+		return orders, pagination, err
+	}
 
-		order.Items = items
+	orderItems := make(map[int64][]du.Item)
+	for _, item := range items {
+		orderItems[item.OrderID] = append(orderItems[item.OrderID], item)
+	}
+
+	retOrders := []du.Order{}
+	for _, order := range orders {
+		order.Items = orderItems[order.OrderID]
 		retOrders = append(retOrders, order)
 	}
 
-	return retOrders, nil
+	return retOrders, pagination, nil
 }
 
-func (uu OrderDataUsecase) GetByID(orderID int64) (*du.Order, error) {
+func (uu OrderDataUsecase) GetByID(ctx context.Context, orderID int64) (*du.Order, error) {
 	order, err := uu.Repo.GetByID(orderID)
 	if err != nil {
 		// uu.Log.WithField("request", utils.StructToString(data)).WithError(err).Errorf("fail to checking is exist order")
@@ -84,7 +107,7 @@ func (uu OrderDataUsecase) GetByID(orderID int64) (*du.Order, error) {
 	return order, nil
 }
 
-func (uu OrderDataUsecase) DeleteByID(orderID int64) (bool, error) {
+func (uu OrderDataUsecase) DeleteByID(ctx context.Context, orderID int64) (bool, error) {
 	tx, err := uu.DBList.Backend.Write.Begin()
 	if err != nil {
 		return false, err
@@ -106,7 +129,7 @@ func (uu OrderDataUsecase) DeleteByID(orderID int64) (bool, error) {
 	return true, nil
 }
 
-func (uu OrderDataUsecase) UpdateOrder(data du.OrderRequest) (bool, error) {
+func (uu OrderDataUsecase) UpdateOrder(ctx context.Context, data du.OrderRequest) (bool, error) {
 	tx, err := uu.DBList.Backend.Write.Begin()
 	if err != nil {
 		return false, err
@@ -155,7 +178,7 @@ func (uu OrderDataUsecase) UpdateOrder(data du.OrderRequest) (bool, error) {
 	return true, nil
 }
 
-func (uu OrderDataUsecase) CreateOrder(data du.OrderRequest) (int64, error) {
+func (uu OrderDataUsecase) CreateOrder(ctx context.Context, data du.OrderRequest) (int64, error) {
 	tx, err := uu.DBList.Backend.Write.Begin()
 	if err != nil {
 		return 0, err
@@ -166,7 +189,8 @@ func (uu OrderDataUsecase) CreateOrder(data du.OrderRequest) (int64, error) {
 		return 0, err
 	}
 
-	order := du.Order{CustomerName: data.CustomerName, OrderedAt: orderedAt}
+	userID := da.CurrentUserID(ctx)
+	order := du.Order{UserID: null.NewInt(userID, userID != 0), CustomerName: data.CustomerName, OrderedAt: orderedAt}
 
 	orderID, err := uu.Repo.InsertOrder(tx, order)
 	if err != nil {
@@ -0,0 +1,190 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	ca "github.com/furee/backend/constants/authorization"
+	cu "github.com/furee/backend/constants/user"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/utils"
+)
+
+// GetListUser return the paginated user list for the admin.
+func (uu UserDataUsecase) GetListUser(ctx context.Context, pagination general.PaginationData, filter du.UserFilter) ([]du.UserAdminResponse, general.PaginationData, string, error) {
+	users, err := uu.Repo.GetListUser(pagination, filter)
+	if err != nil {
+		uu.Log.WithField("filter", utils.StructToString(filter)).WithError(err).Error("GetListUser | fail to get user list from repo")
+		return nil, pagination, "", err
+	}
+
+	count, page, err := uu.Repo.GetTotalDataUser(pagination, filter)
+	if err != nil {
+		uu.Log.WithField("filter", utils.StructToString(filter)).WithError(err).Error("GetListUser | fail to get total user from repo")
+		return nil, pagination, "", err
+	}
+
+	pagination.TotalData = int(count)
+	pagination.TotalPage = int(page)
+
+	res := make([]du.UserAdminResponse, 0, len(users))
+	for i := range users {
+		user, err := uu.toUserAdmin(&users[i])
+		if err != nil {
+			uu.Log.WithField("user id", users[i].ID).WithError(err).Error("GetListUser | fail to decrypt user phone")
+			return nil, pagination, "", err
+		}
+
+		res = append(res, *user)
+	}
+
+	return res, pagination, "", nil
+}
+
+func (uu UserDataUsecase) GetUserDetail(ctx context.Context, userID int64) (*du.UserAdminResponse, string, error) {
+	user, err := uu.Repo.GetByID(userID)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("GetUserDetail | fail to get user data from repo")
+		return nil, "", err
+	}
+
+	if user == nil {
+		return nil, "user not found", errors.New("user not found")
+	}
+
+	res, err := uu.toUserAdmin(user)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("GetUserDetail | fail to decrypt user phone")
+		return nil, "", err
+	}
+
+	return res, "", nil
+}
+
+// UpdateUserStatus block or unblock the user, every session of blocked user is revoked.
+// Only the admin user can change the status, so the actor is always recorded on the audit log.
+// The deleted user is anonymized, so the status can't be changed anymore.
+func (uu UserDataUsecase) UpdateUserStatus(ctx context.Context, userID int64, data du.UpdateStatusRequest) (*du.UserAdminResponse, string, error) {
+	actorID := da.CurrentUserID(ctx)
+	if actorID == 0 {
+		return nil, "admin user is required", errors.New("actor not found")
+	}
+
+	if data.Status != cu.StatusActive && data.Status != cu.StatusBlocked {
+		return nil, "status not valid", errors.New("status not valid")
+	}
+
+	if userID == actorID {
+		return nil, "cannot change your own status", errors.New("change own status")
+	}
+
+	user, err := uu.Repo.GetByID(userID)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("UpdateUserStatus | fail to get user data from repo")
+		return nil, "", err
+	}
+
+	if user == nil {
+		return nil, "user not found", errors.New("user not found")
+	}
+
+	if user.Status == cu.StatusDeleted {
+		return nil, "deleted user status cannot be changed", errors.New("user is deleted")
+	}
+
+	tx, err := uu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = uu.Repo.UpdateStatus(tx, du.UpdateStatus{
+		UserID:    userID,
+		Status:    data.Status,
+		Reason:    data.Reason,
+		UpdatedBy: actorID,
+	})
+	if err != nil {
+		tx.Rollback()
+		uu.Log.WithField("user id", userID).WithError(err).Error("UpdateUserStatus | fail to update user status")
+		return nil, "", err
+	}
+
+	err = uu.insertAuditLog(tx, actorID, ca.AuditActionStatusUpdate, userID, map[string]interface{}{
+		"old_status": user.Status,
+		"status":     data.Status,
+		"reason":     data.Reason,
+	})
+	if err != nil {
+		tx.Rollback()
+		uu.Log.WithField("user id", userID).WithError(err).Error("UpdateUserStatus | fail to insert audit log")
+		return nil, "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if data.Status == cu.StatusBlocked {
+		err = uu.Token.RevokeUserSessions(userID)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	uu.Log.WithField("user id", userID).WithField("admin id", actorID).WithField("status", data.Status).Info("UpdateUserStatus | user status updated")
+
+	return uu.GetUserDetail(ctx, userID)
+}
+
+// ForceLogout revoke every session of the user, the admin is recorded on the audit log.
+func (uu UserDataUsecase) ForceLogout(ctx context.Context, userID int64) (string, error) {
+	user, err := uu.Repo.GetByID(userID)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("ForceLogout | fail to get user data from repo")
+		return "", err
+	}
+
+	if user == nil {
+		return "user not found", errors.New("user not found")
+	}
+
+	actorID := da.CurrentUserID(ctx)
+
+	err = uu.Token.RevokeUserSessions(userID)
+	if err != nil {
+		return "", err
+	}
+
+	err = uu.insertAuditLog(nil, actorID, ca.AuditActionForceLogout, userID, map[string]interface{}{"status": user.Status})
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("ForceLogout | fail to insert audit log")
+		return "", err
+	}
+
+	uu.Log.WithField("user id", userID).WithField("admin id", actorID).Info("ForceLogout | user session revoked")
+
+	return "success force logout user", nil
+}
+
+func (uu UserDataUsecase) toUserAdmin(user *du.User) (*du.UserAdminResponse, error) {
+	decryptedPhone, err := uu.phoneCipher.decrypt(user.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &du.UserAdminResponse{
+		ID:             user.ID,
+		Name:           user.Name,
+		Email:          user.Email,
+		Phone:          decryptedPhone,
+		Status:         user.Status,
+		StatusReason:   user.StatusReason,
+		OTPLockedUntil: user.OTPLockedUntil,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+		UpdatedBy:      user.UpdatedBy,
+	}, nil
+}
@@ -4,15 +4,21 @@ import (
 	"github.com/furee/backend/domain/general"
 	"github.com/furee/backend/infra"
 	"github.com/furee/backend/repo"
+	ua "github.com/furee/backend/usecase/authorization"
 	"github.com/sirupsen/logrus"
 )
 
 type UserUsecase struct {
-	User UserDataUsecaseItf
+	User      UserDataUsecaseItf
+	Whitelist OTPWhitelistUsecaseItf
 }
 
-func NewUsecase(repo repo.Repo, conf *general.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger) UserUsecase {
+// NewUsecase use the shared authorization usecase, so the token & auth event is not built twice.
+func NewUsecase(repo repo.Repo, conf *general.SectionService, dbList *infra.DatabaseList, logger *logrus.Logger, auth ua.AuthorizationUsecase) UserUsecase {
+	whitelist := newWhitelistCache(repo.User.Whitelist, conf.Whitelist)
+
 	return UserUsecase{
-		User: newUserDataUsecase(repo, conf, logger, dbList),
+		User:      newUserDataUsecase(repo, conf, logger, dbList, whitelist, auth),
+		Whitelist: newOTPWhitelistUsecase(repo, conf, logger, dbList, whitelist),
 	}
 }
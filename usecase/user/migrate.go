@@ -0,0 +1,170 @@
+package user
+
+import (
+	cg "github.com/furee/backend/constants/general"
+	cu "github.com/furee/backend/constants/user"
+	"github.com/furee/backend/domain/general"
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/utils/phone"
+)
+
+// NormalizePhones re-normalize the phone of every user to E.164 & re-hash the phone filter.
+// User with invalid phone or phone that normalized to the phone of other user is skipped & reported.
+func (uu UserDataUsecase) NormalizePhones(dryRun bool) (du.PhoneMigrationReport, error) {
+	var report du.PhoneMigrationReport
+
+	pagination := general.GetPagination()
+	pagination.IsGetAll = true
+	pagination.Sorts = []general.SortData{{Field: "user_id"}}
+
+	users, err := uu.Repo.GetListUser(pagination, du.UserFilter{})
+	if err != nil {
+		uu.Log.WithError(err).Error("NormalizePhones | fail to get user list from repo")
+		return report, err
+	}
+
+	report.Total = len(users)
+
+	// Phone filter that already used, to detect user that share the same number after normalized.
+	owner := make(map[string]int64, len(users))
+	for _, user := range users {
+		owner[user.PhoneFilter] = user.ID
+	}
+
+	for _, user := range users {
+		// Phone of the deleted user is only a placeholder.
+		if user.Status == cu.StatusDeleted {
+			report.Unchanged++
+			continue
+		}
+
+		decryptedPhone, err := uu.phoneCipher.decrypt(user.Phone)
+		if err != nil {
+			uu.Log.WithField("user id", user.ID).WithError(err).Error("NormalizePhones | fail to decrypt user phone")
+			report.Failed = append(report.Failed, user.ID)
+			continue
+		}
+
+		number, err := phone.Parse(decryptedPhone, phone.RegionID)
+		if err != nil {
+			uu.Log.WithField("user id", user.ID).WithError(err).Warn("NormalizePhones | phone is not valid")
+			report.Invalid = append(report.Invalid, user.ID)
+			continue
+		}
+
+		phoneFilter, err := uu.phoneCipher.filter(number.E164)
+		if err != nil {
+			return report, err
+		}
+
+		if number.E164 == decryptedPhone && phoneFilter == user.PhoneFilter && uu.phoneCipher.isActive(user.Phone) {
+			report.Unchanged++
+			continue
+		}
+
+		if ownerID, ok := owner[phoneFilter]; ok && ownerID != user.ID {
+			uu.Log.WithField("user id", user.ID).WithField("owner id", ownerID).Warn("NormalizePhones | phone already used by other user")
+			report.Conflict = append(report.Conflict, user.ID)
+			continue
+		}
+
+		if !dryRun {
+			err = uu.updateEncryptedPhone(user.ID, number.E164, phoneFilter)
+			if err != nil {
+				uu.Log.WithField("user id", user.ID).WithError(err).Error("NormalizePhones | fail to update user phone")
+				report.Failed = append(report.Failed, user.ID)
+				continue
+			}
+		}
+
+		delete(owner, user.PhoneFilter)
+		owner[phoneFilter] = user.ID
+		report.Updated++
+	}
+
+	return report, nil
+}
+
+// ReencryptPhones encrypt the phone of every user with the active key & recalculate the
+// phone filter with the current pepper. User is processed per batch, the old key can be
+// removed from config after the report doesn't contain any failed user.
+func (uu UserDataUsecase) ReencryptPhones(batchSize int, dryRun bool, afterBatch func(page int)) (du.PhoneMigrationReport, error) {
+	var report du.PhoneMigrationReport
+
+	pagination := general.GetPagination()
+	pagination.Limit = batchSize
+	pagination.Sorts = []general.SortData{{Field: "user_id"}}
+
+	for {
+		pagination.SetOffset()
+
+		users, err := uu.Repo.GetListUser(pagination, du.UserFilter{})
+		if err != nil {
+			uu.Log.WithField("page", pagination.Page).WithError(err).Error("ReencryptPhones | fail to get user list from repo")
+			return report, err
+		}
+
+		for _, user := range users {
+			report.Total++
+
+			if user.Status == cu.StatusDeleted {
+				report.Unchanged++
+				continue
+			}
+
+			decryptedPhone, err := uu.phoneCipher.decrypt(user.Phone)
+			if err != nil {
+				uu.Log.WithField("user id", user.ID).WithError(err).Error("ReencryptPhones | fail to decrypt user phone")
+				report.Failed = append(report.Failed, user.ID)
+				continue
+			}
+
+			phoneFilter, err := uu.phoneCipher.filter(decryptedPhone)
+			if err != nil {
+				return report, err
+			}
+
+			if phoneFilter == user.PhoneFilter && uu.phoneCipher.isActive(user.Phone) {
+				report.Unchanged++
+				continue
+			}
+
+			if !dryRun {
+				err = uu.updateEncryptedPhone(user.ID, decryptedPhone, phoneFilter)
+				if err != nil {
+					uu.Log.WithField("user id", user.ID).WithError(err).Error("ReencryptPhones | fail to update user phone")
+					report.Failed = append(report.Failed, user.ID)
+					continue
+				}
+			}
+
+			report.Updated++
+		}
+
+		if len(users) < pagination.Limit {
+			break
+		}
+
+		if afterBatch != nil {
+			afterBatch(pagination.Page)
+		}
+
+		pagination.Page++
+	}
+
+	return report, nil
+}
+
+func (uu UserDataUsecase) updateEncryptedPhone(userID int64, e164, phoneFilter string) error {
+	encryptedPhone, err := uu.phoneCipher.encrypt(e164)
+	if err != nil {
+		return err
+	}
+
+	return uu.Repo.UpdatePhone(nil, du.UpdatePhone{
+		UserID:      userID,
+		Phone:       encryptedPhone,
+		PhoneFilter: phoneFilter,
+		UpdatedBy:   int64(cg.UpdatedBySystem),
+	})
+}
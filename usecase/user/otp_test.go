@@ -0,0 +1,141 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	ca "github.com/furee/backend/constants/authorization"
+	"github.com/furee/backend/domain/general"
+	du "github.com/furee/backend/domain/user"
+	ru "github.com/furee/backend/repo/user"
+	ua "github.com/furee/backend/usecase/authorization"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+)
+
+// stubOTPRepo keep the OTP attempt of one user like the users table.
+type stubOTPRepo struct {
+	ru.UserDataRepoItf
+	user *du.User
+}
+
+func (sr stubOTPRepo) IncrementOTPAttempt(tx *sql.Tx, userID int64) (du.OTPAttempt, error) {
+	sr.user.OTPAttempt++
+
+	return du.OTPAttempt{UserID: userID, Attempt: sr.user.OTPAttempt, LockCount: sr.user.OTPLockCount}, nil
+}
+
+func (sr stubOTPRepo) LockOTP(tx *sql.Tx, data du.OTPAttempt, currentLockCount int) (bool, error) {
+	if sr.user.OTPLockCount != currentLockCount {
+		return false, nil
+	}
+
+	sr.user.OTPAttempt = data.Attempt
+	sr.user.OTPLockCount = data.LockCount
+	sr.user.OTPLockedUntil = data.LockedUntil
+
+	return true, nil
+}
+
+type stubOTPAuthEvent struct {
+	ua.AuthEventUsecaseItf
+	events *[]string
+}
+
+func (se stubOTPAuthEvent) Record(ctx context.Context, eventType string, userID int64, phoneHash string, detail map[string]interface{}) {
+	*se.events = append(*se.events, eventType)
+}
+
+func TestValidateOTPLockout(t *testing.T) {
+	const code = "123456"
+
+	start := time.Now().UTC()
+	user := &du.User{
+		ID:           1,
+		OTP:          null.StringFrom(code),
+		OTPCreatedAt: &start,
+	}
+	events := &[]string{}
+
+	conf := &general.SectionService{}
+	conf.Authorization.OTP = general.OTPCredential{Duration: 5, MaxAttempt: 3, LockDuration: 15}
+
+	uu := UserDataUsecase{
+		Repo:      stubOTPRepo{user: user},
+		AuthEvent: stubOTPAuthEvent{events: events},
+		Conf:      conf,
+		Log:       logrus.New(),
+	}
+
+	tests := []struct {
+		name        string
+		at          time.Duration
+		reissue     bool // new OTP is sent at the time
+		code        string
+		wantMessage string // prefix of the message, empty when the OTP is valid
+		wantLocked  int    // lock count after the attempt
+	}{
+		{name: "first wrong", code: "000000", wantMessage: "Kode verifikasi salah"},
+		{name: "second wrong", code: "000000", wantMessage: "Kode verifikasi salah"},
+		{name: "third wrong lock", code: "000000", wantMessage: "Terlalu banyak percobaan kode verifikasi. Silahkan coba lagi dalam 15 menit", wantLocked: 1},
+		{name: "correct while locked", at: time.Minute, code: code, wantMessage: "Terlalu banyak percobaan kode verifikasi. Silahkan coba lagi dalam 14 menit", wantLocked: 1},
+		{name: "expired after lock", at: 15 * time.Minute, code: code, wantMessage: "Kode verifikasi sudah kedaluwarsa", wantLocked: 1},
+		{name: "correct after lock", at: 16 * time.Minute, reissue: true, code: code, wantLocked: 1},
+		{name: "wrong after lock", at: 16 * time.Minute, code: "000000", wantMessage: "Kode verifikasi salah", wantLocked: 1},
+		{name: "wrong after lock again", at: 16 * time.Minute, code: "000000", wantMessage: "Kode verifikasi salah", wantLocked: 1},
+		{name: "second lock is doubled", at: 16 * time.Minute, code: "000000", wantMessage: "Terlalu banyak percobaan kode verifikasi. Silahkan coba lagi dalam 30 menit", wantLocked: 2},
+		{name: "correct while locked again", at: 17 * time.Minute, code: code, wantMessage: "Terlalu banyak percobaan kode verifikasi. Silahkan coba lagi dalam 29 menit", wantLocked: 2},
+	}
+
+	for _, tt := range tests {
+		now := start.Add(tt.at)
+		if tt.reissue {
+			user.OTPCreatedAt = &now
+		}
+
+		// The user is loaded again on every request.
+		loaded := *user
+
+		message, err := uu.validateOTP(context.Background(), &loaded, tt.code, now)
+		if tt.wantMessage == "" {
+			if err != nil {
+				t.Errorf("%s: validateOTP = (%q, %v), want valid", tt.name, message, err)
+			}
+		} else if err == nil || !strings.HasPrefix(message, tt.wantMessage) {
+			t.Errorf("%s: validateOTP = (%q, %v), want %q", tt.name, message, err, tt.wantMessage)
+		}
+
+		if user.OTPLockCount != tt.wantLocked {
+			t.Errorf("%s: lock count = %d, want %d", tt.name, user.OTPLockCount, tt.wantLocked)
+		}
+	}
+
+	if len(*events) != 2 || (*events)[0] != ca.AuthEventLockout || (*events)[1] != ca.AuthEventLockout {
+		t.Errorf("auth events = %v, want two lockout", *events)
+	}
+}
+
+func TestOTPLockDurationOf(t *testing.T) {
+	otp := general.OTPCredential{LockDuration: 15}
+
+	tests := []struct {
+		lockCount int
+		want      time.Duration
+	}{
+		{0, 15 * time.Minute},
+		{1, 30 * time.Minute},
+		{2, time.Hour},
+		{6, 16 * time.Hour},
+		{7, 24 * time.Hour},
+		{100, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := otp.LockDurationOf(tt.lockCount); got != tt.want {
+			t.Errorf("LockDurationOf(%d) = %s, want %s", tt.lockCount, got, tt.want)
+		}
+	}
+}
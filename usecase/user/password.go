@@ -0,0 +1,240 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	cg "github.com/furee/backend/constants/general"
+	cu "github.com/furee/backend/constants/user"
+	"github.com/furee/backend/domain/general"
+	du "github.com/furee/backend/domain/user"
+	ua "github.com/furee/backend/usecase/authorization"
+	"github.com/furee/backend/utils"
+	"github.com/furee/backend/utils/phone"
+	"gopkg.in/guregu/null.v4"
+)
+
+// defaultPasswordMinLength is used when the minimum length is not configured.
+const defaultPasswordMinLength = 8
+
+// LoginPassword login the user with phone & password, only for user type that enabled on config.
+// Wrong password is counted to the same lockout as the OTP.
+func (uu UserDataUsecase) LoginPassword(ctx context.Context, data du.PasswordLoginRequest) (*general.JWTAccess, string, error) {
+	user, message, err := uu.getUserByPhone(data.Phone, "LoginPassword")
+	if err != nil {
+		return nil, message, err
+	}
+
+	now := time.Now().UTC()
+
+	if message, locked := uu.isOTPLocked(user, now); locked {
+		return nil, message, errors.New("user locked")
+	}
+
+	if !uu.isPasswordEnabled(user) || !user.Password.Valid {
+		return nil, "Login dengan password tidak tersedia untuk akun ini", errors.New("password login not enabled")
+	}
+
+	if user.Status != cu.StatusActive {
+		return nil, "user is not active", errors.New("user is not active")
+	}
+
+	isMatch, err := utils.ComparePassword(user.Password.String, data.Password)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !isMatch {
+		message, err := uu.registerFailedOTP(ctx, user, now, "Nomor telepon atau password salah")
+		if err != nil {
+			uu.Log.WithField("user id", user.ID).WithError(err).Error("LoginPassword | fail to update failed attempt")
+			return nil, "", err
+		}
+
+		return nil, message, errors.New("password not match")
+	}
+
+	// Reset the failed attempt after success login.
+	if user.OTPAttempt > 0 {
+		err = uu.Repo.UpdateOTPAttempt(nil, du.OTPAttempt{UserID: user.ID, LockCount: user.OTPLockCount})
+		if err != nil {
+			uu.Log.WithField("user id", user.ID).WithError(err).Error("LoginPassword | fail to reset failed attempt")
+		}
+	}
+
+	jwtAccess, err := uu.Token.IssueToken(ctx, user.ID)
+	if err == ua.ErrUserNotActive {
+		return nil, "user is not active", err
+	}
+
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("LoginPassword | fail to get token data from infra")
+		return nil, "", err
+	}
+
+	return jwtAccess, "success login", nil
+}
+
+// ForgotPassword send OTP to the phone of the user, the OTP is used to reset the password.
+func (uu UserDataUsecase) ForgotPassword(ctx context.Context, data du.ForgotPasswordRequest) (string, error) {
+	user, message, err := uu.getUserByPhone(data.Phone, "ForgotPassword")
+	if err != nil {
+		return message, err
+	}
+
+	if !uu.isPasswordEnabled(user) {
+		return "Login dengan password tidak tersedia untuk akun ini", errors.New("password login not enabled")
+	}
+
+	if user.Status != cu.StatusActive {
+		return "user is not active", errors.New("user is not active")
+	}
+
+	if message, locked := uu.isOTPLocked(user, time.Now().UTC()); locked {
+		return message, errors.New("user locked")
+	}
+
+	otpCode := utils.GenerateOTP()
+
+	err = uu.Repo.UpdateOTP(nil, otpCode, null.Int{}, user.ID)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("ForgotPassword | fail to update otp")
+		return "", err
+	}
+
+	decryptedPhone, err := uu.phoneCipher.decrypt(user.Phone)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("ForgotPassword | fail to decrypt user phone")
+		return "", err
+	}
+
+	err = uu.sendOTP(ctx, user.ID, user.PhoneFilter, decryptedPhone, otpCode)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("ForgotPassword | fail to send otp")
+		return "Kode verifikasi gagal dikirim. Silahkan coba beberapa saat lagi", err
+	}
+
+	return "success send otp user", nil
+}
+
+// ResetPassword set the new password after the OTP is verified, every session of the user is revoked.
+func (uu UserDataUsecase) ResetPassword(ctx context.Context, data du.ResetPasswordRequest) (string, error) {
+	user, message, err := uu.getUserByPhone(data.Phone, "ResetPassword")
+	if err != nil {
+		return message, err
+	}
+
+	if !uu.isPasswordEnabled(user) {
+		return "Login dengan password tidak tersedia untuk akun ini", errors.New("password login not enabled")
+	}
+
+	if user.Status != cu.StatusActive {
+		return "user is not active", errors.New("user is not active")
+	}
+
+	minLength := uu.Conf.Authorization.Password.MinLength
+	if minLength <= 0 {
+		minLength = defaultPasswordMinLength
+	}
+
+	if !utils.PasswordStrengthValidator(data.NewPassword, minLength) {
+		return fmt.Sprintf("Password minimal %d karakter dan mengandung huruf besar, huruf kecil dan angka", minLength), errors.New("password too weak")
+	}
+
+	now := time.Now().UTC()
+
+	message, err = uu.checkOTP(ctx, user, data.OTP, now)
+	if err != nil {
+		if message == "" {
+			uu.Log.WithField("user id", user.ID).WithError(err).Error("ResetPassword | fail to update otp attempt")
+		}
+
+		return message, err
+	}
+
+	// The fixed OTP of the whitelisted phone is only for login, it can't take over the password.
+	// The flag is stored with the OTP, so it's refused even when the entry is removed after.
+	if user.OTPWhitelistID.Valid {
+		uu.Log.WithField("user id", user.ID).WithField("whitelist id", user.OTPWhitelistID.Int64).Warn("ResetPassword | whitelist otp is refused")
+		return "Kode verifikasi tidak dapat digunakan untuk reset password. Silahkan minta kode verifikasi baru", errors.New("whitelist otp refused")
+	}
+
+	hashedPassword, err := utils.GeneratePassword(data.NewPassword)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := uu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	err = uu.Repo.VerifyUser(tx, du.VerifyUser{PhoneFilter: user.PhoneFilter, OTP: user.OTP.String})
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return "Kode verifikasi sudah digunakan. Silahkan minta kode verifikasi baru", errors.New("OTP already used")
+		}
+
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("ResetPassword | fail to verify otp")
+		return "", err
+	}
+
+	err = uu.Repo.UpdatePassword(tx, user.ID, hashedPassword, int64(cg.UpdatedBySystem))
+	if err != nil {
+		tx.Rollback()
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("ResetPassword | fail to update password")
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	err = uu.Token.RevokeUserSessions(user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	return "success reset password", nil
+}
+
+func (uu UserDataUsecase) isPasswordEnabled(user *du.User) bool {
+	for _, userType := range uu.Conf.Authorization.Password.UserTypes {
+		if userType != "" && userType == user.UserType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getUserByPhone normalize the phone & find the user by the phone filter.
+func (uu UserDataUsecase) getUserByPhone(rawPhone, funcName string) (*du.User, string, error) {
+	number, err := phone.Parse(rawPhone, phone.RegionID)
+	if err != nil {
+		return nil, "Nomor telepon tidak valid", err
+	}
+
+	phoneFilter, err := uu.phoneCipher.filter(number.E164)
+	if err != nil {
+		uu.Log.WithError(err).Error(funcName + " | fail to get phone filter")
+		return nil, "", err
+	}
+
+	user, err := uu.Repo.GetByPhone(phoneFilter)
+	if err != nil {
+		uu.Log.WithField("phone filter", phoneFilter).WithError(err).Error(funcName + " | fail to get user data from repo")
+		return nil, "", err
+	}
+
+	if user == nil {
+		return nil, "Nomor Anda Belum Terdaftar", errors.New("user not exist")
+	}
+
+	return user, "", nil
+}
@@ -0,0 +1,90 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/furee/backend/domain/general"
+	"github.com/furee/backend/utils"
+	"github.com/furee/backend/utils/phone"
+)
+
+// phoneCipher encrypt the user phone with versioned key, the stored value is "<version>:<hex>".
+// Phone without version prefix is the plaintext phone before the encryption is introduced.
+type phoneCipher struct {
+	activeVersion string
+	keys          map[string][]byte
+	pepper        []byte
+}
+
+func newPhoneCipher(cfg general.PhoneCredential) phoneCipher {
+	pc := phoneCipher{
+		activeVersion: cfg.ActiveKeyVersion,
+		keys:          make(map[string][]byte),
+		pepper:        []byte(cfg.FilterPepper),
+	}
+
+	for _, key := range cfg.Keys {
+		pc.keys[key.Version] = []byte(key.Key)
+	}
+
+	return pc
+}
+
+func (pc phoneCipher) encrypt(e164 string) (string, error) {
+	key, ok := pc.keys[pc.activeVersion]
+	if !ok {
+		return "", fmt.Errorf("phone key version %s not found", pc.activeVersion)
+	}
+
+	encrypted, err := utils.GetEncrypt(key, e164)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%s", pc.activeVersion, encrypted), nil
+}
+
+func (pc phoneCipher) decrypt(stored string) (string, error) {
+	version, encrypted, ok := splitPhoneVersion(stored)
+	if !ok {
+		return stored, nil
+	}
+
+	key, ok := pc.keys[version]
+	if !ok {
+		return "", fmt.Errorf("phone key version %s not found", version)
+	}
+
+	decrypted, err := utils.GetDecrypt(key, encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	return decrypted, nil
+}
+
+// isActive check the stored phone is encrypted with the active key.
+func (pc phoneCipher) isActive(stored string) bool {
+	version, _, ok := splitPhoneVersion(stored)
+	return ok && version == pc.activeVersion
+}
+
+// filter return the blind index of the phone that stored on phone_filter.
+func (pc phoneCipher) filter(e164 string) (string, error) {
+	if len(pc.pepper) == 0 {
+		return "", errors.New("phone filter pepper not configured")
+	}
+
+	return phone.BlindIndex(pc.pepper, e164), nil
+}
+
+func splitPhoneVersion(stored string) (string, string, bool) {
+	idx := strings.Index(stored, ":")
+	if idx <= 0 {
+		return "", "", false
+	}
+
+	return stored[:idx], stored[idx+1:], true
+}
@@ -0,0 +1,545 @@
+package user
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	ca "github.com/furee/backend/constants/authorization"
+	cu "github.com/furee/backend/constants/user"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Default of the privacy setting, used when it's not configured.
+const (
+	defaultDeletionGracePeriod = 14 // days
+	defaultExportLinkDuration  = 60 // minutes
+	defaultJobMaxAttempt       = 3
+	defaultJobTimeout          = 30 // minutes
+	defaultExportFolder        = "exports"
+)
+
+// jobRetryDelay is multiplied by the attempt, so every retry wait longer.
+const jobRetryDelay = 5 * time.Minute
+
+// newStorage return the storage of the data export, nil when minio is not configured.
+func newStorage(conf *general.SectionService, logger *logrus.Logger) *infra.Minio {
+	if conf.Minio.Endpoint == "" {
+		return nil
+	}
+
+	storage, err := infra.NewMinio(conf.Minio)
+	if err != nil {
+		logger.WithError(err).Error("fail to init minio, data export is not available")
+		return nil
+	}
+
+	return &storage
+}
+
+// RequestExport queue the export of the user data, the previous export that still running is returned instead.
+func (uu UserDataUsecase) RequestExport(ctx context.Context) (*du.UserJobResponse, string, error) {
+	return uu.requestJob(ctx, cu.JobTypeExport, time.Now().UTC(), ca.AuditActionExportRequest)
+}
+
+// RequestDeletion queue the deletion of the account, the account is anonymized after the grace period.
+func (uu UserDataUsecase) RequestDeletion(ctx context.Context) (*du.UserJobResponse, string, error) {
+	gracePeriod := uu.Conf.Privacy.DeletionGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = defaultDeletionGracePeriod
+	}
+
+	runAt := time.Now().UTC().AddDate(0, 0, gracePeriod)
+	return uu.requestJob(ctx, cu.JobTypeDelete, runAt, ca.AuditActionDeleteRequest)
+}
+
+// CancelDeletion cancel the deletion that still on the grace period.
+func (uu UserDataUsecase) CancelDeletion(ctx context.Context) (string, error) {
+	userID := da.CurrentUserID(ctx)
+
+	job, err := uu.JobRepo.GetActiveJob(userID, cu.JobTypeDelete)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("CancelDeletion | fail to get job from repo")
+		return "", err
+	}
+
+	if job == nil {
+		return "Tidak ada permintaan hapus akun", errors.New("deletion job not found")
+	}
+
+	tx, err := uu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	err = uu.JobRepo.CancelJob(tx, job.ID)
+	if err != nil {
+		tx.Rollback()
+
+		if err == sql.ErrNoRows {
+			return "Akun sedang dalam proses penghapusan", errors.New("deletion job already running")
+		}
+
+		uu.Log.WithField("job id", job.ID).WithError(err).Error("CancelDeletion | fail to cancel job")
+		return "", err
+	}
+
+	err = uu.insertAuditLog(tx, userID, ca.AuditActionDeleteCancel, userID, map[string]interface{}{"job_id": job.ID})
+	if err != nil {
+		tx.Rollback()
+		uu.Log.WithField("job id", job.ID).WithError(err).Error("CancelDeletion | fail to insert audit log")
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	return "success cancel account deletion", nil
+}
+
+// GetJob return the job of the current user, the download link of the export is signed on every call.
+func (uu UserDataUsecase) GetJob(ctx context.Context, jobID int64) (*du.UserJobResponse, string, error) {
+	job, err := uu.JobRepo.GetByID(jobID)
+	if err != nil {
+		uu.Log.WithField("job id", jobID).WithError(err).Error("GetJob | fail to get job from repo")
+		return nil, "", err
+	}
+
+	if job == nil || job.UserID != da.CurrentUserID(ctx) {
+		return nil, "job not found", errors.New("job not found")
+	}
+
+	res, err := uu.toUserJob(job)
+	if err != nil {
+		uu.Log.WithField("job id", jobID).WithError(err).Error("GetJob | fail to sign export link")
+		return nil, "", err
+	}
+
+	return res, "", nil
+}
+
+// RunNextJob process one due job, false is returned when there is no due job.
+// Failed job is retried with increasing delay until the max attempt, the job that timed out is claimed again.
+func (uu UserDataUsecase) RunNextJob() (bool, error) {
+	timeout := uu.Conf.Privacy.JobTimeout
+	if timeout <= 0 {
+		timeout = defaultJobTimeout
+	}
+
+	now := time.Now().UTC()
+	job, err := uu.JobRepo.ClaimNextJob(now, now.Add(-time.Duration(timeout)*time.Minute))
+	if err != nil {
+		return false, err
+	}
+
+	if job == nil {
+		return false, nil
+	}
+
+	log := uu.Log.WithField("job id", job.ID).WithField("job type", job.JobType)
+
+	maxAttempt := uu.Conf.Privacy.JobMaxAttempt
+	if maxAttempt <= 0 {
+		maxAttempt = defaultJobMaxAttempt
+	}
+
+	// Only the job that timed out is claimed past the max attempt, the failed job is not retried anymore.
+	if job.Attempt > maxAttempt {
+		log.Error("RunNextJob | job timed out")
+		return true, uu.JobRepo.FailJob(job.ID, "job timed out", nil)
+	}
+
+	switch job.JobType {
+	case cu.JobTypeExport:
+		err = uu.runExport(job)
+	case cu.JobTypeDelete:
+		err = uu.runDeletion(job)
+	default:
+		err = fmt.Errorf("unknown job type %s", job.JobType)
+	}
+
+	if err == nil {
+		log.Info("RunNextJob | job done")
+		return true, nil
+	}
+
+	log.WithError(err).Error("RunNextJob | job failed")
+
+	var retryAt *time.Time
+	if job.Attempt < maxAttempt {
+		next := time.Now().UTC().Add(time.Duration(job.Attempt) * jobRetryDelay)
+		retryAt = &next
+	}
+
+	err = uu.JobRepo.FailJob(job.ID, err.Error(), retryAt)
+	if err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
+// PurgeExpiredExports delete the export file that is older than the link duration, the number of the purged export is returned.
+func (uu UserDataUsecase) PurgeExpiredExports() (int, error) {
+	if uu.Storage == nil {
+		return 0, nil
+	}
+
+	jobs, err := uu.JobRepo.GetExpiredJobs(cu.JobTypeExport, time.Now().UTC().Add(-uu.exportDuration()))
+	if err != nil {
+		return 0, err
+	}
+
+	for i, job := range jobs {
+		if job.Result.Valid {
+			err = uu.Storage.DeleteFile(job.Result.String)
+			if err != nil {
+				return i, err
+			}
+		}
+
+		err = uu.JobRepo.ExpireJob(nil, job.ID)
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(jobs), nil
+}
+
+func (uu UserDataUsecase) requestJob(ctx context.Context, jobType string, runAt time.Time, action string) (*du.UserJobResponse, string, error) {
+	userID := da.CurrentUserID(ctx)
+
+	if jobType == cu.JobTypeExport && uu.Storage == nil {
+		return nil, "Ekspor data belum tersedia", errors.New("storage not configured")
+	}
+
+	job, err := uu.JobRepo.GetActiveJob(userID, jobType)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("requestJob | fail to get job from repo")
+		return nil, "", err
+	}
+
+	if job != nil {
+		res, err := uu.toUserJob(job)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return res, "request already in progress", nil
+	}
+
+	job = &du.UserJob{
+		UserID:    userID,
+		JobType:   jobType,
+		Status:    cu.JobStatusPending,
+		RunAt:     runAt,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	tx, err := uu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	job.ID, err = uu.JobRepo.InsertJob(tx, *job)
+	if err != nil {
+		tx.Rollback()
+		uu.Log.WithField("user id", userID).WithError(err).Error("requestJob | fail to insert job")
+		return nil, "", err
+	}
+
+	err = uu.insertAuditLog(tx, userID, action, userID, map[string]interface{}{"job_id": job.ID, "run_at": runAt})
+	if err != nil {
+		tx.Rollback()
+		uu.Log.WithField("user id", userID).WithError(err).Error("requestJob | fail to insert audit log")
+		return nil, "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", err
+	}
+
+	res, err := uu.toUserJob(job)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return res, "success request " + jobType, nil
+}
+
+// runExport build ZIP of the profile, orders with the items & sessions then upload it as private file.
+func (uu UserDataUsecase) runExport(job *du.UserJob) error {
+	if uu.Storage == nil {
+		return errors.New("storage not configured")
+	}
+
+	user, err := uu.Repo.GetByID(job.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil || user.Status == cu.StatusDeleted {
+		return errors.New("user not found")
+	}
+
+	profile, err := uu.toUserDetail(user)
+	if err != nil {
+		return err
+	}
+
+	orders, err := uu.OrderRepo.GetListByUserID(job.UserID)
+	if err != nil {
+		return err
+	}
+
+	for i := range orders {
+		orders[i].Items, err = uu.ItemRepo.GetListByOrderID(orders[i].OrderID)
+		if err != nil {
+			return err
+		}
+	}
+
+	sessions, err := uu.SessionRepo.GetListByUserID(job.UserID)
+	if err != nil {
+		return err
+	}
+
+	bundle := new(bytes.Buffer)
+	writer := zip.NewWriter(bundle)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", profile},
+		{"orders.json", orders},
+		{"sessions.json", sessions},
+	}
+
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		f, err := writer.Create(file.name)
+		if err != nil {
+			return err
+		}
+
+		_, err = f.Write(content)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	// Random name so the file path can't be guessed from the user & job id.
+	name, err := utils.GetUUID()
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("%s%s.zip", uu.exportFolder(job.UserID), name)
+	err = uu.Storage.UploadFile(infra.MinioPrivateAccess, path, "application/zip", bundle.Bytes())
+	if err != nil {
+		return err
+	}
+
+	tx, err := uu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = uu.JobRepo.CompleteJob(tx, job.ID, path)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.insertAuditLog(tx, 0, ca.AuditActionExportDone, job.UserID, map[string]interface{}{"job_id": job.ID})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// runDeletion anonymize the PII of the user & the orders. The orders & items is kept for accounting,
+// the session, MFA, pending phone change, OTP delivery & data export is removed.
+// The auth events is kept for the security history without the phone hash, IP & user agent.
+func (uu UserDataUsecase) runDeletion(job *du.UserJob) error {
+	user, err := uu.Repo.GetByID(job.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	err = uu.Token.RevokeUserSessions(job.UserID)
+	if err != nil {
+		return err
+	}
+
+	if uu.Storage != nil {
+		err = uu.Storage.DeleteFolder(uu.exportFolder(job.UserID))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Phone is unique, so it's replaced with the placeholder of the user.
+	placeholder := fmt.Sprintf("deleted-%d", job.UserID)
+
+	tx, err := uu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = uu.Repo.AnonymizeUser(tx, du.AnonymizeUser{
+		UserID:      job.UserID,
+		Name:        cu.DeletedUserName,
+		Phone:       placeholder,
+		PhoneFilter: placeholder,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.OrderRepo.AnonymizeByUserID(tx, job.UserID, cu.DeletedUserName)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.Repo.DeletePhoneChanges(tx, job.UserID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.SessionRepo.DeleteByUserID(tx, job.UserID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.OTPDeliveryRepo.DeleteByUserID(tx, job.UserID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.AuthEventRepo.RedactByUser(tx, job.UserID, user.PhoneFilter)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.MFARepo.DeleteRecoveryCodes(tx, job.UserID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.MFARepo.Delete(tx, job.UserID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.JobRepo.ExpireUserJobs(tx, job.UserID, cu.JobTypeExport)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.JobRepo.CompleteJob(tx, job.ID, "")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = uu.insertAuditLog(tx, 0, ca.AuditActionDeleteDone, job.UserID, map[string]interface{}{"job_id": job.ID})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (uu UserDataUsecase) toUserJob(job *du.UserJob) (*du.UserJobResponse, error) {
+	res := &du.UserJobResponse{
+		ID:         job.ID,
+		JobType:    job.JobType,
+		Status:     job.Status,
+		RunAt:      job.RunAt,
+		FinishedAt: job.FinishedAt,
+		CreatedAt:  job.CreatedAt,
+	}
+
+	if job.JobType != cu.JobTypeExport || job.Status != cu.JobStatusDone || !job.Result.Valid || job.FinishedAt == nil || uu.Storage == nil {
+		return res, nil
+	}
+
+	// The link never outlive the file, the file is deleted after the duration since the export is done.
+	expiredAt := job.FinishedAt.UTC().Add(uu.exportDuration())
+	expiry := time.Until(expiredAt)
+	if expiry <= 0 {
+		res.Status = cu.JobStatusExpired
+		return res, nil
+	}
+
+	link, err := uu.Storage.PresignedURL(job.Result.String, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	res.DownloadURL = link
+	res.URLExpiredAt = &expiredAt
+
+	return res, nil
+}
+
+// exportFolder return the folder of the data export of the user.
+func (uu UserDataUsecase) exportFolder(userID int64) string {
+	folder := uu.Conf.Privacy.ExportFolder
+	if folder == "" {
+		folder = defaultExportFolder
+	}
+
+	return fmt.Sprintf("%s/%d/", folder, userID)
+}
+
+// exportDuration return how long the data export is kept.
+func (uu UserDataUsecase) exportDuration() time.Duration {
+	duration := uu.Conf.Privacy.ExportLinkDuration
+	if duration <= 0 {
+		duration = defaultExportLinkDuration
+	}
+
+	return time.Duration(duration) * time.Minute
+}
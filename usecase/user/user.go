@@ -1,127 +1,210 @@
 package user
 
 import (
-	"crypto/sha256"
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"regexp"
 	"time"
 
+	ca "github.com/furee/backend/constants/authorization"
+	cg "github.com/furee/backend/constants/general"
+	cu "github.com/furee/backend/constants/user"
+	da "github.com/furee/backend/domain/authorization"
 	"github.com/furee/backend/domain/general"
 	du "github.com/furee/backend/domain/user"
 	"github.com/furee/backend/infra"
 	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	ro "github.com/furee/backend/repo/order"
 	ru "github.com/furee/backend/repo/user"
+	ua "github.com/furee/backend/usecase/authorization"
 	"github.com/furee/backend/utils"
+	"github.com/furee/backend/utils/phone"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
 )
 
 type UserDataUsecaseItf interface {
-	LoginUser(data du.UserLoginRequest) (string, error)
-	VerifyOTP(data du.VerifyOTPRequest) (*general.JWTAccess, string, error)
+	LoginUser(ctx context.Context, data du.UserLoginRequest) (string, error)
+	VerifyOTP(ctx context.Context, data du.VerifyOTPRequest) (*general.JWTAccess, string, error)
+	GetActiveUser(ctx context.Context, userID int64) (*du.User, string, error)
+	GetProfile(ctx context.Context) (*du.UserDetailResponse, string, error)
+	UpdateProfile(ctx context.Context, data du.UpdateProfileRequest) (*du.UserDetailResponse, string, error)
+	RequestPhoneChange(ctx context.Context, data du.ChangePhoneRequest) (string, error)
+	VerifyPhoneChange(ctx context.Context, data du.VerifyPhoneChangeRequest) (string, error)
+	GetListUser(ctx context.Context, pagination general.PaginationData, filter du.UserFilter) ([]du.UserAdminResponse, general.PaginationData, string, error)
+	GetUserDetail(ctx context.Context, userID int64) (*du.UserAdminResponse, string, error)
+	UpdateUserStatus(ctx context.Context, userID int64, data du.UpdateStatusRequest) (*du.UserAdminResponse, string, error)
+	ForceLogout(ctx context.Context, userID int64) (string, error)
+	NormalizePhones(dryRun bool) (du.PhoneMigrationReport, error)
+	LoginPassword(ctx context.Context, data du.PasswordLoginRequest) (*general.JWTAccess, string, error)
+	ForgotPassword(ctx context.Context, data du.ForgotPasswordRequest) (string, error)
+	ResetPassword(ctx context.Context, data du.ResetPasswordRequest) (string, error)
+	ReencryptPhones(batchSize int, dryRun bool, afterBatch func(page int)) (du.PhoneMigrationReport, error)
+	RequestExport(ctx context.Context) (*du.UserJobResponse, string, error)
+	RequestDeletion(ctx context.Context) (*du.UserJobResponse, string, error)
+	CancelDeletion(ctx context.Context) (string, error)
+	GetJob(ctx context.Context, jobID int64) (*du.UserJobResponse, string, error)
+	RunNextJob() (bool, error)
+	PurgeExpiredExports() (int, error)
 }
 
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
 type UserDataUsecase struct {
-	Repo   ru.UserDataRepoItf
-	DBList *infra.DatabaseList
-	Conf   *general.SectionService
-	Log    *logrus.Logger
+	Repo            ru.UserDataRepoItf
+	OTPDeliveryRepo ru.OTPDeliveryRepoItf
+	AuditLogRepo    ra.AuditLogRepoItf
+	AuthEventRepo   ra.AuthEventRepoItf
+	JobRepo         ru.UserJobRepoItf
+	SessionRepo     ra.SessionRepoItf
+	MFARepo         ra.MFARepoItf
+	OrderRepo       ro.OrderDataRepoItf
+	ItemRepo        ro.ItemDataRepoItf
+	Storage         *infra.Minio
+	OTPSenders      []infra.OTPSender
+	Token           ua.TokenUsecaseItf
+	AuthEvent       ua.AuthEventUsecaseItf
+	phoneCipher     phoneCipher
+	whitelist       *whitelistCache
+	DBList          *infra.DatabaseList
+	Conf            *general.SectionService
+	Log             *logrus.Logger
 }
 
-func newUserDataUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList) UserDataUsecase {
+func newUserDataUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList, whitelist *whitelistCache, auth ua.AuthorizationUsecase) UserDataUsecase {
+	if !conf.App.IsNonProd() && conf.Whitelist.AllowOnProduction {
+		logger.Warn("OTP bypass of the whitelisted phone is enabled on production")
+	}
+
 	return UserDataUsecase{
-		Repo:   r.User.User,
-		Conf:   conf,
-		Log:    logger,
-		DBList: dbList,
-	}
-}
-
-func (uu UserDataUsecase) VerifyOTP(data du.VerifyOTPRequest) (*general.JWTAccess, string, error) {
-	// phoneFilter := sha256.Sum256([]byte(data.Phone))
-	// data.PhoneFilter = fmt.Sprintf("%x", phoneFilter[:])
-
-	// isExist, err := uu.Repo.IsExistUser(data.PhoneFilter)
-	// if err != nil {
-	// 	uu.Log.WithField("request", utils.StructToString(data)).WithError(err).Errorf("fail to checking is exist user")
-	// 	return nil, "", err
-	// }
-
-	// if !isExist {
-	// 	uu.Log.WithField("request", utils.StructToString(data)).Errorf("user is not exist")
-	// 	return nil, "Nomor Anda Belum Terdaftar", nil
-	// }
-
-	// user, err := uu.Repo.GetByPhone(data.PhoneFilter)
-	// if err != nil {
-	// 	uu.Log.WithField("user id", user.ID).WithError(err).Error("VerifyOTP | fail to get user data from repo")
-	// 	return nil, "", err
-	// }
-
-	// if user == nil {
-	// 	return nil, "", errors.New("user data not found")
-	// }
-
-	// // checking whitelist OTP. if phone is on whitelist, do not send OTP to this phone.
-	// _, ok := uu.Conf.Whitelist.User.Phone[data.Phone]
-	// if !ok {
-	// 	if !user.OTP.Valid {
-	// 		return nil, "", errors.New("OTP is null")
-	// 	}
-
-	// 	if user.OTP.String != data.OTP {
-	// 		return nil, "Kode verifikasi salah. Silahkan cek kode verifikasi di akun whatsapp anda", errors.New("OTP not match")
-	// 	}
-	// } else {
-	// 	if data.OTP != uu.Conf.Whitelist.User.OTP {
-	// 		return nil, "Kode verifikasi salah. Silahkan cek kode verifikasi di akun whatsapp anda", errors.New("OTP not match")
-	// 	}
-	// }
-
-	// err = uu.Repo.VerifyUser(nil, du.VerifyUser{PhoneFilter: data.PhoneFilter, OTP: user.OTP.String})
-	// if err != nil {
-	// 	return nil, "", err
-	// }
-
-	session, err := utils.GetEncrypt([]byte(uu.Conf.App.SecretKey), fmt.Sprintf("%v", 1))
-	if err != nil {
-		uu.Log.WithField("user id", 1).WithError(err).Error("VerifyOTP | fail to get token data from infra")
+		Repo:            r.User.User,
+		OTPDeliveryRepo: r.User.OTPDelivery,
+		AuditLogRepo:    r.Authorization.AuditLog,
+		AuthEventRepo:   r.Authorization.AuthEvent,
+		JobRepo:         r.User.Job,
+		SessionRepo:     r.Authorization.Session,
+		MFARepo:         r.Authorization.MFA,
+		OrderRepo:       r.Order.Order,
+		ItemRepo:        r.Order.Item,
+		Storage:         newStorage(conf, logger),
+		OTPSenders:      infra.NewOTPSenders(conf, logger),
+		Token:           auth.Token,
+		AuthEvent:       auth.AuthEvent,
+		phoneCipher:     newPhoneCipher(conf.Authorization.Phone),
+		whitelist:       whitelist,
+		Conf:            conf,
+		Log:             logger,
+		DBList:          dbList,
+	}
+}
+
+func (uu UserDataUsecase) VerifyOTP(ctx context.Context, data du.VerifyOTPRequest) (*general.JWTAccess, string, error) {
+	number, err := phone.Parse(data.Phone, phone.RegionID)
+	if err != nil {
+		return nil, "Nomor telepon tidak valid", err
+	}
+
+	data.Phone = number.E164
+	data.PhoneFilter, err = uu.phoneCipher.filter(number.E164)
+	if err != nil {
+		uu.Log.WithError(err).Error("VerifyOTP | fail to get phone filter")
 		return nil, "", err
 	}
 
-	accessToken, renewToken, err := utils.GenerateJWT(session)
+	user, err := uu.Repo.GetByPhone(data.PhoneFilter)
 	if err != nil {
-		uu.Log.WithField("user id", 1).WithError(err).Error("VerifyOTP | fail to get token data from infra")
+		uu.Log.WithField("phone filter", data.PhoneFilter).WithError(err).Error("VerifyOTP | fail to get user data from repo")
 		return nil, "", err
 	}
 
-	generateTime := time.Now().UTC()
+	if user == nil {
+		uu.Log.WithField("phone filter", data.PhoneFilter).Error("VerifyOTP | user is not exist")
+		uu.AuthEvent.Record(ctx, ca.AuthEventOTPFailed, 0, data.PhoneFilter, map[string]interface{}{
+			"reason": "user not exist",
+		})
+		return nil, "Nomor Anda Belum Terdaftar", errors.New("user not exist")
+	}
+
+	if user.Status != cu.StatusActive {
+		return nil, "user is not active", errors.New("user is not active")
+	}
+
+	now := time.Now().UTC()
 
-	return &general.JWTAccess{
-		AccessToken:        accessToken,
-		AccessTokenExpired: generateTime.Add(time.Duration(uu.Conf.Authorization.JWT.AccessTokenDuration) * time.Minute).Format(time.RFC3339),
-		RenewToken:         renewToken,
-		RenewTokenExpired:  generateTime.Add(time.Duration(uu.Conf.Authorization.JWT.RefreshTokenDuration*24) * time.Hour).Format(time.RFC3339),
-	}, "success verify user account", nil
+	message, err := uu.checkOTP(ctx, user, data.OTP, now)
+	if err != nil {
+		if message == "" {
+			uu.Log.WithField("user id", user.ID).WithError(err).Error("VerifyOTP | fail to update otp attempt")
+		}
+
+		return nil, message, err
+	}
+
+	// The OTP of the whitelisted phone is never sent, every login with it is audited.
+	// The flag is stored with the OTP, so the login is audited even when the entry is removed after.
+	if user.OTPWhitelistID.Valid {
+		err = uu.auditOTPBypass(user.ID, user.OTPWhitelistID.Int64)
+		if err != nil {
+			uu.Log.WithField("user id", user.ID).WithError(err).Error("VerifyOTP | fail to insert audit log")
+			return nil, "", err
+		}
+	}
+
+	err = uu.Repo.VerifyUser(nil, du.VerifyUser{PhoneFilter: data.PhoneFilter, OTP: user.OTP.String})
+	if err == sql.ErrNoRows {
+		return nil, "Kode verifikasi sudah digunakan. Silahkan minta kode verifikasi baru", errors.New("OTP already used")
+	}
+
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("VerifyOTP | fail to verify user")
+		return nil, "", err
+	}
+
+	jwtAccess, err := uu.Token.IssueToken(ctx, user.ID)
+	if err == ua.ErrUserNotActive {
+		return nil, "user is not active", err
+	}
+
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("VerifyOTP | fail to get token data from infra")
+		return nil, "", err
+	}
+
+	return jwtAccess, "success verify user account", nil
 }
 
-func (uu UserDataUsecase) LoginUser(data du.UserLoginRequest) (string, error) {
-	phoneFilter := sha256.Sum256([]byte(data.Phone))
-	phone := fmt.Sprintf("%x", phoneFilter[:])
+func (uu UserDataUsecase) LoginUser(ctx context.Context, data du.UserLoginRequest) (string, error) {
+	number, err := phone.Parse(data.Phone, phone.RegionID)
+	if err != nil {
+		return "Nomor telepon tidak valid", err
+	}
+
+	phoneFilter, err := uu.phoneCipher.filter(number.E164)
+	if err != nil {
+		uu.Log.WithError(err).Error("LoginUser | fail to get phone filter")
+		return "", err
+	}
 
-	isExist, err := uu.Repo.IsExistUser(phone)
+	isExist, err := uu.Repo.IsExistUser(phoneFilter)
 	if err != nil {
-		uu.Log.WithField("request", utils.StructToString(data)).WithError(err).Errorf("fail to checking is exist user")
+		uu.Log.WithField("phone filter", phoneFilter).WithError(err).Errorf("fail to checking is exist user")
 		return "", err
 	}
 
 	if !isExist {
-		uu.Log.WithField("request", utils.StructToString(data)).Errorf("user is not exist")
+		uu.Log.WithField("phone filter", phoneFilter).Errorf("user is not exist")
 		return "Nomor Anda Belum Terdaftar", errors.New("user not exist")
 	}
 
-	user, err := uu.Repo.GetByPhone(phone)
+	user, err := uu.Repo.GetByPhone(phoneFilter)
 	if err != nil {
-		uu.Log.WithField("request", utils.StructToString(data)).WithError(err).Errorf("fail to get user")
+		uu.Log.WithField("phone filter", phoneFilter).WithError(err).Errorf("fail to get user")
 		return "", err
 	}
 
@@ -129,13 +212,459 @@ func (uu UserDataUsecase) LoginUser(data du.UserLoginRequest) (string, error) {
 		return "", errors.New("user data not found")
 	}
 
+	// The OTP is not sent to the blocked or deleted user.
+	if user.Status != cu.StatusActive {
+		return "user is not active", errors.New("user is not active")
+	}
+
+	if message, locked := uu.isOTPLocked(user, time.Now().UTC()); locked {
+		return message, errors.New("otp locked")
+	}
+
+	whitelist, err := uu.getWhitelist(phoneFilter, time.Now().UTC())
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("LoginUser | fail to get whitelist")
+		return "", err
+	}
+
 	otpCode := utils.GenerateOTP()
+	var whitelistID null.Int
+	if whitelist != nil {
+		otpCode = whitelist.OTP
+		whitelistID = null.IntFrom(whitelist.ID)
+	}
 
-	err = uu.Repo.UpdateOTP(nil, otpCode, user.ID)
+	err = uu.Repo.UpdateOTP(nil, otpCode, whitelistID, user.ID)
 	if err != nil {
-		uu.Log.WithField("request", utils.StructToString(data)).WithError(err).Errorf("fail to update otp")
+		uu.Log.WithField("user id", user.ID).WithError(err).Errorf("fail to update otp")
 		return "fail to login user", nil
 	}
 
+	if whitelist != nil {
+		uu.Log.WithField("user id", user.ID).WithField("whitelist id", whitelist.ID).Info("LoginUser | whitelisted phone, otp is not sent")
+		return "success send otp user", nil
+	}
+
+	err = uu.sendOTP(ctx, user.ID, phoneFilter, number.E164, otpCode)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("LoginUser | fail to send otp")
+		return "Kode verifikasi gagal dikirim. Silahkan coba beberapa saat lagi", err
+	}
+
 	return "success send otp user", nil
 }
+
+// GetActiveUser return the user of the session, only active user is allowed to access the API.
+func (uu UserDataUsecase) GetActiveUser(ctx context.Context, userID int64) (*du.User, string, error) {
+	user, err := uu.Repo.GetByID(userID)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("GetActiveUser | fail to get user data from repo")
+		return nil, "", err
+	}
+
+	if user == nil {
+		return nil, "user not found", errors.New("user not found")
+	}
+
+	if user.Status != cu.StatusActive {
+		return nil, "user is not active", errors.New("user is not active")
+	}
+
+	return user, "", nil
+}
+
+// GetProfile return the profile of the current user.
+func (uu UserDataUsecase) GetProfile(ctx context.Context) (*du.UserDetailResponse, string, error) {
+	user, ok := da.CurrentUser(ctx)
+	if !ok {
+		return nil, "", errors.New("user not found on context")
+	}
+
+	profile, err := uu.toUserDetail(user)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("GetProfile | fail to decrypt user phone")
+		return nil, "", err
+	}
+
+	return profile, "", nil
+}
+
+// UpdateProfile update the name & profile field of the current user.
+func (uu UserDataUsecase) UpdateProfile(ctx context.Context, data du.UpdateProfileRequest) (*du.UserDetailResponse, string, error) {
+	userID := da.CurrentUserID(ctx)
+	if userID == 0 {
+		return nil, "", errors.New("user not found on context")
+	}
+
+	if data.Email != "" && !emailPattern.MatchString(data.Email) {
+		return nil, "Format email tidak valid", errors.New("email not valid")
+	}
+
+	err := uu.Repo.UpdateProfile(nil, userID, data)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("UpdateProfile | fail to update user profile")
+		return nil, "", err
+	}
+
+	user, err := uu.Repo.GetByID(userID)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("UpdateProfile | fail to get user data from repo")
+		return nil, "", err
+	}
+
+	if user == nil {
+		return nil, "", errors.New("user not found")
+	}
+
+	profile, err := uu.toUserDetail(user)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("UpdateProfile | fail to decrypt user phone")
+		return nil, "", err
+	}
+
+	return profile, "", nil
+}
+
+// RequestPhoneChange send OTP to the new phone, the phone is not changed until the OTP is verified.
+func (uu UserDataUsecase) RequestPhoneChange(ctx context.Context, data du.ChangePhoneRequest) (string, error) {
+	user, ok := da.CurrentUser(ctx)
+	if !ok {
+		return "", errors.New("user not found on context")
+	}
+
+	number, err := phone.Parse(data.Phone, phone.RegionID)
+	if err != nil {
+		return "Nomor telepon tidak valid", err
+	}
+
+	// OTP can only be delivered to mobile number.
+	if number.Type == phone.TypeLandline {
+		return "Nomor telepon harus nomor handphone", errors.New("phone is landline")
+	}
+
+	newPhoneFilter, err := uu.phoneCipher.filter(number.E164)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("RequestPhoneChange | fail to get phone filter")
+		return "", err
+	}
+
+	encryptedPhone, err := uu.phoneCipher.encrypt(number.E164)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("RequestPhoneChange | fail to encrypt phone")
+		return "", err
+	}
+
+	if newPhoneFilter == user.PhoneFilter {
+		return "Nomor baru sama dengan nomor saat ini", errors.New("phone not changed")
+	}
+
+	isExist, err := uu.Repo.IsExistUser(newPhoneFilter)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("RequestPhoneChange | fail to checking is exist user")
+		return "", err
+	}
+
+	if isExist {
+		return "Nomor sudah terdaftar", errors.New("phone already registered")
+	}
+
+	otpCode := utils.GenerateOTP()
+
+	_, err = uu.Repo.InsertPhoneChange(nil, du.PhoneChange{
+		UserID:         user.ID,
+		NewPhone:       encryptedPhone,
+		NewPhoneFilter: newPhoneFilter,
+		OTP:            otpCode,
+		ExpiredAt:      time.Now().UTC().Add(time.Duration(uu.Conf.Authorization.OTP.Duration) * time.Minute),
+	})
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("RequestPhoneChange | fail to insert phone change")
+		return "", err
+	}
+
+	err = uu.sendOTP(ctx, user.ID, newPhoneFilter, number.E164, otpCode)
+	if err != nil {
+		uu.Log.WithField("user id", user.ID).WithError(err).Error("RequestPhoneChange | fail to send otp")
+		return "Kode verifikasi gagal dikirim. Silahkan coba beberapa saat lagi", err
+	}
+
+	return "success send otp to new phone", nil
+}
+
+// VerifyPhoneChange swap the phone of the user with the pending new phone,
+// every other session of the user is revoked after the phone is changed.
+func (uu UserDataUsecase) VerifyPhoneChange(ctx context.Context, data du.VerifyPhoneChangeRequest) (string, error) {
+	userID := da.CurrentUserID(ctx)
+	if userID == 0 {
+		return "", errors.New("user not found on context")
+	}
+
+	phoneChange, err := uu.Repo.GetPendingPhoneChange(userID)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("VerifyPhoneChange | fail to get phone change from repo")
+		return "", err
+	}
+
+	if phoneChange == nil {
+		return "Kode verifikasi tidak ditemukan. Silahkan minta kode verifikasi baru", errors.New("phone change not found")
+	}
+
+	if time.Now().UTC().After(phoneChange.ExpiredAt.UTC()) {
+		return "Kode verifikasi sudah kedaluwarsa. Silahkan minta kode verifikasi baru", errors.New("OTP expired")
+	}
+
+	// The attempt is counted before the OTP is compared, so the parallel guess can not pass the limit.
+	attempt, err := uu.Repo.IncrementPhoneChangeAttempt(nil, phoneChange.ID)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("VerifyPhoneChange | fail to update otp attempt")
+		return "", err
+	}
+
+	if attempt > uu.Conf.Authorization.OTP.MaxAttempt {
+		return "Terlalu banyak percobaan kode verifikasi. Silahkan minta kode verifikasi baru", errors.New("too many otp attempt")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(phoneChange.OTP), []byte(data.OTP)) != 1 {
+		return "Kode verifikasi salah", errors.New("OTP not match")
+	}
+
+	// The new phone could be registered by other user after the OTP is sent.
+	isExist, err := uu.Repo.IsExistUser(phoneChange.NewPhoneFilter)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("VerifyPhoneChange | fail to checking is exist user")
+		return "", err
+	}
+
+	if isExist {
+		return "Nomor sudah terdaftar", errors.New("phone already registered")
+	}
+
+	tx, err := uu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	err = uu.Repo.VerifyPhoneChange(tx, phoneChange.ID)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return "Kode verifikasi sudah digunakan", errors.New("phone change already verified")
+		}
+
+		uu.Log.WithField("user id", userID).WithError(err).Error("VerifyPhoneChange | fail to verify phone change")
+		return "", err
+	}
+
+	err = uu.Repo.UpdatePhone(tx, du.UpdatePhone{
+		UserID:      userID,
+		Phone:       phoneChange.NewPhone,
+		PhoneFilter: phoneChange.NewPhoneFilter,
+		UpdatedBy:   userID,
+	})
+	if err != nil {
+		tx.Rollback()
+		uu.Log.WithField("user id", userID).WithError(err).Error("VerifyPhoneChange | fail to update user phone")
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	err = uu.Token.RevokeOtherSessions(ctx)
+	if err != nil {
+		uu.Log.WithField("user id", userID).WithError(err).Error("VerifyPhoneChange | fail to revoke other session")
+		return "", err
+	}
+
+	return "success change phone", nil
+}
+
+func (uu UserDataUsecase) toUserDetail(user *du.User) (*du.UserDetailResponse, error) {
+	decryptedPhone, err := uu.phoneCipher.decrypt(user.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &du.UserDetailResponse{
+		ID:     user.ID,
+		Name:   user.Name,
+		Email:  user.Email,
+		Status: user.Status,
+		Phone:  decryptedPhone,
+	}, nil
+}
+
+// sendOTP try every configured sender in order until one of them succeed.
+// Each attempt is recorded together with the provider response.
+func (uu UserDataUsecase) sendOTP(ctx context.Context, userID int64, phoneFilter, phone, otpCode string) error {
+	if len(uu.OTPSenders) == 0 {
+		return errors.New("no otp sender configured")
+	}
+
+	var sendErr error
+	for _, sender := range uu.OTPSenders {
+		response, err := sender.Send(phone, otpCode)
+
+		delivery := du.OTPDelivery{
+			UserID:   userID,
+			Channel:  sender.Channel(),
+			Status:   cg.OTPDeliverySuccess,
+			Response: response,
+		}
+
+		if err != nil {
+			delivery.Status = cg.OTPDeliveryFail
+			if delivery.Response == "" {
+				delivery.Response = err.Error()
+			}
+
+			uu.Log.WithField("user id", userID).WithField("channel", sender.Channel()).WithError(err).Warn("sendOTP | fail to send otp, try next channel")
+		}
+
+		_, errInsert := uu.OTPDeliveryRepo.InsertDelivery(nil, delivery)
+		if errInsert != nil {
+			uu.Log.WithField("delivery", utils.StructToString(delivery)).WithError(errInsert).Error("sendOTP | fail to record otp delivery")
+		}
+
+		if err == nil {
+			uu.AuthEvent.Record(ctx, ca.AuthEventOTPSent, userID, phoneFilter, map[string]interface{}{
+				"channel": sender.Channel(),
+			})
+
+			return nil
+		}
+
+		sendErr = err
+	}
+
+	return sendErr
+}
+
+// checkOTP validate the OTP of the user, every wrong OTP is counted to the lockout.
+// Both the failed & verified OTP is recorded to the auth event.
+func (uu UserDataUsecase) checkOTP(ctx context.Context, user *du.User, otpCode string, now time.Time) (string, error) {
+	message, err := uu.validateOTP(ctx, user, otpCode, now)
+	if err != nil {
+		uu.AuthEvent.Record(ctx, ca.AuthEventOTPFailed, user.ID, user.PhoneFilter, map[string]interface{}{
+			"reason": err.Error(),
+		})
+
+		return message, err
+	}
+
+	uu.AuthEvent.Record(ctx, ca.AuthEventOTPVerified, user.ID, user.PhoneFilter, nil)
+
+	return "", nil
+}
+
+func (uu UserDataUsecase) validateOTP(ctx context.Context, user *du.User, otpCode string, now time.Time) (string, error) {
+	if message, locked := uu.isOTPLocked(user, now); locked {
+		return message, errors.New("otp locked")
+	}
+
+	if !user.OTP.Valid || user.OTPCreatedAt == nil {
+		return "Kode verifikasi tidak ditemukan. Silahkan minta kode verifikasi baru", errors.New("OTP is null")
+	}
+
+	if now.After(user.OTPCreatedAt.UTC().Add(time.Duration(uu.Conf.Authorization.OTP.Duration) * time.Minute)) {
+		return "Kode verifikasi sudah kedaluwarsa. Silahkan minta kode verifikasi baru", errors.New("OTP expired")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(user.OTP.String), []byte(otpCode)) != 1 {
+		message, err := uu.registerFailedOTP(ctx, user, now, "Kode verifikasi salah. Silahkan cek kode verifikasi di akun whatsapp anda")
+		if err != nil {
+			return "", err
+		}
+
+		return message, errors.New("OTP not match")
+	}
+
+	return "", nil
+}
+
+// isOTPLocked return the message shown to the user while the phone is still locked
+// because of too many failed OTP attempts.
+func (uu UserDataUsecase) isOTPLocked(user *du.User, now time.Time) (string, bool) {
+	if user.OTPLockedUntil == nil || !now.Before(user.OTPLockedUntil.UTC()) {
+		return "", false
+	}
+
+	remaining := int(math.Ceil(user.OTPLockedUntil.UTC().Sub(now).Minutes()))
+
+	return fmt.Sprintf("Terlalu banyak percobaan kode verifikasi. Silahkan coba lagi dalam %d menit", remaining), true
+}
+
+// registerFailedOTP count the failed attempt and lock the phone once the attempt reach
+// the configured limit. Every following lockout doubles the lock duration.
+// Wrong password share the same counter so both login method is locked together.
+// The attempt is incremented on the database, the lock is decided from the returned value.
+func (uu UserDataUsecase) registerFailedOTP(ctx context.Context, user *du.User, now time.Time, message string) (string, error) {
+	current, err := uu.Repo.IncrementOTPAttempt(nil, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if current.Attempt < uu.Conf.Authorization.OTP.MaxAttempt {
+		return message, nil
+	}
+
+	lockDuration := uu.Conf.Authorization.OTP.LockDurationOf(current.LockCount)
+	lockedUntil := now.Add(lockDuration)
+	attempt := du.OTPAttempt{
+		UserID:      user.ID,
+		Attempt:     0,
+		LockCount:   current.LockCount + 1,
+		LockedUntil: &lockedUntil,
+	}
+
+	message = fmt.Sprintf("Terlalu banyak percobaan kode verifikasi. Silahkan coba lagi dalam %d menit", int(lockDuration.Minutes()))
+
+	locked, err := uu.Repo.LockOTP(nil, attempt, current.LockCount)
+	if err != nil {
+		return "", err
+	}
+
+	// The parallel request that reach the limit together is only locked once.
+	if locked {
+		uu.AuthEvent.Record(ctx, ca.AuthEventLockout, user.ID, user.PhoneFilter, map[string]interface{}{
+			"lock_count":   attempt.LockCount,
+			"locked_until": attempt.LockedUntil,
+		})
+	}
+
+	return message, nil
+}
+
+// getWhitelist return the whitelist entry of the phone, nil when the phone is not whitelisted
+// or the OTP bypass is not allowed on this environment.
+func (uu UserDataUsecase) getWhitelist(phoneFilter string, now time.Time) (*du.OTPWhitelist, error) {
+	if !isOTPBypassAllowed(uu.Conf) {
+		return nil, nil
+	}
+
+	return uu.whitelist.get(phoneFilter, now)
+}
+
+func (uu UserDataUsecase) auditOTPBypass(userID int64, whitelistID int64) error {
+	return uu.insertAuditLog(nil, userID, ca.AuditActionOTPBypass, userID, map[string]interface{}{
+		"whitelist_id": whitelistID,
+	})
+}
+
+// insertAuditLog record the action on the user, actorID 0 is the system.
+func (uu UserDataUsecase) insertAuditLog(tx *sql.Tx, actorID int64, action string, userID int64, detail map[string]interface{}) error {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+
+	return uu.AuditLogRepo.InsertAuditLog(tx, da.AuditLog{
+		ActorID:    null.NewInt(actorID, actorID != 0),
+		Action:     action,
+		EntityType: ca.AuditEntityUser,
+		EntityID:   fmt.Sprintf("%v", userID),
+		Detail:     string(data),
+	})
+}
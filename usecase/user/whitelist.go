@@ -0,0 +1,289 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	ca "github.com/furee/backend/constants/authorization"
+	da "github.com/furee/backend/domain/authorization"
+	"github.com/furee/backend/domain/general"
+	du "github.com/furee/backend/domain/user"
+	"github.com/furee/backend/infra"
+	"github.com/furee/backend/repo"
+	ra "github.com/furee/backend/repo/authorization"
+	ru "github.com/furee/backend/repo/user"
+	"github.com/furee/backend/utils/phone"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v4"
+)
+
+// defaultWhitelistCacheDuration is used when the cache duration is not configured.
+const defaultWhitelistCacheDuration = time.Minute
+
+var otpPattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// whitelistCache keep the active whitelist entry on memory, keyed by the phone filter.
+// The cache is reloaded after it's expired or invalidated by the admin change on this instance.
+type whitelistCache struct {
+	repo     ru.OTPWhitelistRepoItf
+	duration time.Duration
+
+	mu       sync.RWMutex
+	entries  map[string]du.OTPWhitelist
+	loadedAt time.Time
+}
+
+func newWhitelistCache(r ru.OTPWhitelistRepoItf, conf general.WhitelistAccount) *whitelistCache {
+	duration := time.Duration(conf.CacheDuration) * time.Second
+	if duration <= 0 {
+		duration = defaultWhitelistCacheDuration
+	}
+
+	return &whitelistCache{
+		repo:     r,
+		duration: duration,
+	}
+}
+
+func (wc *whitelistCache) get(phoneFilter string, now time.Time) (*du.OTPWhitelist, error) {
+	wc.mu.RLock()
+	entries, loadedAt := wc.entries, wc.loadedAt
+	wc.mu.RUnlock()
+
+	if entries == nil || now.Sub(loadedAt) >= wc.duration {
+		list, err := wc.repo.GetListActive(now)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = make(map[string]du.OTPWhitelist)
+		for _, entry := range list {
+			entries[entry.PhoneFilter] = entry
+		}
+
+		wc.mu.Lock()
+		wc.entries, wc.loadedAt = entries, now
+		wc.mu.Unlock()
+	}
+
+	entry, ok := entries[phoneFilter]
+	if !ok || !now.Before(entry.ExpiredAt) {
+		return nil, nil
+	}
+
+	return &entry, nil
+}
+
+func (wc *whitelistCache) invalidate() {
+	wc.mu.Lock()
+	wc.entries = nil
+	wc.mu.Unlock()
+}
+
+type OTPWhitelistUsecaseItf interface {
+	GetListWhitelist(ctx context.Context) ([]du.OTPWhitelistResponse, string, error)
+	CreateWhitelist(ctx context.Context, data du.OTPWhitelistRequest) (*du.OTPWhitelistResponse, string, error)
+	DeleteWhitelist(ctx context.Context, whitelistID int64) (string, error)
+}
+
+type OTPWhitelistUsecase struct {
+	Repo         ru.OTPWhitelistRepoItf
+	AuditLogRepo ra.AuditLogRepoItf
+	cache        *whitelistCache
+	phoneCipher  phoneCipher
+	DBList       *infra.DatabaseList
+	Conf         *general.SectionService
+	Log          *logrus.Logger
+}
+
+func newOTPWhitelistUsecase(r repo.Repo, conf *general.SectionService, logger *logrus.Logger, dbList *infra.DatabaseList, cache *whitelistCache) OTPWhitelistUsecase {
+	return OTPWhitelistUsecase{
+		Repo:         r.User.Whitelist,
+		AuditLogRepo: r.Authorization.AuditLog,
+		cache:        cache,
+		phoneCipher:  newPhoneCipher(conf.Authorization.Phone),
+		Conf:         conf,
+		Log:          logger,
+		DBList:       dbList,
+	}
+}
+
+func (wu OTPWhitelistUsecase) GetListWhitelist(ctx context.Context) ([]du.OTPWhitelistResponse, string, error) {
+	list, err := wu.Repo.GetList()
+	if err != nil {
+		wu.Log.WithError(err).Error("GetListWhitelist | fail to get whitelist from repo")
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	res := make([]du.OTPWhitelistResponse, 0)
+	for _, entry := range list {
+		detail, err := wu.toWhitelistResponse(entry, now)
+		if err != nil {
+			wu.Log.WithField("whitelist id", entry.ID).WithError(err).Error("GetListWhitelist | fail to decrypt phone")
+			return nil, "", err
+		}
+
+		res = append(res, *detail)
+	}
+
+	return res, "", nil
+}
+
+// CreateWhitelist add the phone to the whitelist, the phone can login with the given OTP until it's expired.
+func (wu OTPWhitelistUsecase) CreateWhitelist(ctx context.Context, data du.OTPWhitelistRequest) (*du.OTPWhitelistResponse, string, error) {
+	number, err := phone.Parse(data.Phone, phone.RegionID)
+	if err != nil {
+		return nil, "Nomor telepon tidak valid", err
+	}
+
+	if !otpPattern.MatchString(data.OTP) {
+		return nil, "OTP harus 6 digit angka", errors.New("otp not valid")
+	}
+
+	now := time.Now().UTC()
+	if !data.ExpiredAt.After(now) {
+		return nil, "expired_at harus lebih dari waktu sekarang", errors.New("expired_at not valid")
+	}
+
+	entry := du.OTPWhitelist{
+		OTP:       data.OTP,
+		Reason:    data.Reason,
+		ExpiredAt: data.ExpiredAt.UTC(),
+		CreatedBy: da.CurrentUserID(ctx),
+		CreatedAt: now,
+	}
+
+	entry.PhoneFilter, err = wu.phoneCipher.filter(number.E164)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entry.Phone, err = wu.phoneCipher.encrypt(number.E164)
+	if err != nil {
+		wu.Log.WithError(err).Error("CreateWhitelist | fail to encrypt phone")
+		return nil, "", err
+	}
+
+	tx, err := wu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	entry.ID, err = wu.Repo.InsertWhitelist(tx, entry)
+	if err != nil {
+		tx.Rollback()
+		wu.Log.WithError(err).Error("CreateWhitelist | fail to insert whitelist")
+		return nil, "", err
+	}
+
+	err = wu.insertAuditLog(tx, ctx, ca.AuditActionOTPWhitelistCreate, entry)
+	if err != nil {
+		tx.Rollback()
+		wu.Log.WithField("whitelist id", entry.ID).WithError(err).Error("CreateWhitelist | fail to insert audit log")
+		return nil, "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", err
+	}
+
+	wu.cache.invalidate()
+
+	res, err := wu.toWhitelistResponse(entry, now)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return res, "success add whitelist", nil
+}
+
+func (wu OTPWhitelistUsecase) DeleteWhitelist(ctx context.Context, whitelistID int64) (string, error) {
+	entry, err := wu.Repo.GetByID(whitelistID)
+	if err != nil {
+		wu.Log.WithField("whitelist id", whitelistID).WithError(err).Error("DeleteWhitelist | fail to get whitelist from repo")
+		return "", err
+	}
+
+	if entry == nil {
+		return "whitelist not found", errors.New("whitelist not found")
+	}
+
+	tx, err := wu.DBList.Backend.Write.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	err = wu.Repo.DeleteWhitelist(tx, whitelistID)
+	if err != nil {
+		tx.Rollback()
+		wu.Log.WithField("whitelist id", whitelistID).WithError(err).Error("DeleteWhitelist | fail to delete whitelist")
+		return "", err
+	}
+
+	err = wu.insertAuditLog(tx, ctx, ca.AuditActionOTPWhitelistDelete, *entry)
+	if err != nil {
+		tx.Rollback()
+		wu.Log.WithField("whitelist id", whitelistID).WithError(err).Error("DeleteWhitelist | fail to insert audit log")
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	wu.cache.invalidate()
+
+	return "success delete whitelist", nil
+}
+
+// insertAuditLog record the admin change, the phone & OTP is not included on the detail.
+func (wu OTPWhitelistUsecase) insertAuditLog(tx *sql.Tx, ctx context.Context, action string, entry du.OTPWhitelist) error {
+	detail, err := json.Marshal(map[string]interface{}{
+		"reason":     entry.Reason,
+		"expired_at": entry.ExpiredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return wu.AuditLogRepo.InsertAuditLog(tx, da.AuditLog{
+		ActorID:    null.NewInt(da.CurrentUserID(ctx), da.CurrentUserID(ctx) != 0),
+		Action:     action,
+		EntityType: ca.AuditEntityOTPWhitelist,
+		EntityID:   fmt.Sprintf("%v", entry.ID),
+		Detail:     string(detail),
+	})
+}
+
+func (wu OTPWhitelistUsecase) toWhitelistResponse(entry du.OTPWhitelist, now time.Time) (*du.OTPWhitelistResponse, error) {
+	decryptedPhone, err := wu.phoneCipher.decrypt(entry.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &du.OTPWhitelistResponse{
+		ID:        entry.ID,
+		Phone:     decryptedPhone,
+		OTP:       entry.OTP,
+		Reason:    entry.Reason,
+		ExpiredAt: entry.ExpiredAt,
+		IsExpired: !now.Before(entry.ExpiredAt),
+		CreatedBy: entry.CreatedBy,
+		CreatedAt: entry.CreatedAt,
+	}, nil
+}
+
+// isOTPBypassAllowed is the hard guard of the whitelist, the OTP bypass is only allowed on the known
+// non production environment. Any other environment, including the empty one, need it to be explicitly enabled.
+func isOTPBypassAllowed(conf *general.SectionService) bool {
+	return conf.App.IsNonProd() || conf.Whitelist.AllowOnProduction
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -251,3 +252,37 @@ func StrToArrMapString(data, separator string) (map[string]string, error) {
 
 	return result, nil
 }
+
+// StrToNetworks parse the list of IP or CIDR, a single IP is converted to the network of the IP itself.
+func StrToNetworks(values []string) ([]*net.IPNet, error) {
+	var res []*net.IPNet
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %s", value)
+			}
+
+			if ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, network)
+	}
+
+	return res, nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
+	constants "github.com/furee/backend/constants/general"
 	dg "github.com/furee/backend/domain/general"
 )
 
@@ -16,53 +17,168 @@ const (
 var jwtCfg JWT
 
 type JWT struct {
-	atSecretKey []byte        //Access Token Secret Key
-	atd         time.Duration //Access Token Duration
-	rtSecretKey []byte        //Refresh Token Secret Key
-	rtd         time.Duration //Refresh Token Duration
+	signingMethod string            //Access Token Signing Method, HMAC or asymmetric key
+	atSecretKey   []byte            //Access Token Secret Key, used on HMAC
+	keys          map[string]jwtKey //Access Token asymmetric keys by kid
+	activeKey     jwtKey            //Access Token asymmetric key used to sign
+	atd           time.Duration     //Access Token Duration
+	rtSecretKey   []byte            //Refresh Token Secret Key
+	rtd           time.Duration     //Refresh Token Duration
 }
 
 type Claims struct {
 	jwt.StandardClaims
-	Session string `json:"session"`
-	Renew   string `json:"renew,omitempty"`
+	Session     string   `json:"session"`
+	Family      string   `json:"fam"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"perms,omitempty"`
+	Renew       string   `json:"renew,omitempty"`
+	MFAPending  bool     `json:"mfa_pending,omitempty"`
 }
 
-func InitJWTConfig(cfg dg.JWTCredential) {
-	jwtCfg = JWT{
-		atSecretKey: []byte(cfg.AccessTokenSecretKey),
-		atd:         time.Duration(cfg.AccessTokenDuration) * time.Minute,
-		rtSecretKey: []byte(cfg.RefreshTokenSecretKey),
-		rtd:         time.Duration(cfg.RefreshTokenDuration) * 24 * time.Hour,
+// TokenAccess is the role & permission of the user that included on the access token.
+type TokenAccess struct {
+	Roles       []string
+	Permissions []string
+	MFAPending  bool
+}
+
+// JWTToken is the generated token pair, ID is the jti claim of each token.
+type JWTToken struct {
+	AccessToken         string
+	AccessTokenID       string
+	AccessTokenExpired  time.Time
+	RefreshToken        string
+	RefreshTokenID      string
+	RefreshTokenExpired time.Time
+	FamilyID            string
+}
+
+//InitJWTConfig load the signing key, must be called once before any token is generated
+//Refresh token is always signed with HMAC because it's only verified by this service
+func InitJWTConfig(cfg dg.JWTCredential) error {
+	signingMethod := cfg.SigningMethod
+	if signingMethod == "" {
+		signingMethod = constants.JWTSigningMethodHMAC
+	}
+
+	config := JWT{
+		signingMethod: signingMethod,
+		atSecretKey:   []byte(cfg.AccessTokenSecretKey),
+		keys:          make(map[string]jwtKey),
+		atd:           time.Duration(cfg.AccessTokenDuration) * time.Minute,
+		rtSecretKey:   []byte(cfg.RefreshTokenSecretKey),
+		rtd:           time.Duration(cfg.RefreshTokenDuration) * 24 * time.Hour,
+	}
+
+	if signingMethod != constants.JWTSigningMethodHMAC {
+		for _, keyCfg := range cfg.Keys {
+			key, err := loadJWTKey(keyCfg, signingMethod)
+			if err != nil {
+				return err
+			}
+
+			config.keys[key.id] = key
+		}
+
+		activeKey, ok := config.keys[cfg.ActiveKeyID]
+		if !ok || activeKey.privateKey == nil {
+			return fmt.Errorf("active key %s doesn't have private key", cfg.ActiveKeyID)
+		}
+
+		config.activeKey = activeKey
 	}
+
+	jwtCfg = config
+
+	return nil
 }
 
 //GenerateJWT will generate Access Token & Refresh Token
-//Use this when login authentication is success
-func GenerateJWT(session string) (string, string, error) {
+//Use this when login authentication is success, or when the refresh token is rotated.
+//Every refresh token that rotated from the same login share the same familyID.
+func GenerateJWT(session, familyID string, access TokenAccess) (JWTToken, error) {
+	var token JWTToken
+	var err error
+
+	if familyID == "" {
+		familyID, err = GetUUID()
+		if err != nil {
+			return token, err
+		}
+	}
+
+	token.FamilyID = familyID
+	generateTime := time.Now().UTC()
+
 	//Create Access Token
-	accessToken, err := generateAccessToken(session)
+	token.AccessTokenID, err = GetUUID()
+	if err != nil {
+		return token, err
+	}
+
+	token.AccessTokenExpired = generateTime.Add(jwtCfg.atd)
+	token.AccessToken, err = generateAccessToken(session, familyID, token.AccessTokenID, token.AccessTokenExpired, access)
 	if err != nil {
-		return "", "", err
+		return token, err
 	}
 
 	//Create Refresh Token
-	refreshToken, err := generateRefreshToken(session)
+	token.RefreshTokenID, err = GetUUID()
+	if err != nil {
+		return token, err
+	}
+
+	token.RefreshTokenExpired = generateTime.Add(jwtCfg.rtd)
+	token.RefreshToken, err = generateRefreshToken(session, familyID, token.RefreshTokenID, token.RefreshTokenExpired)
+	if err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+//GenerateMFAPendingToken will generate Access Token with mfa_pending claim without Refresh Token
+//Use this when the primary login is success but the user still need to verify the second factor.
+func GenerateMFAPendingToken(session string, duration time.Duration) (JWTToken, error) {
+	var token JWTToken
+	var err error
+
+	token.AccessTokenID, err = GetUUID()
+	if err != nil {
+		return token, err
+	}
+
+	token.AccessTokenExpired = time.Now().UTC().Add(duration)
+	token.AccessToken, err = generateAccessToken(session, "", token.AccessTokenID, token.AccessTokenExpired, TokenAccess{MFAPending: true})
 	if err != nil {
-		return "", "", err
+		return token, err
 	}
 
-	return accessToken, refreshToken, nil
+	return token, nil
 }
 
-func generateAccessToken(session string) (string, error) {
+func generateAccessToken(session, familyID, tokenID string, expiredAt time.Time, access TokenAccess) (string, error) {
 	accessClaims := Claims{
 		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
 			Issuer:    issuer,
-			ExpiresAt: time.Now().UTC().Add(jwtCfg.atd).Unix(),
+			ExpiresAt: expiredAt.Unix(),
 		},
-		Session: session,
+		Session:     session,
+		Family:      familyID,
+		Roles:       access.Roles,
+		Permissions: access.Permissions,
+		MFAPending:  access.MFAPending,
 	}
+
+	if jwtCfg.signingMethod != constants.JWTSigningMethodHMAC {
+		accessToken := jwt.NewWithClaims(jwtCfg.activeKey.method, accessClaims)
+		accessToken.Header["kid"] = jwtCfg.activeKey.id
+
+		return accessToken.SignedString(jwtCfg.activeKey.privateKey)
+	}
+
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
 	accessSignedToken, err := accessToken.SignedString(jwtCfg.atSecretKey)
 	if err != nil {
@@ -72,13 +188,15 @@ func generateAccessToken(session string) (string, error) {
 	return accessSignedToken, nil
 }
 
-func generateRefreshToken(session string) (string, error) {
+func generateRefreshToken(session, familyID, tokenID string, expiredAt time.Time) (string, error) {
 	refreshClaims := Claims{
 		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
 			Issuer:    issuer,
-			ExpiresAt: time.Now().UTC().Add(jwtCfg.rtd).Unix(),
+			ExpiresAt: expiredAt.Unix(),
 		},
 		Session: session,
+		Family:  familyID,
 		Renew:   renewClaims,
 	}
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS384, refreshClaims)
@@ -93,13 +211,7 @@ func generateRefreshToken(session string) (string, error) {
 //CheckAccessToken will check validity of access_token
 //This action will be used in middleware
 func CheckAccessToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Signing method invalid")
-		}
-
-		return jwtCfg.atSecretKey, nil
-	})
+	token, err := jwt.Parse(tokenString, accessTokenKey)
 	if err != nil {
 		return nil, err
 	}
@@ -109,6 +221,10 @@ func CheckAccessToken(tokenString string) (jwt.MapClaims, error) {
 		return nil, fmt.Errorf("Invalid Token")
 	}
 
+	if _, ok := claims["renew"]; ok {
+		return nil, fmt.Errorf("Invalid JWT Payload")
+	}
+
 	isr := fmt.Sprintf("%v", claims["iss"])
 	if isr != issuer {
 		return nil, fmt.Errorf("Invalid Issuer")
@@ -117,9 +233,9 @@ func CheckAccessToken(tokenString string) (jwt.MapClaims, error) {
 	return claims, nil
 }
 
-//RenewAccessToken will generate access_token as long as refresh_token still valid
-func RenewAccessToken(tokenString string) (string, error) {
-	//Validating Refresh Token
+//CheckRefreshToken will check validity of refresh_token
+//The token still need to be checked against the stored refresh token before it's rotated
+func CheckRefreshToken(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("Signing method invalid")
@@ -128,27 +244,63 @@ func RenewAccessToken(tokenString string) (string, error) {
 		return jwtCfg.rtSecretKey, nil
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok || !token.Valid {
-		return "", fmt.Errorf("Invalid Token")
+		return nil, fmt.Errorf("Invalid Token")
 	}
 
 	checker := fmt.Sprintf("%v", claims["renew"])
 	if checker != renewClaims {
-		return "", fmt.Errorf("Invalid JWT Payload")
+		return nil, fmt.Errorf("Invalid JWT Payload")
 	}
 
-	//Generate new access_token
-	session := fmt.Sprintf("%v", claims["session"])
-	accessToken, err := generateAccessToken(session)
-	if err != nil {
-		return "", err
+	return claims, nil
+}
+
+//GetClaimString return the string value of the claim, empty when not exist
+func GetClaimString(claims jwt.MapClaims, key string) string {
+	value, ok := claims[key].(string)
+	if !ok {
+		return ""
+	}
+
+	return value
+}
+
+//GetClaimStrings return the string list value of the claim
+func GetClaimStrings(claims jwt.MapClaims, key string) []string {
+	values, ok := claims[key].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			result = append(result, str)
+		}
+	}
+
+	return result
+}
+
+//GetClaimBool return the bool value of the claim, false when the claim is not exist
+func GetClaimBool(claims jwt.MapClaims, key string) bool {
+	value, ok := claims[key].(bool)
+	return ok && value
+}
+
+//GetClaimExpired return the expired time of the token
+func GetClaimExpired(claims jwt.MapClaims) time.Time {
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return time.Now().UTC()
 	}
 
-	return accessToken, nil
+	return time.Unix(int64(exp), 0).UTC()
 }
 
 func GetUserIDFromToken(session, secretKey string) (int64, error) {
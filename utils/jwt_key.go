@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	constants "github.com/furee/backend/constants/general"
+	dg "github.com/furee/backend/domain/general"
+)
+
+// SigningMethodEdDSA is Ed25519 signing method, jwt-go v3 doesn't provide it.
+var SigningMethodEdDSA = &signingMethodEd25519{}
+
+type signingMethodEd25519 struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEd25519) Alg() string {
+	return constants.JWTSigningMethodEdDSA
+}
+
+func (m *signingMethodEd25519) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+func (m *signingMethodEd25519) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
+
+// jwtKey is the asymmetric key that identified by kid.
+// Key without private key is only used to verify token that signed before the key rotated.
+type jwtKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.PrivateKey
+	publicKey  crypto.PublicKey
+}
+
+func loadJWTKey(cfg dg.JWTKey, signingMethod string) (jwtKey, error) {
+	key := jwtKey{
+		id: cfg.ID,
+	}
+
+	switch signingMethod {
+	case constants.JWTSigningMethodRS256:
+		key.method = jwt.SigningMethodRS256
+	case constants.JWTSigningMethodEdDSA:
+		key.method = SigningMethodEdDSA
+	default:
+		return key, fmt.Errorf("signing method %s not supported", signingMethod)
+	}
+
+	if cfg.PrivateKeyPath != "" {
+		block, err := readPEM(cfg.PrivateKeyPath)
+		if err != nil {
+			return key, err
+		}
+
+		switch signingMethod {
+		case constants.JWTSigningMethodRS256:
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pem.EncodeToMemory(block))
+			if err != nil {
+				return key, err
+			}
+
+			key.privateKey = privateKey
+			key.publicKey = &privateKey.PublicKey
+		case constants.JWTSigningMethodEdDSA:
+			parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return key, err
+			}
+
+			privateKey, ok := parsed.(ed25519.PrivateKey)
+			if !ok {
+				return key, fmt.Errorf("key %s is not ed25519 private key", cfg.ID)
+			}
+
+			key.privateKey = privateKey
+			key.publicKey = privateKey.Public()
+		}
+	}
+
+	if cfg.PublicKeyPath != "" {
+		block, err := readPEM(cfg.PublicKeyPath)
+		if err != nil {
+			return key, err
+		}
+
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return key, err
+		}
+
+		key.publicKey = parsed
+	}
+
+	if key.publicKey == nil {
+		return key, fmt.Errorf("key %s doesn't have public key", cfg.ID)
+	}
+
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("file %s is not PEM encoded", path)
+	}
+
+	return block, nil
+}
+
+// GetJWKS return the public key of every configured key, used by other service to verify our token.
+func GetJWKS() dg.JWKS {
+	jwks := dg.JWKS{
+		Keys: make([]dg.JWK, 0, len(jwtCfg.keys)),
+	}
+
+	for _, key := range jwtCfg.keys {
+		jwk := dg.JWK{
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+		}
+
+		switch publicKey := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// accessTokenKey return the key used to verify the access token based on signing method & kid.
+func accessTokenKey(token *jwt.Token) (interface{}, error) {
+	if jwtCfg.signingMethod == constants.JWTSigningMethodHMAC {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Signing method invalid")
+		}
+
+		return jwtCfg.atSecretKey, nil
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("kid not found")
+	}
+
+	key, ok := jwtCfg.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("kid %s unknown", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("Signing method invalid")
+	}
+
+	return key.publicKey, nil
+}
+
+// ParseRSAJWK convert the RSA key of the JWKS, used to verify the token of other issuer.
+func ParseRSAJWK(jwk dg.JWK) (*rsa.PublicKey, error) {
+	if jwk.KeyType != "RSA" {
+		return nil, fmt.Errorf("key %s is not RSA key", jwk.KeyID)
+	}
+
+	modulus, err := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent, err := base64.RawURLEncoding.DecodeString(jwk.Exponent)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modulus) == 0 || len(exponent) == 0 {
+		return nil, fmt.Errorf("key %s is not valid", jwk.KeyID)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	constants "github.com/furee/backend/constants/general"
+)
+
+// setTestJWTConfig set the asymmetric config with the active RS256 key & the rotated key that only has public key.
+func setTestJWTConfig(t *testing.T) (*rsa.PrivateKey, *rsa.PrivateKey) {
+	t.Helper()
+
+	active, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	activeKey := jwtKey{id: "active", method: jwt.SigningMethodRS256, privateKey: active, publicKey: &active.PublicKey}
+
+	previous := jwtCfg
+	t.Cleanup(func() { jwtCfg = previous })
+
+	jwtCfg = JWT{
+		signingMethod: constants.JWTSigningMethodRS256,
+		atSecretKey:   []byte("hmac-secret"),
+		keys: map[string]jwtKey{
+			"active":  activeKey,
+			"rotated": {id: "rotated", method: jwt.SigningMethodRS256, publicKey: &rotated.PublicKey},
+		},
+		activeKey: activeKey,
+		atd:       time.Minute,
+	}
+
+	return active, rotated
+}
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, jwt.MapClaims{
+		"iss": issuer,
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestCheckAccessTokenKey(t *testing.T) {
+	active, rotated := setTestJWTConfig(t)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "active key", token: signTestToken(t, jwt.SigningMethodRS256, "active", active)},
+		{name: "rotated key", token: signTestToken(t, jwt.SigningMethodRS256, "rotated", rotated)},
+		{name: "wrong kid", token: signTestToken(t, jwt.SigningMethodRS256, "rotated", active), wantErr: true},
+		{name: "unknown kid", token: signTestToken(t, jwt.SigningMethodRS256, "unknown", active), wantErr: true},
+		{name: "missing kid", token: signTestToken(t, jwt.SigningMethodRS256, "", active), wantErr: true},
+		{name: "alg mismatch", token: signTestToken(t, SigningMethodEdDSA, "active", edKey), wantErr: true},
+		{name: "hmac with the configured secret", token: signTestToken(t, jwt.SigningMethodHS256, "active", []byte("hmac-secret")), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CheckAccessToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckAccessToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckAccessTokenKeyHMAC(t *testing.T) {
+	active, _ := setTestJWTConfig(t)
+	jwtCfg.signingMethod = constants.JWTSigningMethodHMAC
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "hmac", token: signTestToken(t, jwt.SigningMethodHS256, "", []byte("hmac-secret"))},
+		{name: "hmac wrong secret", token: signTestToken(t, jwt.SigningMethodHS256, "", []byte("other-secret")), wantErr: true},
+		{name: "rsa", token: signTestToken(t, jwt.SigningMethodRS256, "active", active), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CheckAccessToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckAccessToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateAccessTokenActiveKey(t *testing.T) {
+	setTestJWTConfig(t)
+
+	token, err := GenerateMFAPendingToken("session", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token.AccessToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if kid := parsed.Header["kid"]; kid != "active" {
+		t.Errorf("kid = %v, want active", kid)
+	}
+
+	if alg := parsed.Header["alg"]; alg != constants.JWTSigningMethodRS256 {
+		t.Errorf("alg = %v, want %s", alg, constants.JWTSigningMethodRS256)
+	}
+
+	if _, err := CheckAccessToken(token.AccessToken); err != nil {
+		t.Errorf("CheckAccessToken of the generated token error = %v", err)
+	}
+}
+
+func TestGetJWKS(t *testing.T) {
+	active, _ := setTestJWTConfig(t)
+
+	jwks := GetJWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(jwks.Keys))
+	}
+
+	for _, jwk := range jwks.Keys {
+		if jwk.KeyID != "active" {
+			continue
+		}
+
+		publicKey, err := ParseRSAJWK(jwk)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if publicKey.N.Cmp(active.N) != 0 || publicKey.E != active.E {
+			t.Errorf("JWKS key of active doesn't match the public key")
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package phone parse phone number into canonical E.164 format,
+// so the same number written in different format is stored as one user.
+package phone
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Type of the phone number.
+const (
+	TypeMobile   string = "mobile"
+	TypeLandline string = "landline"
+	TypeUnknown  string = "unknown"
+)
+
+// Region supported as default region of national format number.
+const (
+	RegionID string = "ID"
+)
+
+const (
+	countryCodeID = "62"
+)
+
+var (
+	ErrEmpty         = errors.New("phone number empty")
+	ErrInvalidFormat = errors.New("phone number format invalid")
+	ErrInvalidLength = errors.New("phone number length invalid")
+	ErrUnknownRegion = errors.New("phone number region unknown")
+)
+
+type Number struct {
+	E164           string `json:"e164"`            // +6281234567890
+	CountryCode    string `json:"country_code"`    // 62, empty for number outside supported region
+	NationalNumber string `json:"national_number"` // 81234567890, without trunk prefix 0
+	Type           string `json:"type"`
+}
+
+func (n Number) IsMobile() bool {
+	return n.Type == TypeMobile
+}
+
+// Parse normalize the raw phone number to E.164. Number without country code is
+// parsed using the default region, e.g. 0812..., 812..., 62812... & +62812... is the same number.
+// International number outside the supported region is only checked by E.164 length.
+func Parse(raw, defaultRegion string) (Number, error) {
+	var res Number
+
+	cleaned := strings.TrimSpace(raw)
+	if cleaned == "" {
+		return res, ErrEmpty
+	}
+
+	isInternational := false
+	switch {
+	case strings.HasPrefix(cleaned, "+"):
+		isInternational = true
+		cleaned = cleaned[1:]
+	case strings.HasPrefix(cleaned, "00"):
+		isInternational = true
+		cleaned = cleaned[2:]
+	}
+
+	digits := make([]byte, 0, len(cleaned))
+	for i := 0; i < len(cleaned); i++ {
+		c := cleaned[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+			continue
+		default:
+			return res, ErrInvalidFormat
+		}
+	}
+
+	number := string(digits)
+	if number == "" {
+		return res, ErrEmpty
+	}
+
+	if isInternational {
+		if strings.HasPrefix(number, countryCodeID) {
+			return parseID(number[len(countryCodeID):])
+		}
+
+		// E.164 allow max 15 digits including country code.
+		if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+			return res, ErrInvalidLength
+		}
+
+		return Number{
+			E164:           "+" + number,
+			NationalNumber: number,
+			Type:           TypeUnknown,
+		}, nil
+	}
+
+	switch defaultRegion {
+	case RegionID:
+		switch {
+		case strings.HasPrefix(number, "0"):
+			return parseID(number[1:])
+		case strings.HasPrefix(number, countryCodeID):
+			return parseID(number[len(countryCodeID):])
+		default:
+			return parseID(number)
+		}
+	default:
+		return res, ErrUnknownRegion
+	}
+}
+
+// IsValid check the phone number can be parsed with the default region.
+func IsValid(raw, defaultRegion string) bool {
+	_, err := Parse(raw, defaultRegion)
+	return err == nil
+}
+
+// Mask hide the phone number except the last 4 digits, used when the number is written to the log.
+func Mask(number string) string {
+	const visible = 4
+	if len(number) <= visible {
+		return strings.Repeat("*", len(number))
+	}
+
+	return strings.Repeat("*", len(number)-visible) + number[len(number)-visible:]
+}
+
+// parseID validate the Indonesian national significant number.
+// Mobile number start with 8 (08xx), landline start with the area code (021, 022, 0274, ...).
+func parseID(nsn string) (Number, error) {
+	var res Number
+
+	if nsn == "" || nsn[0] == '0' || nsn[0] == '1' {
+		return res, ErrInvalidFormat
+	}
+
+	res = Number{
+		E164:           "+" + countryCodeID + nsn,
+		CountryCode:    countryCodeID,
+		NationalNumber: nsn,
+	}
+
+	if nsn[0] == '8' {
+		if len(nsn) < 9 || len(nsn) > 12 {
+			return Number{}, ErrInvalidLength
+		}
+
+		res.Type = TypeMobile
+		return res, nil
+	}
+
+	if len(nsn) < 7 || len(nsn) > 11 {
+		return Number{}, ErrInvalidLength
+	}
+
+	res.Type = TypeLandline
+	return res, nil
+}
+
+// BlindIndex return the keyed hash of the E.164 phone, used to search the encrypted phone.
+// The pepper must be kept secret, otherwise the index can be reversed by brute force.
+func BlindIndex(pepper []byte, e164 string) string {
+	mac := hmac.New(sha256.New, pepper)
+	mac.Write([]byte(e164))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// totpVectors is the SHA1 test vector of RFC 6238 appendix B, the code is truncated to the last 6 digits.
+var totpVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestGenerateTOTP(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	for _, v := range totpVectors {
+		if code := generateTOTP(key, v.unix/totpPeriod); code != v.code {
+			t.Errorf("generateTOTP at %d = %s, want %s", v.unix, code, v.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	for _, v := range totpVectors {
+		at := time.Unix(v.unix, 0)
+
+		step, ok := ValidateTOTP(secret, v.code, at, 0)
+		if !ok || step != v.unix/totpPeriod {
+			t.Errorf("ValidateTOTP at %d = (%d, %v), want (%d, true)", v.unix, step, ok, v.unix/totpPeriod)
+		}
+
+		if _, ok := ValidateTOTP(secret, v.code, at.Add(2*totpPeriod*time.Second), 1); ok {
+			t.Errorf("ValidateTOTP at %d accept the code outside of the skew", v.unix)
+		}
+	}
+}
@@ -4,14 +4,36 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
-	"regexp"
+	"unicode"
 
 	domain "github.com/furee/backend/domain/general"
+	"github.com/furee/backend/utils/phone"
 )
 
-func PhoneNumberValidator(phone string) bool {
-	val := regexp.MustCompile(`[^0-9]*1[34578][0-9]{9}[^0-9]*`)
-	return val.MatchString(phone)
+// PhoneNumberValidator check the phone is valid Indonesian or international E.164 number.
+func PhoneNumberValidator(number string) bool {
+	return phone.IsValid(number, phone.RegionID)
+}
+
+// PasswordStrengthValidator check the password length & require lower case, upper case and digit.
+func PasswordStrengthValidator(password string, minLength int) bool {
+	if len(password) < minLength {
+		return false
+	}
+
+	var hasLower, hasUpper, hasDigit bool
+	for _, c := range password {
+		switch {
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		}
+	}
+
+	return hasLower && hasUpper && hasDigit
 }
 
 func ImageValidator(image multipart.File, header *multipart.FileHeader, imageSize int64) (bool, string) {